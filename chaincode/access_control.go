@@ -0,0 +1,218 @@
+package chaincode
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Roles recognised by the access control checks
+const (
+	RoleAdmin       = "admin"
+	RoleCollector   = "collector"
+	RoleWeighbridge = "weighbridge"
+	RoleVerifier    = "verifier"
+	RoleAuditor     = "auditor"
+	RolePublic      = "public"
+	RoleAny         = "*"
+)
+
+// RoleAttribute is the X.509 certificate attribute holding the comma separated roles of a client
+const RoleAttribute = "role"
+
+// ActionDeleteViolations is the permission required to run a comparison with deleteViolations set
+const ActionDeleteViolations = "DeleteViolations"
+
+// AuthorizationError is returned when the caller lacks the role required by a transaction
+type AuthorizationError struct {
+	Transaction string
+	Roles       []string
+	Required    []string
+}
+
+func (e *AuthorizationError) Error() string {
+	return fmt.Sprintf("authorization denied: %s requires one of the roles [%s], caller has [%s]",
+		e.Transaction, strings.Join(e.Required, ", "), strings.Join(e.Roles, ", "))
+}
+
+// defaultPermissions maps every transaction to the roles allowed to call it
+func defaultPermissions() map[string][]string {
+	readers := []string{RoleCollector, RoleWeighbridge, RoleVerifier, RoleAuditor, RoleAdmin}
+	verifiers := []string{RoleVerifier, RoleAuditor, RoleAdmin}
+	owners := []string{RoleCollector, RoleWeighbridge, RoleAdmin}
+	viewers := append([]string{RolePublic}, readers...)
+
+	return map[string][]string{
+		"CreateProofRecord":                         {RoleCollector, RoleAdmin},
+		"CreateProofRecordPrivate":                  {RoleCollector, RoleAdmin},
+		"QueryProofRecord":                          viewers,
+		"QueryAllProofRecords":                      viewers,
+		"QueryRecordsByField":                       viewers,
+		"GetRecordHistory":                          viewers,
+		"CreateTicket":                              {RoleWeighbridge, RoleAdmin},
+		"CreateTicketPrivate":                       {RoleWeighbridge, RoleAdmin},
+		"QueryTicket":                               viewers,
+		"QueryAllTickets":                           viewers,
+		"QueryTicketsByField":                       viewers,
+		"CompareWeightsByPressIncrement":            verifiers,
+		"CompareWeightsByPressIncrementWithOptions": verifiers,
+		"CompareWeightsByStoreIncrement":            verifiers,
+		"CompareWeightsByStoreIncrementWithOptions": verifiers,
+		"ReconcileByPressIncrement":                 verifiers,
+		"ReconcileByStoreIncrement":                 verifiers,
+		ActionDeleteViolations:                      {RoleAdmin},
+		"SetComparisonConfig":                       {RoleAdmin},
+		"QueryComparisonConfig":                     readers,
+		"SetAccessControlConfig":                    {RoleAdmin},
+		"QueryAccessControlConfig":                  readers,
+		"SetIdentityConfig":                         {RoleAdmin},
+		"QueryIdentityConfig":                       readers,
+		"QueryDocumentsByCreator":                   readers,
+		"GrantRole":                                 {RoleAdmin},
+		"RevokeRole":                                {RoleAdmin},
+		"RevokeSponsor":                             {RoleAdmin},
+		"ListRoleAssignments":                       {RoleAdmin},
+		"TransferOwnership":                         owners,
+		"TransferBulkOwnership":                     owners,
+		"DelegateOwnership":                         owners,
+		"RevokeDelegation":                          owners,
+		"SetSponsorEndorsementPolicy":               {RoleAny},
+		"QuerySponsorEndorsementPolicy":             {RoleAny},
+		"QueryRecordEndorsementPolicy":              readers,
+		"ProposeViolationAction":                    verifiers,
+		"ApproveProposal":                           verifiers,
+		"QueryProposal":                             verifiers,
+		"QueryProposalsByStatus":                    verifiers,
+		"SetGovernanceConfig":                       {RoleAdmin},
+		"QueryGovernanceConfig":                     readers,
+		"SetPrivacyConfig":                          {RoleAdmin},
+		"QueryPrivacyConfig":                        readers,
+		"VerifyProofRecordCommitment":               viewers,
+		"ErasePersonalData":                         {RoleAdmin},
+		"EraseCollectorPersonalData":                {RoleAdmin},
+		"SetRedactionConfig":                        {RoleAdmin},
+		"QueryRedactionConfig":                      readers,
+		"SetRegistryConfig":                         {RoleAdmin},
+		"QueryRegistryConfig":                       readers,
+		"CreateSponsor":                             {RoleAdmin},
+		"UpdateSponsor":                             {RoleAdmin},
+		"QuerySponsor":                              readers,
+		"QueryAllSponsors":                          readers,
+		"DeleteSponsor":                             {RoleAdmin},
+		"CreateCollector":                           {RoleAdmin},
+		"UpdateCollector":                           {RoleAdmin},
+		"QueryCollector":                            readers,
+		"QueryAllCollectors":                        readers,
+		"DeleteCollector":                           {RoleAdmin},
+		"CreateBulk":                                {RoleCollector, RoleAdmin},
+		"UpdateBulk":                                {RoleCollector, RoleAdmin},
+		"QueryBulk":                                 readers,
+		"QueryAllBulks":                             readers,
+		"DeleteBulk":                                {RoleAdmin},
+		"IssueCredits":                              verifiers,
+		"QueryCredit":                               readers,
+		"QueryCreditByRecord":                       readers,
+		"Transfer":                                  {RoleAny},
+		"Retire":                                    {RoleAny},
+		"BalanceOf":                                 {RoleAny},
+		"QueryRetirementCertificate":                {RoleAny},
+		"ApplySponsorEndorsementPolicy":             {RoleAny},
+	}
+}
+
+// getCallerRoles returns the roles of the caller, combining its certificate
+// attributes with its assignments in the on-ledger role registry
+func getCallerRoles(ctx contractapi.TransactionContextInterface) ([]string, error) {
+	value, found, err := ctx.GetClientIdentity().GetAttributeValue(RoleAttribute)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client identity attributes: %v", err)
+	}
+
+	roles := []string{}
+	if found {
+		for _, role := range strings.Split(value, ",") {
+			role = strings.TrimSpace(role)
+			if role != "" {
+				roles = addUnique(roles, role)
+			}
+		}
+	}
+
+	assignments, err := getCallerAssignments(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, assignment := range assignments {
+		for _, role := range assignment.Roles {
+			roles = addUnique(roles, role)
+		}
+	}
+
+	sort.Strings(roles)
+	return roles, nil
+}
+
+// authorize checks that the caller holds one of the roles allowed to call the transaction.
+// Transactions missing from the permission matrix are restricted to admins.
+func authorize(ctx contractapi.TransactionContextInterface, transaction string) error {
+	config, err := loadAccessControlConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	required, exists := config.Permissions[transaction]
+	if !exists {
+		required = []string{RoleAdmin}
+	}
+
+	roles, err := getCallerRoles(ctx)
+	if err != nil {
+		return err
+	}
+
+	if hasAnyRole(roles, required) {
+		return nil
+	}
+	return &AuthorizationError{
+		Transaction: transaction,
+		Roles:       roles,
+		Required:    required,
+	}
+}
+
+// authorizeComparison checks the comparison permission and, when records are to be deleted, the delete permission
+func authorizeComparison(ctx contractapi.TransactionContextInterface, transaction string, deleteViolations string) error {
+	err := authorize(ctx, transaction)
+	if err != nil {
+		return err
+	}
+	if deleteViolations == "true" {
+		return authorize(ctx, ActionDeleteViolations)
+	}
+	return nil
+}
+
+// callerHasRole reports whether the caller holds the given role
+func callerHasRole(ctx contractapi.TransactionContextInterface, role string) (bool, error) {
+	roles, err := getCallerRoles(ctx)
+	if err != nil {
+		return false, err
+	}
+	return hasAnyRole(roles, []string{role}), nil
+}
+
+func hasAnyRole(roles []string, required []string) bool {
+	for _, requiredRole := range required {
+		if requiredRole == RoleAny {
+			return true
+		}
+		for _, role := range roles {
+			if role == requiredRole {
+				return true
+			}
+		}
+	}
+	return false
+}
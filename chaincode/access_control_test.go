@@ -0,0 +1,118 @@
+package chaincode
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// testIdentity is a client identity of the given organization holding certificate attributes
+type testIdentity struct {
+	mspID      string
+	id         string
+	attributes map[string]string
+	cert       *x509.Certificate
+}
+
+func (ti *testIdentity) GetID() (string, error) {
+	return ti.id, nil
+}
+
+func (ti *testIdentity) GetMSPID() (string, error) {
+	return ti.mspID, nil
+}
+
+func (ti *testIdentity) GetAttributeValue(name string) (string, bool, error) {
+	value, found := ti.attributes[name]
+	return value, found, nil
+}
+
+func (ti *testIdentity) AssertAttributeValue(name string, value string) error {
+	if ti.attributes[name] != value {
+		return fmt.Errorf("attribute %s is not %s", name, value)
+	}
+	return nil
+}
+
+func (ti *testIdentity) GetX509Certificate() (*x509.Certificate, error) {
+	return ti.cert, nil
+}
+
+// setCaller makes an Org1MSP client holding the given comma separated roles the caller of ctx
+func setCaller(ctx *contractapi.TransactionContext, roles string) *testIdentity {
+	identity := &testIdentity{mspID: "Org1MSP", id: "x509::CN=user::CN=ca", attributes: map[string]string{}}
+	if roles != "" {
+		identity.attributes[RoleAttribute] = roles
+	}
+	ctx.SetClientIdentity(identity)
+	return identity
+}
+
+func TestAuthorize(t *testing.T) {
+	tests := []struct {
+		roles       string
+		transaction string
+		allowed     bool
+	}{
+		{"collector", "CreateProofRecord", true},
+		{"weighbridge", "CreateProofRecord", false},
+		{"verifier, auditor", "ReconcileByPressIncrement", true},
+		{"collector", "SetComparisonConfig", false},
+		{"admin", "SetComparisonConfig", true},
+		{"", "QueryProofRecord", false},
+		{"verifier", "UnlistedTransaction", false},
+		{"admin", "UnlistedTransaction", true},
+	}
+
+	for _, test := range tests {
+		ctx := newTestContext(t)
+		setCaller(ctx, test.roles)
+		err := authorize(ctx, test.transaction)
+		if (err == nil) != test.allowed {
+			t.Errorf("roles %q calling %s: error = %v, want allowed %v", test.roles, test.transaction, err, test.allowed)
+		}
+		var authErr *AuthorizationError
+		if err != nil && !errors.As(err, &authErr) {
+			t.Errorf("roles %q calling %s: error %v is not an AuthorizationError", test.roles, test.transaction, err)
+		}
+	}
+}
+
+func TestAuthorizeComparisonDeletion(t *testing.T) {
+	ctx := newTestContext(t)
+	setCaller(ctx, "verifier")
+	if err := authorizeComparison(ctx, "CompareWeightsByPressIncrement", "false"); err != nil {
+		t.Errorf("verifier dry run denied: %v", err)
+	}
+	if err := authorizeComparison(ctx, "CompareWeightsByPressIncrement", "true"); err == nil {
+		t.Errorf("verifier was allowed to delete violations")
+	}
+}
+
+func TestSetAccessControlConfigOverridesDefaults(t *testing.T) {
+	ctx := newTestContext(t)
+	cm := NewConfigManager()
+
+	if _, err := cm.SetAccessControlConfig(ctx, `{"permissions":{"CreateTicket":[]}}`); err == nil {
+		t.Errorf("transaction without roles was accepted")
+	}
+	if _, err := cm.SetAccessControlConfig(ctx, `{"permissions":{"CreateTicket":["verifier"]}}`); err != nil {
+		t.Fatalf("SetAccessControlConfig failed: %v", err)
+	}
+
+	setCaller(ctx, "verifier")
+	if err := authorize(ctx, "CreateTicket"); err != nil {
+		t.Errorf("configured role denied: %v", err)
+	}
+	setCaller(ctx, "weighbridge")
+	if err := authorize(ctx, "CreateTicket"); err == nil {
+		t.Errorf("role removed by the config was allowed")
+	}
+	setCaller(ctx, "collector")
+	if err := authorize(ctx, "CreateProofRecord"); err != nil {
+		t.Errorf("transaction left out of the config lost its default roles: %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+package chaincode
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// TransientSaltKey is the transient map entry holding the secret salt of a proof record commitment
+const TransientSaltKey = "salt"
+
+// SaltSourceTransient marks a commitment salted with the secret salt of the transient map
+const SaltSourceTransient = "transient"
+
+// Commitment represents salted SHA-256 commitments over the canonical JSON of a submitted proof record.
+// Value covers the whole record, PublicValue the record without its personal fields, so the weights and
+// increments stay verifiable once the personal data is erased and Value is cleared.
+type Commitment struct {
+	Algorithm      string   `json:"algorithm"`
+	Value          string   `json:"value,omitempty"`
+	PublicValue    string   `json:"publicValue,omitempty"`
+	PersonalFields []string `json:"personalFields,omitempty"`
+	SaltSource     string   `json:"saltSource"`
+	TxID           string   `json:"txId"`
+	Timestamp      string   `json:"timestamp"`
+}
+
+// CommitmentVerification represents the result of a VerifyProofRecordCommitment call. Matches reports
+// whether the whole claimed record matches, PublicMatches whether its public fields do.
+type CommitmentVerification struct {
+	RecordID         string `json:"recordId"`
+	Matches          bool   `json:"matches"`
+	PublicMatches    bool   `json:"publicMatches"`
+	Commitment       string `json:"commitment,omitempty"`
+	PublicCommitment string `json:"publicCommitment,omitempty"`
+	TxID             string `json:"txId"`
+	Timestamp        string `json:"timestamp"`
+}
+
+// newCommitment commits to a submitted proof record with the secret salt of the transient map, once
+// whole and once without the given personal fields. Records created without a salt get no commitment,
+// as before commitments were salted: without a salt, anyone could confirm a guessed record against
+// the public commitment.
+func newCommitment(ctx contractapi.TransactionContextInterface, document map[string]interface{}, personalFields []string) (*Commitment, error) {
+	salt, err := readTransientSalt(ctx)
+	if err != nil || salt == "" {
+		return nil, err
+	}
+
+	value, err := commitmentValue(document, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	presentFields := []string{}
+	for _, field := range personalFields {
+		if _, exists := document[field]; exists {
+			presentFields = append(presentFields, field)
+		}
+	}
+	sort.Strings(presentFields)
+	publicValue, err := commitmentValue(withoutFields(document, presentFields), salt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Commitment{
+		Algorithm:      "sha256",
+		Value:          value,
+		PublicValue:    publicValue,
+		PersonalFields: presentFields,
+		SaltSource:     SaltSourceTransient,
+		TxID:           ctx.GetStub().GetTxID(),
+		Timestamp:      getTxTimestamp(ctx),
+	}, nil
+}
+
+// withoutFields returns a copy of a document without the given fields
+func withoutFields(document map[string]interface{}, fields []string) map[string]interface{} {
+	copied := make(map[string]interface{}, len(document))
+	for field, value := range document {
+		copied[field] = value
+	}
+	for _, field := range fields {
+		delete(copied, field)
+	}
+	return copied
+}
+
+// commitmentValue returns the hex SHA-256 of the salt followed by the canonical JSON of the document.
+// Canonical JSON has object keys sorted and no insignificant whitespace.
+func commitmentValue(document interface{}, salt string) (string, error) {
+	canonicalJSON, err := json.Marshal(document)
+	if err != nil {
+		return "", fmt.Errorf("failed to canonicalize document: %v", err)
+	}
+
+	hash := sha256.Sum256(append([]byte(salt), canonicalJSON...))
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// VerifyProofRecordCommitment checks a claimed document and salt against the commitment of a proof record
+func (qu *QueryUtils) VerifyProofRecordCommitment(ctx contractapi.TransactionContextInterface, recordId string, claimedDocument string, salt string) (string, error) {
+	if salt == "" {
+		return "", fmt.Errorf("the commitment salt is required")
+	}
+
+	record, err := getDocument(ctx, recordId)
+	if err != nil || record["docType"] != "proofRecord" {
+		return "", fmt.Errorf("Proof record %s does not exist", recordId)
+	}
+
+	access, err := getTenantAccess(ctx)
+	if err != nil {
+		return "", err
+	}
+	if !access.CanSeeDocument(record) {
+		return "", fmt.Errorf("Proof record %s does not exist", recordId)
+	}
+
+	commitmentJSON, _ := json.Marshal(record["commitment"])
+	var commitment Commitment
+	err = json.Unmarshal(commitmentJSON, &commitment)
+	if err != nil || (commitment.Value == "" && commitment.PublicValue == "") {
+		return "", fmt.Errorf("Proof record %s has no commitment", recordId)
+	}
+
+	var document map[string]interface{}
+	err = json.Unmarshal([]byte(claimedDocument), &document)
+	if err != nil {
+		return "", fmt.Errorf("invalid claimed document: %v", err)
+	}
+
+	verification := CommitmentVerification{
+		RecordID:         recordId,
+		Commitment:       commitment.Value,
+		PublicCommitment: commitment.PublicValue,
+		TxID:             commitment.TxID,
+		Timestamp:        commitment.Timestamp,
+	}
+	// The commitment over the whole record is cleared when its personal data is erased
+	if commitment.Value != "" {
+		value, err := commitmentValue(document, salt)
+		if err != nil {
+			return "", err
+		}
+		verification.Matches = value == commitment.Value
+	}
+	if commitment.PublicValue != "" {
+		publicValue, err := commitmentValue(withoutFields(document, commitment.PersonalFields), salt)
+		if err != nil {
+			return "", err
+		}
+		verification.PublicMatches = publicValue == commitment.PublicValue
+	}
+	verificationJSON, err := json.Marshal(verification)
+	if err != nil {
+		return "", err
+	}
+	return string(verificationJSON), nil
+}
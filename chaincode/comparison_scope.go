@@ -0,0 +1,95 @@
+package chaincode
+
+import (
+	"fmt"
+	"time"
+)
+
+// ComparisonScope restricts a comparison to a subset of proof records and tickets
+type ComparisonScope struct {
+	SponsorID     string    `json:"sponsor_id,omitempty"`
+	CollectorName string    `json:"collector_name,omitempty"`
+	CreatedFrom   string    `json:"createdFrom,omitempty"`
+	CreatedTo     string    `json:"createdTo,omitempty"`
+	IncrementIDs  []float64 `json:"incrementIds,omitempty"`
+}
+
+// validate checks that the createdAt window of the scope is well formed and normalizes its bounds
+// to UTC, the zone createdAt is stored in, so the string comparisons of the selector hold
+func (scope *ComparisonScope) validate() error {
+	var from, to time.Time
+	var err error
+
+	if scope.CreatedFrom != "" {
+		from, err = time.Parse(time.RFC3339, scope.CreatedFrom)
+		if err != nil {
+			return fmt.Errorf("invalid scope createdFrom: %v", err)
+		}
+		scope.CreatedFrom = from.UTC().Format(time.RFC3339)
+	}
+	if scope.CreatedTo != "" {
+		to, err = time.Parse(time.RFC3339, scope.CreatedTo)
+		if err != nil {
+			return fmt.Errorf("invalid scope createdTo: %v", err)
+		}
+		scope.CreatedTo = to.UTC().Format(time.RFC3339)
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return fmt.Errorf("invalid scope: createdTo is before createdFrom")
+	}
+	return nil
+}
+
+// restrictsRecords reports whether the scope filters proof records on fields tickets do not carry
+func (scope *ComparisonScope) restrictsRecords() bool {
+	return scope != nil && (scope.SponsorID != "" || scope.CollectorName != "")
+}
+
+// proofRecordSelector builds the CouchDB selector for the proof records in scope. The collector
+// is a private field, its records are resolved from the private data collection by the caller.
+func (scope *ComparisonScope) proofRecordSelector(incrementField string) map[string]interface{} {
+	selector := map[string]interface{}{
+		"docType": "proofRecord",
+	}
+	if scope == nil {
+		return selector
+	}
+
+	if scope.SponsorID != "" {
+		selector["sponsor_id"] = scope.SponsorID
+	}
+	scope.addCommonConditions(selector, incrementField)
+	return selector
+}
+
+// ticketSelector builds the CouchDB selector for the tickets in scope
+func (scope *ComparisonScope) ticketSelector() map[string]interface{} {
+	selector := map[string]interface{}{
+		"docType": "ticket",
+	}
+	if scope == nil {
+		return selector
+	}
+
+	scope.addCommonConditions(selector, "incrementId")
+	return selector
+}
+
+func (scope *ComparisonScope) addCommonConditions(selector map[string]interface{}, incrementField string) {
+	createdAt := map[string]interface{}{}
+	if scope.CreatedFrom != "" {
+		createdAt["$gte"] = scope.CreatedFrom
+	}
+	if scope.CreatedTo != "" {
+		createdAt["$lte"] = scope.CreatedTo
+	}
+	if len(createdAt) > 0 {
+		selector["createdAt"] = createdAt
+	}
+
+	if len(scope.IncrementIDs) > 0 {
+		selector[incrementField] = map[string]interface{}{
+			"$in": scope.IncrementIDs,
+		}
+	}
+}
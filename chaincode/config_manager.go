@@ -0,0 +1,624 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ComparisonConfigKey is the world state key of the comparison configuration
+const ComparisonConfigKey = "CONFIG_COMPARISON"
+
+// ComparisonConfig represents the ledger-stored defaults for weight comparisons
+type ComparisonConfig struct {
+	Aggregation string  `json:"aggregation"`
+	Rejection   string  `json:"rejection"`
+	Tolerance   float64 `json:"tolerance"`
+	DocType     string  `json:"docType"`
+}
+
+// ConfigManager handles configuration documents stored in world state
+type ConfigManager struct{}
+
+// NewConfigManager creates a new ConfigManager instance
+func NewConfigManager() *ConfigManager {
+	return &ConfigManager{}
+}
+
+// SetComparisonConfig stores the default comparison configuration
+func (cm *ConfigManager) SetComparisonConfig(ctx contractapi.TransactionContextInterface, configData string) (string, error) {
+	var config ComparisonConfig
+	err := json.Unmarshal([]byte(configData), &config)
+	if err != nil {
+		return "", fmt.Errorf("invalid comparison config: %v", err)
+	}
+
+	if config.Aggregation == "" {
+		config.Aggregation = AggregationMax
+	}
+	if config.Rejection == "" {
+		config.Rejection = RejectionAll
+	}
+	if !isValidAggregation(config.Aggregation) {
+		return "", fmt.Errorf("invalid comparison config: unknown aggregation %s", config.Aggregation)
+	}
+	if !isValidRejection(config.Rejection) {
+		return "", fmt.Errorf("invalid comparison config: unknown rejection strategy %s", config.Rejection)
+	}
+	if config.Tolerance < 0 {
+		return "", fmt.Errorf("invalid comparison config: tolerance must not be negative")
+	}
+	config.DocType = "comparisonConfig"
+
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+
+	err = ctx.GetStub().PutState(ComparisonConfigKey, configJSON)
+	if err != nil {
+		return "", fmt.Errorf("failed to put comparison config to world state: %v", err)
+	}
+
+	return string(configJSON), nil
+}
+
+// QueryComparisonConfig returns the effective comparison configuration
+func (cm *ConfigManager) QueryComparisonConfig(ctx contractapi.TransactionContextInterface) (string, error) {
+	config, err := loadComparisonConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+
+	return string(configJSON), nil
+}
+
+// loadComparisonConfig reads the comparison configuration, falling back to the defaults
+func loadComparisonConfig(ctx contractapi.TransactionContextInterface) (*ComparisonConfig, error) {
+	config := &ComparisonConfig{
+		Aggregation: AggregationMax,
+		Rejection:   RejectionAll,
+		DocType:     "comparisonConfig",
+	}
+
+	configAsBytes, err := ctx.GetStub().GetState(ComparisonConfigKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if len(configAsBytes) == 0 {
+		return config, nil
+	}
+
+	err = json.Unmarshal(configAsBytes, config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid comparison config in world state: %v", err)
+	}
+
+	return config, nil
+}
+
+// AccessControlConfigKey is the world state key of the role-to-transaction matrix
+const AccessControlConfigKey = "CONFIG_ACCESS_CONTROL"
+
+// AccessControlConfig represents the ledger-stored role-to-transaction matrix
+type AccessControlConfig struct {
+	Permissions map[string][]string `json:"permissions"`
+	DocType     string              `json:"docType"`
+}
+
+// SetAccessControlConfig stores the role-to-transaction matrix. Transactions missing
+// from the given permissions keep their default roles.
+func (cm *ConfigManager) SetAccessControlConfig(ctx contractapi.TransactionContextInterface, configData string) (string, error) {
+	var config AccessControlConfig
+	err := json.Unmarshal([]byte(configData), &config)
+	if err != nil {
+		return "", fmt.Errorf("invalid access control config: %v", err)
+	}
+
+	permissions := defaultPermissions()
+	for transaction, roles := range config.Permissions {
+		if len(roles) == 0 {
+			return "", fmt.Errorf("invalid access control config: %s has no roles", transaction)
+		}
+		permissions[transaction] = roles
+	}
+	config.Permissions = permissions
+	config.DocType = "accessControlConfig"
+
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+
+	err = ctx.GetStub().PutState(AccessControlConfigKey, configJSON)
+	if err != nil {
+		return "", fmt.Errorf("failed to put access control config to world state: %v", err)
+	}
+
+	return string(configJSON), nil
+}
+
+// QueryAccessControlConfig returns the effective role-to-transaction matrix
+func (cm *ConfigManager) QueryAccessControlConfig(ctx contractapi.TransactionContextInterface) (string, error) {
+	config, err := loadAccessControlConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+
+	return string(configJSON), nil
+}
+
+// loadAccessControlConfig reads the role-to-transaction matrix, falling back to the defaults
+func loadAccessControlConfig(ctx contractapi.TransactionContextInterface) (*AccessControlConfig, error) {
+	config := &AccessControlConfig{
+		Permissions: defaultPermissions(),
+		DocType:     "accessControlConfig",
+	}
+
+	configAsBytes, err := ctx.GetStub().GetState(AccessControlConfigKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if len(configAsBytes) == 0 {
+		return config, nil
+	}
+
+	var stored AccessControlConfig
+	err = json.Unmarshal(configAsBytes, &stored)
+	if err != nil {
+		return nil, fmt.Errorf("invalid access control config in world state: %v", err)
+	}
+	for transaction, roles := range stored.Permissions {
+		config.Permissions[transaction] = roles
+	}
+
+	return config, nil
+}
+
+// IdentityConfigKey is the world state key of the identity capture configuration
+const IdentityConfigKey = "CONFIG_IDENTITY"
+
+// IdentityConfig represents the certificate attributes captured in the creator metadata of documents
+type IdentityConfig struct {
+	Attributes []string `json:"attributes"`
+	DocType    string   `json:"docType"`
+}
+
+// SetIdentityConfig stores the certificate attributes captured from creators
+func (cm *ConfigManager) SetIdentityConfig(ctx contractapi.TransactionContextInterface, configData string) (string, error) {
+	var config IdentityConfig
+	err := json.Unmarshal([]byte(configData), &config)
+	if err != nil {
+		return "", fmt.Errorf("invalid identity config: %v", err)
+	}
+
+	if config.Attributes == nil {
+		config.Attributes = []string{}
+	}
+	config.DocType = "identityConfig"
+
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+
+	err = ctx.GetStub().PutState(IdentityConfigKey, configJSON)
+	if err != nil {
+		return "", fmt.Errorf("failed to put identity config to world state: %v", err)
+	}
+
+	return string(configJSON), nil
+}
+
+// QueryIdentityConfig returns the effective identity capture configuration
+func (cm *ConfigManager) QueryIdentityConfig(ctx contractapi.TransactionContextInterface) (string, error) {
+	config, err := loadIdentityConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+
+	return string(configJSON), nil
+}
+
+// loadIdentityConfig reads the identity capture configuration, falling back to the defaults
+func loadIdentityConfig(ctx contractapi.TransactionContextInterface) (*IdentityConfig, error) {
+	config := &IdentityConfig{
+		Attributes: []string{"userId", "site", "device"},
+		DocType:    "identityConfig",
+	}
+
+	configAsBytes, err := ctx.GetStub().GetState(IdentityConfigKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if len(configAsBytes) == 0 {
+		return config, nil
+	}
+
+	err = json.Unmarshal(configAsBytes, config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid identity config in world state: %v", err)
+	}
+
+	return config, nil
+}
+
+// GovernanceConfigKey is the world state key of the governance configuration
+const GovernanceConfigKey = "CONFIG_GOVERNANCE"
+
+// GovernanceConfig represents the number of organizations that must approve destructive reconciliation
+type GovernanceConfig struct {
+	Quorum  int    `json:"quorum"`
+	DocType string `json:"docType"`
+}
+
+// SetGovernanceConfig stores the approval quorum of destructive reconciliation
+func (cm *ConfigManager) SetGovernanceConfig(ctx contractapi.TransactionContextInterface, configData string) (string, error) {
+	var config GovernanceConfig
+	err := json.Unmarshal([]byte(configData), &config)
+	if err != nil {
+		return "", fmt.Errorf("invalid governance config: %v", err)
+	}
+
+	if config.Quorum < 1 {
+		return "", fmt.Errorf("invalid governance config: quorum must be at least 1")
+	}
+	config.DocType = "governanceConfig"
+
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+
+	err = ctx.GetStub().PutState(GovernanceConfigKey, configJSON)
+	if err != nil {
+		return "", fmt.Errorf("failed to put governance config to world state: %v", err)
+	}
+
+	return string(configJSON), nil
+}
+
+// QueryGovernanceConfig returns the effective governance configuration
+func (cm *ConfigManager) QueryGovernanceConfig(ctx contractapi.TransactionContextInterface) (string, error) {
+	config, err := loadGovernanceConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+
+	return string(configJSON), nil
+}
+
+// loadGovernanceConfig reads the governance configuration, falling back to the defaults
+func loadGovernanceConfig(ctx contractapi.TransactionContextInterface) (*GovernanceConfig, error) {
+	config := &GovernanceConfig{
+		Quorum:  2,
+		DocType: "governanceConfig",
+	}
+
+	configAsBytes, err := ctx.GetStub().GetState(GovernanceConfigKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if len(configAsBytes) == 0 {
+		return config, nil
+	}
+
+	err = json.Unmarshal(configAsBytes, config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid governance config in world state: %v", err)
+	}
+
+	return config, nil
+}
+
+// PrivacyConfigKey is the world state key of the privacy configuration
+const PrivacyConfigKey = "CONFIG_PRIVACY"
+
+// PrivacyConfig represents the proof record and ticket fields kept in the private data collection and
+// the organizations, besides the owner organization and its delegates, that may read them
+type PrivacyConfig struct {
+	PrivateFields       []string `json:"privateFields"`
+	TicketPrivateFields []string `json:"ticketPrivateFields"`
+	AuthorizedMSPs      []string `json:"authorizedMSPs"`
+	Collection          string   `json:"collection"`
+	DocType             string   `json:"docType"`
+}
+
+// SetPrivacyConfig stores the private fields of proof records and tickets and the organizations allowed to read them
+func (cm *ConfigManager) SetPrivacyConfig(ctx contractapi.TransactionContextInterface, configData string) (string, error) {
+	var config PrivacyConfig
+	err := json.Unmarshal([]byte(configData), &config)
+	if err != nil {
+		return "", fmt.Errorf("invalid privacy config: %v", err)
+	}
+
+	if config.PrivateFields == nil {
+		config.PrivateFields = []string{}
+	}
+	if config.TicketPrivateFields == nil {
+		config.TicketPrivateFields = []string{}
+	}
+	if config.AuthorizedMSPs == nil {
+		config.AuthorizedMSPs = []string{}
+	}
+	if config.Collection == "" {
+		config.Collection = PrivateCollectionName
+	}
+	for _, field := range append(append([]string{}, config.PrivateFields...), config.TicketPrivateFields...) {
+		if isProtectedField(field) {
+			return "", fmt.Errorf("invalid privacy config: %s must stay public", field)
+		}
+	}
+	config.DocType = "privacyConfig"
+
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+
+	err = ctx.GetStub().PutState(PrivacyConfigKey, configJSON)
+	if err != nil {
+		return "", fmt.Errorf("failed to put privacy config to world state: %v", err)
+	}
+
+	return string(configJSON), nil
+}
+
+// QueryPrivacyConfig returns the effective privacy configuration
+func (cm *ConfigManager) QueryPrivacyConfig(ctx contractapi.TransactionContextInterface) (string, error) {
+	config, err := loadPrivacyConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+
+	return string(configJSON), nil
+}
+
+// loadPrivacyConfig reads the privacy configuration, falling back to the defaults
+func loadPrivacyConfig(ctx contractapi.TransactionContextInterface) (*PrivacyConfig, error) {
+	config := &PrivacyConfig{
+		PrivateFields:       []string{"collector_name"},
+		TicketPrivateFields: []string{},
+		AuthorizedMSPs:      []string{},
+		Collection:          PrivateCollectionName,
+		DocType:             "privacyConfig",
+	}
+
+	configAsBytes, err := ctx.GetStub().GetState(PrivacyConfigKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if len(configAsBytes) == 0 {
+		return config, nil
+	}
+
+	err = json.Unmarshal(configAsBytes, config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid privacy config in world state: %v", err)
+	}
+
+	return config, nil
+}
+
+// RedactionConfigKey is the world state key of the redaction configuration
+const RedactionConfigKey = "CONFIG_REDACTION"
+
+// RedactionConfig represents the ledger-stored redaction profiles of query results, by role
+type RedactionConfig struct {
+	Profiles map[string]RedactionProfile `json:"profiles"`
+	DocType  string                      `json:"docType"`
+}
+
+// SetRedactionConfig stores the redaction profiles. Roles missing from the given profiles keep their default profile.
+func (cm *ConfigManager) SetRedactionConfig(ctx contractapi.TransactionContextInterface, configData string) (string, error) {
+	var config RedactionConfig
+	err := json.Unmarshal([]byte(configData), &config)
+	if err != nil {
+		return "", fmt.Errorf("invalid redaction config: %v", err)
+	}
+
+	profiles := defaultRedactionProfiles()
+	for role, profile := range config.Profiles {
+		if len(profile.Include) == 0 {
+			return "", fmt.Errorf("invalid redaction config: profile %s includes no fields", role)
+		}
+		profiles[role] = profile
+	}
+	config.Profiles = profiles
+	config.DocType = "redactionConfig"
+
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+
+	err = ctx.GetStub().PutState(RedactionConfigKey, configJSON)
+	if err != nil {
+		return "", fmt.Errorf("failed to put redaction config to world state: %v", err)
+	}
+
+	return string(configJSON), nil
+}
+
+// QueryRedactionConfig returns the effective redaction profiles
+func (cm *ConfigManager) QueryRedactionConfig(ctx contractapi.TransactionContextInterface) (string, error) {
+	config, err := loadRedactionConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+
+	return string(configJSON), nil
+}
+
+// loadRedactionConfig reads the redaction profiles, falling back to the defaults
+func loadRedactionConfig(ctx contractapi.TransactionContextInterface) (*RedactionConfig, error) {
+	config := &RedactionConfig{
+		Profiles: defaultRedactionProfiles(),
+		DocType:  "redactionConfig",
+	}
+
+	configAsBytes, err := ctx.GetStub().GetState(RedactionConfigKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if len(configAsBytes) == 0 {
+		return config, nil
+	}
+
+	var stored RedactionConfig
+	err = json.Unmarshal(configAsBytes, &stored)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redaction config in world state: %v", err)
+	}
+	for role, profile := range stored.Profiles {
+		config.Profiles[role] = profile
+	}
+
+	return config, nil
+}
+
+// RegistryConfigKey is the world state key of the external registry configuration
+const RegistryConfigKey = "CONFIG_REGISTRY"
+
+// Registry sources proof records are validated against
+const (
+	RegistrySourceChaincode = "chaincode"
+	RegistrySourceLedger    = "ledger"
+)
+
+// RegistryConfig represents the registry proof records are validated against: a registry
+// chaincode, or the sponsors, collectors and bulks registered on the ledger. An empty
+// channel is the channel of the calling transaction.
+type RegistryConfig struct {
+	Enabled           bool   `json:"enabled"`
+	Source            string `json:"source"`
+	ChaincodeName     string `json:"chaincodeName"`
+	Channel           string `json:"channel"`
+	Function          string `json:"function"`
+	ValidateSponsor   bool   `json:"validateSponsor"`
+	ValidateCollector bool   `json:"validateCollector"`
+	ValidateBulk      bool   `json:"validateBulk"`
+	DocType           string `json:"docType"`
+}
+
+// SetRegistryConfig stores the external registry configuration
+func (cm *ConfigManager) SetRegistryConfig(ctx contractapi.TransactionContextInterface, configData string) (string, error) {
+	// Fields left out of the config keep their defaults, so validation does not silently turn off
+	config := defaultRegistryConfig()
+	err := json.Unmarshal([]byte(configData), config)
+	if err != nil {
+		return "", fmt.Errorf("invalid registry config: %v", err)
+	}
+
+	if config.Function == "" {
+		config.Function = "GetEntity"
+	}
+	if config.Source != RegistrySourceChaincode && config.Source != RegistrySourceLedger {
+		return "", fmt.Errorf("invalid registry config: source must be %s or %s", RegistrySourceChaincode, RegistrySourceLedger)
+	}
+	if config.Enabled && config.Source == RegistrySourceChaincode && config.ChaincodeName == "" {
+		return "", fmt.Errorf("invalid registry config: chaincodeName is required")
+	}
+	if config.Enabled && !config.ValidateSponsor && !config.ValidateCollector && !config.ValidateBulk {
+		return "", fmt.Errorf("invalid registry config: an enabled registry must validate sponsors, collectors or bulks")
+	}
+	config.DocType = "registryConfig"
+
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+
+	err = ctx.GetStub().PutState(RegistryConfigKey, configJSON)
+	if err != nil {
+		return "", fmt.Errorf("failed to put registry config to world state: %v", err)
+	}
+
+	return string(configJSON), nil
+}
+
+// QueryRegistryConfig returns the effective external registry configuration
+func (cm *ConfigManager) QueryRegistryConfig(ctx contractapi.TransactionContextInterface) (string, error) {
+	config, err := loadRegistryConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+
+	return string(configJSON), nil
+}
+
+// defaultRegistryConfig returns the registry configuration used until an admin sets one:
+// proof records are checked against every registry on the ledger
+func defaultRegistryConfig() *RegistryConfig {
+	return &RegistryConfig{
+		Enabled:           true,
+		Source:            RegistrySourceLedger,
+		ChaincodeName:     "registry",
+		Function:          "GetEntity",
+		ValidateSponsor:   true,
+		ValidateCollector: true,
+		ValidateBulk:      true,
+		DocType:           "registryConfig",
+	}
+}
+
+// loadRegistryConfig reads the external registry configuration, falling back to the defaults
+// only when none is stored
+func loadRegistryConfig(ctx contractapi.TransactionContextInterface) (*RegistryConfig, error) {
+	configAsBytes, err := ctx.GetStub().GetState(RegistryConfigKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if len(configAsBytes) == 0 {
+		return defaultRegistryConfig(), nil
+	}
+
+	// Fields missing from a stored config keep their value from when it was stored, not the new defaults
+	config := &RegistryConfig{}
+	err = json.Unmarshal(configAsBytes, config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid registry config in world state: %v", err)
+	}
+	// Configs stored before the ledger registries existed validate against a registry chaincode
+	if config.Source == "" {
+		config.Source = RegistrySourceChaincode
+	}
+
+	return config, nil
+}
@@ -0,0 +1,674 @@
+package chaincode
+
+import (
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ProofRecordsContract provides functions for managing proof records and tickets
+type ProofRecordsContract struct {
+	contractapi.Contract
+}
+
+// InitLedger initializes the ledger
+func (c *ProofRecordsContract) InitLedger(ctx contractapi.TransactionContextInterface) error {
+	logger.Println("============= START : Initialize Ledger ===========")
+	logger.Println("============= END : Initialize Ledger ===========")
+	return nil
+}
+
+// CreateProofRecord creates a new proof record
+func (c *ProofRecordsContract) CreateProofRecord(ctx contractapi.TransactionContextInterface, recordData string) (string, error) {
+	if err := authorize(ctx, "CreateProofRecord"); err != nil {
+		return "", err
+	}
+	manager := NewProofRecordManager()
+	return manager.CreateProofRecord(ctx, recordData)
+}
+
+// CreateProofRecordPrivate creates a new proof record passed in the transient map under "record"
+func (c *ProofRecordsContract) CreateProofRecordPrivate(ctx contractapi.TransactionContextInterface) (string, error) {
+	if err := authorize(ctx, "CreateProofRecordPrivate"); err != nil {
+		return "", err
+	}
+	manager := NewProofRecordManager()
+	return manager.CreateProofRecordPrivate(ctx)
+}
+
+// QueryProofRecord queries a proof record by ID
+func (c *ProofRecordsContract) QueryProofRecord(ctx contractapi.TransactionContextInterface, recordId string) (string, error) {
+	if err := authorize(ctx, "QueryProofRecord"); err != nil {
+		return "", err
+	}
+	queryUtils := NewQueryUtils()
+	return queryUtils.QueryProofRecord(ctx, recordId)
+}
+
+// VerifyProofRecordCommitment checks a claimed document and salt against the commitment of a proof record
+func (c *ProofRecordsContract) VerifyProofRecordCommitment(ctx contractapi.TransactionContextInterface, recordId string, claimedDocument string, salt string) (string, error) {
+	if err := authorize(ctx, "VerifyProofRecordCommitment"); err != nil {
+		return "", err
+	}
+	queryUtils := NewQueryUtils()
+	return queryUtils.VerifyProofRecordCommitment(ctx, recordId, claimedDocument, salt)
+}
+
+// QueryAllProofRecords queries all proof records
+func (c *ProofRecordsContract) QueryAllProofRecords(ctx contractapi.TransactionContextInterface) (string, error) {
+	if err := authorize(ctx, "QueryAllProofRecords"); err != nil {
+		return "", err
+	}
+	queryUtils := NewQueryUtils()
+	return queryUtils.QueryAllProofRecords(ctx)
+}
+
+// QueryRecordsByField queries records by a specific field
+func (c *ProofRecordsContract) QueryRecordsByField(ctx contractapi.TransactionContextInterface, fieldName string, fieldValue string) (string, error) {
+	if err := authorize(ctx, "QueryRecordsByField"); err != nil {
+		return "", err
+	}
+	queryUtils := NewQueryUtils()
+	return queryUtils.QueryRecordsByField(ctx, fieldName, fieldValue)
+}
+
+// GetRecordHistory gets the history of a record
+func (c *ProofRecordsContract) GetRecordHistory(ctx contractapi.TransactionContextInterface, recordId string) (string, error) {
+	if err := authorize(ctx, "GetRecordHistory"); err != nil {
+		return "", err
+	}
+	queryUtils := NewQueryUtils()
+	return queryUtils.GetRecordHistory(ctx, recordId)
+}
+
+// QueryDocumentsByCreator queries proof records and tickets by an identity dimension of their creator
+func (c *ProofRecordsContract) QueryDocumentsByCreator(ctx contractapi.TransactionContextInterface, dimension string, value string) (string, error) {
+	if err := authorize(ctx, "QueryDocumentsByCreator"); err != nil {
+		return "", err
+	}
+	queryUtils := NewQueryUtils()
+	return queryUtils.QueryDocumentsByCreator(ctx, dimension, value)
+}
+
+// CreateTicket creates a new ticket
+func (c *ProofRecordsContract) CreateTicket(ctx contractapi.TransactionContextInterface, ticketData string) (string, error) {
+	if err := authorize(ctx, "CreateTicket"); err != nil {
+		return "", err
+	}
+	manager := NewTicketManager()
+	return manager.CreateTicket(ctx, ticketData)
+}
+
+// CreateTicketPrivate creates a new ticket passed in the transient map under "ticket"
+func (c *ProofRecordsContract) CreateTicketPrivate(ctx contractapi.TransactionContextInterface) (string, error) {
+	if err := authorize(ctx, "CreateTicketPrivate"); err != nil {
+		return "", err
+	}
+	manager := NewTicketManager()
+	return manager.CreateTicketPrivate(ctx)
+}
+
+// QueryTicket queries a ticket by key
+func (c *ProofRecordsContract) QueryTicket(ctx contractapi.TransactionContextInterface, ticketKey string) (string, error) {
+	if err := authorize(ctx, "QueryTicket"); err != nil {
+		return "", err
+	}
+	queryUtils := NewQueryUtils()
+	return queryUtils.QueryTicket(ctx, ticketKey)
+}
+
+// QueryAllTickets queries all tickets
+func (c *ProofRecordsContract) QueryAllTickets(ctx contractapi.TransactionContextInterface) (string, error) {
+	if err := authorize(ctx, "QueryAllTickets"); err != nil {
+		return "", err
+	}
+	queryUtils := NewQueryUtils()
+	return queryUtils.QueryAllTickets(ctx)
+}
+
+// QueryTicketsByField queries tickets by a specific field
+func (c *ProofRecordsContract) QueryTicketsByField(ctx contractapi.TransactionContextInterface, fieldName string, fieldValue string) (string, error) {
+	if err := authorize(ctx, "QueryTicketsByField"); err != nil {
+		return "", err
+	}
+	queryUtils := NewQueryUtils()
+	return queryUtils.QueryTicketsByField(ctx, fieldName, fieldValue)
+}
+
+// CompareWeightsByPressIncrement compares weights by press increment
+func (c *ProofRecordsContract) CompareWeightsByPressIncrement(ctx contractapi.TransactionContextInterface, deleteViolations string) (string, error) {
+	if err := authorizeComparison(ctx, "CompareWeightsByPressIncrement", deleteViolations); err != nil {
+		return "", err
+	}
+	weightComp := NewWeightComparison()
+	return weightComp.CompareWeightsByPressIncrement(ctx, deleteViolations, "")
+}
+
+// CompareWeightsByPressIncrementWithOptions compares weights by press increment using the given options
+func (c *ProofRecordsContract) CompareWeightsByPressIncrementWithOptions(ctx contractapi.TransactionContextInterface, deleteViolations string, options string) (string, error) {
+	if err := authorizeComparison(ctx, "CompareWeightsByPressIncrementWithOptions", deleteViolations); err != nil {
+		return "", err
+	}
+	weightComp := NewWeightComparison()
+	return weightComp.CompareWeightsByPressIncrement(ctx, deleteViolations, options)
+}
+
+// CompareWeightsByStoreIncrement compares weights by store increment
+func (c *ProofRecordsContract) CompareWeightsByStoreIncrement(ctx contractapi.TransactionContextInterface, deleteViolations string) (string, error) {
+	if err := authorizeComparison(ctx, "CompareWeightsByStoreIncrement", deleteViolations); err != nil {
+		return "", err
+	}
+	weightComp := NewWeightComparison()
+	return weightComp.CompareWeightsByStoreIncrement(ctx, deleteViolations, "")
+}
+
+// CompareWeightsByStoreIncrementWithOptions compares weights by store increment using the given options
+func (c *ProofRecordsContract) CompareWeightsByStoreIncrementWithOptions(ctx contractapi.TransactionContextInterface, deleteViolations string, options string) (string, error) {
+	if err := authorizeComparison(ctx, "CompareWeightsByStoreIncrementWithOptions", deleteViolations); err != nil {
+		return "", err
+	}
+	weightComp := NewWeightComparison()
+	return weightComp.CompareWeightsByStoreIncrement(ctx, deleteViolations, options)
+}
+
+// ReconcileByPressIncrement reports the reconciliation status of every press increment
+func (c *ProofRecordsContract) ReconcileByPressIncrement(ctx contractapi.TransactionContextInterface, options string) (string, error) {
+	if err := authorize(ctx, "ReconcileByPressIncrement"); err != nil {
+		return "", err
+	}
+	weightComp := NewWeightComparison()
+	return weightComp.ReconcileByPressIncrement(ctx, options)
+}
+
+// ReconcileByStoreIncrement reports the reconciliation status of every store increment
+func (c *ProofRecordsContract) ReconcileByStoreIncrement(ctx contractapi.TransactionContextInterface, options string) (string, error) {
+	if err := authorize(ctx, "ReconcileByStoreIncrement"); err != nil {
+		return "", err
+	}
+	weightComp := NewWeightComparison()
+	return weightComp.ReconcileByStoreIncrement(ctx, options)
+}
+
+// SetComparisonConfig stores the default comparison configuration
+func (c *ProofRecordsContract) SetComparisonConfig(ctx contractapi.TransactionContextInterface, configData string) (string, error) {
+	if err := authorize(ctx, "SetComparisonConfig"); err != nil {
+		return "", err
+	}
+	configManager := NewConfigManager()
+	return configManager.SetComparisonConfig(ctx, configData)
+}
+
+// QueryComparisonConfig queries the comparison configuration
+func (c *ProofRecordsContract) QueryComparisonConfig(ctx contractapi.TransactionContextInterface) (string, error) {
+	if err := authorize(ctx, "QueryComparisonConfig"); err != nil {
+		return "", err
+	}
+	configManager := NewConfigManager()
+	return configManager.QueryComparisonConfig(ctx)
+}
+
+// SetAccessControlConfig stores the role-to-transaction matrix
+func (c *ProofRecordsContract) SetAccessControlConfig(ctx contractapi.TransactionContextInterface, configData string) (string, error) {
+	if err := authorize(ctx, "SetAccessControlConfig"); err != nil {
+		return "", err
+	}
+	configManager := NewConfigManager()
+	return configManager.SetAccessControlConfig(ctx, configData)
+}
+
+// QueryAccessControlConfig queries the role-to-transaction matrix
+func (c *ProofRecordsContract) QueryAccessControlConfig(ctx contractapi.TransactionContextInterface) (string, error) {
+	if err := authorize(ctx, "QueryAccessControlConfig"); err != nil {
+		return "", err
+	}
+	configManager := NewConfigManager()
+	return configManager.QueryAccessControlConfig(ctx)
+}
+
+// SetIdentityConfig stores the certificate attributes captured from creators
+func (c *ProofRecordsContract) SetIdentityConfig(ctx contractapi.TransactionContextInterface, configData string) (string, error) {
+	if err := authorize(ctx, "SetIdentityConfig"); err != nil {
+		return "", err
+	}
+	configManager := NewConfigManager()
+	return configManager.SetIdentityConfig(ctx, configData)
+}
+
+// QueryIdentityConfig queries the identity capture configuration
+func (c *ProofRecordsContract) QueryIdentityConfig(ctx contractapi.TransactionContextInterface) (string, error) {
+	if err := authorize(ctx, "QueryIdentityConfig"); err != nil {
+		return "", err
+	}
+	configManager := NewConfigManager()
+	return configManager.QueryIdentityConfig(ctx)
+}
+
+// SetGovernanceConfig stores the approval quorum of destructive reconciliation
+func (c *ProofRecordsContract) SetGovernanceConfig(ctx contractapi.TransactionContextInterface, configData string) (string, error) {
+	if err := authorize(ctx, "SetGovernanceConfig"); err != nil {
+		return "", err
+	}
+	configManager := NewConfigManager()
+	return configManager.SetGovernanceConfig(ctx, configData)
+}
+
+// QueryGovernanceConfig queries the governance configuration
+func (c *ProofRecordsContract) QueryGovernanceConfig(ctx contractapi.TransactionContextInterface) (string, error) {
+	if err := authorize(ctx, "QueryGovernanceConfig"); err != nil {
+		return "", err
+	}
+	configManager := NewConfigManager()
+	return configManager.QueryGovernanceConfig(ctx)
+}
+
+// SetPrivacyConfig stores the private fields of proof records and the organizations allowed to read them
+func (c *ProofRecordsContract) SetPrivacyConfig(ctx contractapi.TransactionContextInterface, configData string) (string, error) {
+	if err := authorize(ctx, "SetPrivacyConfig"); err != nil {
+		return "", err
+	}
+	configManager := NewConfigManager()
+	return configManager.SetPrivacyConfig(ctx, configData)
+}
+
+// QueryPrivacyConfig queries the privacy configuration
+func (c *ProofRecordsContract) QueryPrivacyConfig(ctx contractapi.TransactionContextInterface) (string, error) {
+	if err := authorize(ctx, "QueryPrivacyConfig"); err != nil {
+		return "", err
+	}
+	configManager := NewConfigManager()
+	return configManager.QueryPrivacyConfig(ctx)
+}
+
+// SetRedactionConfig stores the redaction profiles applied to query results by role
+func (c *ProofRecordsContract) SetRedactionConfig(ctx contractapi.TransactionContextInterface, configData string) (string, error) {
+	if err := authorize(ctx, "SetRedactionConfig"); err != nil {
+		return "", err
+	}
+	configManager := NewConfigManager()
+	return configManager.SetRedactionConfig(ctx, configData)
+}
+
+// QueryRedactionConfig queries the redaction profiles
+func (c *ProofRecordsContract) QueryRedactionConfig(ctx contractapi.TransactionContextInterface) (string, error) {
+	if err := authorize(ctx, "QueryRedactionConfig"); err != nil {
+		return "", err
+	}
+	configManager := NewConfigManager()
+	return configManager.QueryRedactionConfig(ctx)
+}
+
+// SetRegistryConfig stores the external registry proof records are validated against
+func (c *ProofRecordsContract) SetRegistryConfig(ctx contractapi.TransactionContextInterface, configData string) (string, error) {
+	if err := authorize(ctx, "SetRegistryConfig"); err != nil {
+		return "", err
+	}
+	configManager := NewConfigManager()
+	return configManager.SetRegistryConfig(ctx, configData)
+}
+
+// QueryRegistryConfig queries the external registry configuration
+func (c *ProofRecordsContract) QueryRegistryConfig(ctx contractapi.TransactionContextInterface) (string, error) {
+	if err := authorize(ctx, "QueryRegistryConfig"); err != nil {
+		return "", err
+	}
+	configManager := NewConfigManager()
+	return configManager.QueryRegistryConfig(ctx)
+}
+
+// CreateSponsor registers a sponsor on the ledger
+func (c *ProofRecordsContract) CreateSponsor(ctx contractapi.TransactionContextInterface, sponsorData string) (string, error) {
+	if err := authorize(ctx, "CreateSponsor"); err != nil {
+		return "", err
+	}
+	manager := NewEntityManager()
+	return manager.CreateSponsor(ctx, sponsorData)
+}
+
+// UpdateSponsor updates a sponsor registered on the ledger
+func (c *ProofRecordsContract) UpdateSponsor(ctx contractapi.TransactionContextInterface, sponsorId string, sponsorData string) (string, error) {
+	if err := authorize(ctx, "UpdateSponsor"); err != nil {
+		return "", err
+	}
+	manager := NewEntityManager()
+	return manager.UpdateSponsor(ctx, sponsorId, sponsorData)
+}
+
+// QuerySponsor queries a sponsor by ID
+func (c *ProofRecordsContract) QuerySponsor(ctx contractapi.TransactionContextInterface, sponsorId string) (string, error) {
+	if err := authorize(ctx, "QuerySponsor"); err != nil {
+		return "", err
+	}
+	manager := NewEntityManager()
+	return manager.QuerySponsor(ctx, sponsorId)
+}
+
+// QueryAllSponsors queries all registered sponsors
+func (c *ProofRecordsContract) QueryAllSponsors(ctx contractapi.TransactionContextInterface) (string, error) {
+	if err := authorize(ctx, "QueryAllSponsors"); err != nil {
+		return "", err
+	}
+	manager := NewEntityManager()
+	return manager.QueryAllSponsors(ctx)
+}
+
+// DeleteSponsor removes a sponsor no longer referenced by any document
+func (c *ProofRecordsContract) DeleteSponsor(ctx contractapi.TransactionContextInterface, sponsorId string) (string, error) {
+	if err := authorize(ctx, "DeleteSponsor"); err != nil {
+		return "", err
+	}
+	manager := NewEntityManager()
+	return manager.DeleteSponsor(ctx, sponsorId)
+}
+
+// CreateCollector registers a collector on the ledger
+func (c *ProofRecordsContract) CreateCollector(ctx contractapi.TransactionContextInterface, collectorData string) (string, error) {
+	if err := authorize(ctx, "CreateCollector"); err != nil {
+		return "", err
+	}
+	manager := NewEntityManager()
+	return manager.CreateCollector(ctx, collectorData)
+}
+
+// UpdateCollector updates a collector registered on the ledger
+func (c *ProofRecordsContract) UpdateCollector(ctx contractapi.TransactionContextInterface, collectorId string, collectorData string) (string, error) {
+	if err := authorize(ctx, "UpdateCollector"); err != nil {
+		return "", err
+	}
+	manager := NewEntityManager()
+	return manager.UpdateCollector(ctx, collectorId, collectorData)
+}
+
+// QueryCollector queries a collector by ID
+func (c *ProofRecordsContract) QueryCollector(ctx contractapi.TransactionContextInterface, collectorId string) (string, error) {
+	if err := authorize(ctx, "QueryCollector"); err != nil {
+		return "", err
+	}
+	manager := NewEntityManager()
+	return manager.QueryCollector(ctx, collectorId)
+}
+
+// QueryAllCollectors queries all registered collectors
+func (c *ProofRecordsContract) QueryAllCollectors(ctx contractapi.TransactionContextInterface) (string, error) {
+	if err := authorize(ctx, "QueryAllCollectors"); err != nil {
+		return "", err
+	}
+	manager := NewEntityManager()
+	return manager.QueryAllCollectors(ctx)
+}
+
+// DeleteCollector removes a collector no longer referenced by any document
+func (c *ProofRecordsContract) DeleteCollector(ctx contractapi.TransactionContextInterface, collectorId string) (string, error) {
+	if err := authorize(ctx, "DeleteCollector"); err != nil {
+		return "", err
+	}
+	manager := NewEntityManager()
+	return manager.DeleteCollector(ctx, collectorId)
+}
+
+// CreateBulk registers a bulk on the ledger
+func (c *ProofRecordsContract) CreateBulk(ctx contractapi.TransactionContextInterface, bulkData string) (string, error) {
+	if err := authorize(ctx, "CreateBulk"); err != nil {
+		return "", err
+	}
+	manager := NewEntityManager()
+	return manager.CreateBulk(ctx, bulkData)
+}
+
+// UpdateBulk updates a bulk registered on the ledger
+func (c *ProofRecordsContract) UpdateBulk(ctx contractapi.TransactionContextInterface, bulkShortId string, bulkData string) (string, error) {
+	if err := authorize(ctx, "UpdateBulk"); err != nil {
+		return "", err
+	}
+	manager := NewEntityManager()
+	return manager.UpdateBulk(ctx, bulkShortId, bulkData)
+}
+
+// QueryBulk queries a bulk by short ID
+func (c *ProofRecordsContract) QueryBulk(ctx contractapi.TransactionContextInterface, bulkShortId string) (string, error) {
+	if err := authorize(ctx, "QueryBulk"); err != nil {
+		return "", err
+	}
+	manager := NewEntityManager()
+	return manager.QueryBulk(ctx, bulkShortId)
+}
+
+// QueryAllBulks queries all registered bulks
+func (c *ProofRecordsContract) QueryAllBulks(ctx contractapi.TransactionContextInterface) (string, error) {
+	if err := authorize(ctx, "QueryAllBulks"); err != nil {
+		return "", err
+	}
+	manager := NewEntityManager()
+	return manager.QueryAllBulks(ctx)
+}
+
+// DeleteBulk removes a bulk no longer referenced by any document
+func (c *ProofRecordsContract) DeleteBulk(ctx contractapi.TransactionContextInterface, bulkShortId string) (string, error) {
+	if err := authorize(ctx, "DeleteBulk"); err != nil {
+		return "", err
+	}
+	manager := NewEntityManager()
+	return manager.DeleteBulk(ctx, bulkShortId)
+}
+
+// GrantRole grants a role to an identity in the on-ledger registry
+func (c *ProofRecordsContract) GrantRole(ctx contractapi.TransactionContextInterface, grantData string) (string, error) {
+	if err := authorize(ctx, "GrantRole"); err != nil {
+		return "", err
+	}
+	registry := NewRoleRegistry()
+	return registry.GrantRole(ctx, grantData)
+}
+
+// RevokeRole revokes a role from an identity in the on-ledger registry
+func (c *ProofRecordsContract) RevokeRole(ctx contractapi.TransactionContextInterface, identity string, role string) (string, error) {
+	if err := authorize(ctx, "RevokeRole"); err != nil {
+		return "", err
+	}
+	registry := NewRoleRegistry()
+	return registry.RevokeRole(ctx, identity, role)
+}
+
+// RevokeSponsor revokes a sponsor from an identity in the on-ledger registry
+func (c *ProofRecordsContract) RevokeSponsor(ctx contractapi.TransactionContextInterface, identity string, sponsorId string) (string, error) {
+	if err := authorize(ctx, "RevokeSponsor"); err != nil {
+		return "", err
+	}
+	registry := NewRoleRegistry()
+	return registry.RevokeSponsor(ctx, identity, sponsorId)
+}
+
+// ListRoleAssignments lists the role assignments of the on-ledger registry
+func (c *ProofRecordsContract) ListRoleAssignments(ctx contractapi.TransactionContextInterface) (string, error) {
+	if err := authorize(ctx, "ListRoleAssignments"); err != nil {
+		return "", err
+	}
+	registry := NewRoleRegistry()
+	return registry.ListRoleAssignments(ctx)
+}
+
+// TransferOwnership transfers a proof record or ticket to another organization
+func (c *ProofRecordsContract) TransferOwnership(ctx contractapi.TransactionContextInterface, key string, newOwnerMSP string) (string, error) {
+	if err := authorize(ctx, "TransferOwnership"); err != nil {
+		return "", err
+	}
+	ownershipManager := NewOwnershipManager()
+	return ownershipManager.TransferOwnership(ctx, key, newOwnerMSP)
+}
+
+// TransferBulkOwnership transfers every proof record of a bulk to another organization
+func (c *ProofRecordsContract) TransferBulkOwnership(ctx contractapi.TransactionContextInterface, bulkShortId string, newOwnerMSP string) (string, error) {
+	if err := authorize(ctx, "TransferBulkOwnership"); err != nil {
+		return "", err
+	}
+	ownershipManager := NewOwnershipManager()
+	return ownershipManager.TransferBulkOwnership(ctx, bulkShortId, newOwnerMSP)
+}
+
+// DelegateOwnership allows another organization to modify a document
+func (c *ProofRecordsContract) DelegateOwnership(ctx contractapi.TransactionContextInterface, key string, delegateMSP string) (string, error) {
+	if err := authorize(ctx, "DelegateOwnership"); err != nil {
+		return "", err
+	}
+	ownershipManager := NewOwnershipManager()
+	return ownershipManager.DelegateOwnership(ctx, key, delegateMSP)
+}
+
+// RevokeDelegation withdraws a delegation from another organization
+func (c *ProofRecordsContract) RevokeDelegation(ctx contractapi.TransactionContextInterface, key string, delegateMSP string) (string, error) {
+	if err := authorize(ctx, "RevokeDelegation"); err != nil {
+		return "", err
+	}
+	ownershipManager := NewOwnershipManager()
+	return ownershipManager.RevokeDelegation(ctx, key, delegateMSP)
+}
+
+// SetSponsorEndorsementPolicy creates or changes the endorsement policy of a sponsor
+func (c *ProofRecordsContract) SetSponsorEndorsementPolicy(ctx contractapi.TransactionContextInterface, policyData string) (string, error) {
+	if err := authorize(ctx, "SetSponsorEndorsementPolicy"); err != nil {
+		return "", err
+	}
+	policyManager := NewEndorsementPolicyManager()
+	return policyManager.SetSponsorEndorsementPolicy(ctx, policyData)
+}
+
+// QuerySponsorEndorsementPolicy queries the endorsement policy of a sponsor
+func (c *ProofRecordsContract) QuerySponsorEndorsementPolicy(ctx contractapi.TransactionContextInterface, sponsorId string) (string, error) {
+	if err := authorize(ctx, "QuerySponsorEndorsementPolicy"); err != nil {
+		return "", err
+	}
+	policyManager := NewEndorsementPolicyManager()
+	return policyManager.QuerySponsorEndorsementPolicy(ctx, sponsorId)
+}
+
+// QueryRecordEndorsementPolicy queries the key-level endorsement policy of a proof record
+func (c *ProofRecordsContract) QueryRecordEndorsementPolicy(ctx contractapi.TransactionContextInterface, recordId string) (string, error) {
+	if err := authorize(ctx, "QueryRecordEndorsementPolicy"); err != nil {
+		return "", err
+	}
+	policyManager := NewEndorsementPolicyManager()
+	return policyManager.QueryRecordEndorsementPolicy(ctx, recordId)
+}
+
+// ApplySponsorEndorsementPolicy re-applies a sponsor's endorsement policy to its proof records
+func (c *ProofRecordsContract) ApplySponsorEndorsementPolicy(ctx contractapi.TransactionContextInterface, sponsorId string) (string, error) {
+	if err := authorize(ctx, "ApplySponsorEndorsementPolicy"); err != nil {
+		return "", err
+	}
+	policyManager := NewEndorsementPolicyManager()
+	return policyManager.ApplySponsorEndorsementPolicy(ctx, sponsorId)
+}
+
+// ProposeViolationAction proposes deleting or voiding the records of weight violations
+func (c *ProofRecordsContract) ProposeViolationAction(ctx contractapi.TransactionContextInterface, proposalData string) (string, error) {
+	if err := authorize(ctx, "ProposeViolationAction"); err != nil {
+		return "", err
+	}
+	governanceManager := NewGovernanceManager()
+	return governanceManager.ProposeViolationAction(ctx, proposalData)
+}
+
+// ApproveProposal approves a violation proposal, executing it once the quorum is reached
+func (c *ProofRecordsContract) ApproveProposal(ctx contractapi.TransactionContextInterface, proposalId string) (string, error) {
+	if err := authorize(ctx, "ApproveProposal"); err != nil {
+		return "", err
+	}
+	governanceManager := NewGovernanceManager()
+	return governanceManager.ApproveProposal(ctx, proposalId)
+}
+
+// QueryProposal queries a violation proposal by ID
+func (c *ProofRecordsContract) QueryProposal(ctx contractapi.TransactionContextInterface, proposalId string) (string, error) {
+	if err := authorize(ctx, "QueryProposal"); err != nil {
+		return "", err
+	}
+	governanceManager := NewGovernanceManager()
+	return governanceManager.QueryProposal(ctx, proposalId)
+}
+
+// QueryProposalsByStatus queries violation proposals by status
+func (c *ProofRecordsContract) QueryProposalsByStatus(ctx contractapi.TransactionContextInterface, status string) (string, error) {
+	if err := authorize(ctx, "QueryProposalsByStatus"); err != nil {
+		return "", err
+	}
+	governanceManager := NewGovernanceManager()
+	return governanceManager.QueryProposalsByStatus(ctx, status)
+}
+
+// ErasePersonalData erases the personal data of a proof record, ticket or collector
+func (c *ProofRecordsContract) ErasePersonalData(ctx contractapi.TransactionContextInterface, key string, reason string) (string, error) {
+	if err := authorize(ctx, "ErasePersonalData"); err != nil {
+		return "", err
+	}
+	erasureManager := NewErasureManager()
+	return erasureManager.ErasePersonalData(ctx, key, reason)
+}
+
+// EraseCollectorPersonalData erases the personal data of every document of the collector passed in the transient map
+func (c *ProofRecordsContract) EraseCollectorPersonalData(ctx contractapi.TransactionContextInterface, reason string) (string, error) {
+	if err := authorize(ctx, "EraseCollectorPersonalData"); err != nil {
+		return "", err
+	}
+	erasureManager := NewErasureManager()
+	return erasureManager.EraseCollectorPersonalData(ctx, reason)
+}
+
+// IssueCredits issues credits against the proof records of a reconciled increment
+func (c *ProofRecordsContract) IssueCredits(ctx contractapi.TransactionContextInterface, issuanceData string) (string, error) {
+	if err := authorize(ctx, "IssueCredits"); err != nil {
+		return "", err
+	}
+	creditManager := NewCreditManager()
+	return creditManager.IssueCredits(ctx, issuanceData)
+}
+
+// QueryCredit queries a credit by ID
+func (c *ProofRecordsContract) QueryCredit(ctx contractapi.TransactionContextInterface, creditId string) (string, error) {
+	if err := authorize(ctx, "QueryCredit"); err != nil {
+		return "", err
+	}
+	creditManager := NewCreditManager()
+	return creditManager.QueryCredit(ctx, creditId)
+}
+
+// QueryCreditByRecord queries the credit a proof record backs
+func (c *ProofRecordsContract) QueryCreditByRecord(ctx contractapi.TransactionContextInterface, recordId string) (string, error) {
+	if err := authorize(ctx, "QueryCreditByRecord"); err != nil {
+		return "", err
+	}
+	creditManager := NewCreditManager()
+	return creditManager.QueryCreditByRecord(ctx, recordId)
+}
+
+// Transfer transfers credits owned by the caller to another owner
+func (c *ProofRecordsContract) Transfer(ctx contractapi.TransactionContextInterface, transferData string) (string, error) {
+	if err := authorize(ctx, "Transfer"); err != nil {
+		return "", err
+	}
+	creditManager := NewCreditManager()
+	return creditManager.Transfer(ctx, transferData)
+}
+
+// Retire retires credits owned by the caller against a claim
+func (c *ProofRecordsContract) Retire(ctx contractapi.TransactionContextInterface, retirementData string) (string, error) {
+	if err := authorize(ctx, "Retire"); err != nil {
+		return "", err
+	}
+	creditManager := NewCreditManager()
+	return creditManager.Retire(ctx, retirementData)
+}
+
+// BalanceOf returns the active and retired credits of an owner
+func (c *ProofRecordsContract) BalanceOf(ctx contractapi.TransactionContextInterface, ownerType string, owner string) (string, error) {
+	if err := authorize(ctx, "BalanceOf"); err != nil {
+		return "", err
+	}
+	creditManager := NewCreditManager()
+	return creditManager.BalanceOf(ctx, ownerType, owner)
+}
+
+// QueryRetirementCertificate queries a retirement certificate by ID
+func (c *ProofRecordsContract) QueryRetirementCertificate(ctx contractapi.TransactionContextInterface, certificateId string) (string, error) {
+	if err := authorize(ctx, "QueryRetirementCertificate"); err != nil {
+		return "", err
+	}
+	creditManager := NewCreditManager()
+	return creditManager.QueryRetirementCertificate(ctx, certificateId)
+}
@@ -0,0 +1,360 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Owner types of credits
+const (
+	CreditOwnerMSP    = "msp"
+	CreditOwnerClient = "client"
+)
+
+// Credit statuses
+const (
+	CreditStatusActive  = "active"
+	CreditStatusRetired = "retired"
+)
+
+// CreditUnit is the unit credits are denominated in, kilograms of chained weight
+const CreditUnit = "kg"
+
+// Credit represents a lot of recycling credits backed by reconciled proof records
+type Credit struct {
+	CreditID       string   `json:"creditId"`
+	SponsorID      string   `json:"sponsor_id"`
+	Amount         float64  `json:"amount"`
+	Unit           string   `json:"unit"`
+	OwnerType      string   `json:"ownerType"`
+	Owner          string   `json:"owner"`
+	Status         string   `json:"status"`
+	IncrementField string   `json:"incrementField"`
+	IncrementID    int      `json:"incrementId"`
+	SourceRecords  []string `json:"sourceRecords"`
+	ParentCreditID string   `json:"parentCreditId,omitempty"`
+	CertificateID  string   `json:"retirementCertificateId,omitempty"`
+	IssuedAt       string   `json:"issuedAt"`
+	IssuedBy       string   `json:"issuedBy"`
+	IssuerMSP      string   `json:"issuerMSP"`
+	DocType        string   `json:"docType"`
+}
+
+// CreditBacking marks a proof record as backing a credit, so it cannot back another one
+type CreditBacking struct {
+	RecordID string `json:"recordId"`
+	CreditID string `json:"creditId"`
+	DocType  string `json:"docType"`
+}
+
+// CreditIssuance represents the data of an IssueCredits call
+type CreditIssuance struct {
+	SponsorID      string  `json:"sponsor_id"`
+	IncrementField string  `json:"incrementField"`
+	IncrementID    float64 `json:"incrementId"`
+	OwnerType      string  `json:"ownerType"`
+	Owner          string  `json:"owner"`
+}
+
+// CreditResponse represents the response of a credit operation
+type CreditResponse struct {
+	Success bool    `json:"success"`
+	Message string  `json:"message"`
+	Credit  *Credit `json:"credit,omitempty"`
+}
+
+// CreditsIssuedPayload is the payload of a CreditsIssued event
+type CreditsIssuedPayload struct {
+	CreditID      string   `json:"creditId"`
+	SponsorID     string   `json:"sponsor_id"`
+	Amount        float64  `json:"amount"`
+	OwnerType     string   `json:"ownerType"`
+	Owner         string   `json:"owner"`
+	SourceRecords []string `json:"sourceRecords"`
+}
+
+// CreditManager handles the issuance of recycling credits
+type CreditManager struct{}
+
+// NewCreditManager creates a new CreditManager instance
+func NewCreditManager() *CreditManager {
+	return &CreditManager{}
+}
+
+// IssueCredits issues credits against the proof records of a sponsor's increment group that reconciled
+// without violation, balanced or under the received weight. The group is compared with the aggregation
+// and tolerance of the ledger comparison config. Records that already back a credit are left out, the
+// amount is the sum of the chained weight of the remaining records.
+func (cm *CreditManager) IssueCredits(ctx contractapi.TransactionContextInterface, issuanceData string) (string, error) {
+	logger.Println("============= START : Issue Credits ===========")
+
+	var issuance CreditIssuance
+	err := json.Unmarshal([]byte(issuanceData), &issuance)
+	if err != nil {
+		return creditErrorResponse(fmt.Errorf("invalid issuance: %v", err))
+	}
+	if issuance.SponsorID == "" {
+		return creditErrorResponse(fmt.Errorf("invalid issuance: sponsor_id is required"))
+	}
+	if issuance.IncrementField != "press_increment" && issuance.IncrementField != "store_increment" {
+		return creditErrorResponse(fmt.Errorf("invalid issuance: incrementField must be press_increment or store_increment"))
+	}
+
+	creator, err := getCreatorInfo(ctx)
+	if err != nil {
+		return creditErrorResponse(err)
+	}
+	if issuance.OwnerType == "" && issuance.Owner == "" {
+		issuance.OwnerType = CreditOwnerMSP
+		issuance.Owner = creator.MSPID
+	}
+	err = validateCreditOwner(issuance.OwnerType, issuance.Owner)
+	if err != nil {
+		return creditErrorResponse(err)
+	}
+
+	// Reconcile the sponsor's group alone, callers cannot loosen the comparison config
+	options := &ComparisonOptions{
+		Scope: &ComparisonScope{
+			SponsorID:    issuance.SponsorID,
+			IncrementIDs: []float64{issuance.IncrementID},
+		},
+	}
+	optionsJSON, _ := json.Marshal(options)
+
+	wc := NewWeightComparison()
+	comparisonOptions, err := wc.parseOptions(ctx, string(optionsJSON))
+	if err != nil {
+		return creditErrorResponse(err)
+	}
+	access, err := getTenantAccess(ctx)
+	if err != nil {
+		return creditErrorResponse(err)
+	}
+	groupedResults, err := wc.groupByIncrement(ctx, issuance.IncrementField, comparisonOptions, access)
+	if err != nil {
+		return creditErrorResponse(err)
+	}
+
+	group, exists := groupedResults[GroupKey{SponsorID: issuance.SponsorID, IncrementID: issuance.IncrementID}]
+	if !exists {
+		return creditErrorResponse(fmt.Errorf("no proof records for sponsor %s at %s %v", issuance.SponsorID, issuance.IncrementField, issuance.IncrementID))
+	}
+	receivedWeight := aggregateTickets(group.Tickets, comparisonOptions.Aggregation)
+	status := classifyGroup(group, receivedWeight, comparisonOptions.Tolerance)
+	if status != StatusBalanced && status != StatusUnder {
+		return creditErrorResponse(fmt.Errorf("increment %v of sponsor %s is %s, only increments without violation back credits", issuance.IncrementID, issuance.SponsorID, status))
+	}
+
+	creditID := fmt.Sprintf("CREDIT_%s", ctx.GetStub().GetTxID())
+	sourceRecords := []string{}
+	amount := 0.0
+	for _, record := range group.Records {
+		backing, err := getCreditBacking(ctx, record.RecordID)
+		if err != nil {
+			return creditErrorResponse(err)
+		}
+		if backing != nil {
+			continue
+		}
+
+		backingJSON, _ := json.Marshal(CreditBacking{
+			RecordID: record.RecordID,
+			CreditID: creditID,
+			DocType:  "creditBacking",
+		})
+		err = ctx.GetStub().PutState(creditBackingKey(record.RecordID), backingJSON)
+		if err != nil {
+			return "", fmt.Errorf("failed to put credit backing to world state: %v", err)
+		}
+		sourceRecords = append(sourceRecords, record.RecordID)
+		amount += record.ChainedWeight
+	}
+	if len(sourceRecords) == 0 {
+		return creditErrorResponse(fmt.Errorf("every proof record of increment %v of sponsor %s already backs a credit", issuance.IncrementID, issuance.SponsorID))
+	}
+
+	credit := &Credit{
+		CreditID:       creditID,
+		SponsorID:      issuance.SponsorID,
+		Amount:         math.Round(amount*100) / 100,
+		Unit:           CreditUnit,
+		OwnerType:      issuance.OwnerType,
+		Owner:          issuance.Owner,
+		Status:         CreditStatusActive,
+		IncrementField: issuance.IncrementField,
+		IncrementID:    int(issuance.IncrementID),
+		SourceRecords:  sourceRecords,
+		IssuedAt:       getTxTimestamp(ctx),
+		IssuedBy:       creator.ID,
+		IssuerMSP:      creator.MSPID,
+		DocType:        "credit",
+	}
+	// Backing markers are already written, failures from here on must abort the transaction
+	err = putCredit(ctx, credit)
+	if err != nil {
+		return "", err
+	}
+
+	err = emitEvents(ctx, []EventEntry{{
+		Type: EventCreditsIssued,
+		Payload: CreditsIssuedPayload{
+			CreditID:      credit.CreditID,
+			SponsorID:     credit.SponsorID,
+			Amount:        credit.Amount,
+			OwnerType:     credit.OwnerType,
+			Owner:         credit.Owner,
+			SourceRecords: credit.SourceRecords,
+		},
+	}})
+	if err != nil {
+		return "", err
+	}
+
+	logger.Println("============= END : Issue Credits ===========")
+
+	response := CreditResponse{
+		Success: true,
+		Message: fmt.Sprintf("Issued %.2f %s of credits", credit.Amount, CreditUnit),
+		Credit:  credit,
+	}
+	responseJSON, _ := json.Marshal(response)
+	return string(responseJSON), nil
+}
+
+// QueryCredit queries a credit by ID
+func (cm *CreditManager) QueryCredit(ctx contractapi.TransactionContextInterface, creditID string) (string, error) {
+	credit, err := getCredit(ctx, creditID)
+	if err != nil {
+		return "", err
+	}
+
+	creditJSON, err := json.Marshal(credit)
+	if err != nil {
+		return "", err
+	}
+	return string(creditJSON), nil
+}
+
+// QueryCreditByRecord queries the credit a proof record backs
+func (cm *CreditManager) QueryCreditByRecord(ctx contractapi.TransactionContextInterface, recordID string) (string, error) {
+	backing, err := getCreditBacking(ctx, recordID)
+	if err != nil {
+		return "", err
+	}
+	if backing == nil {
+		return "", fmt.Errorf("Proof record %s backs no credit", recordID)
+	}
+	return cm.QueryCredit(ctx, backing.CreditID)
+}
+
+func validateCreditOwner(ownerType string, owner string) error {
+	if ownerType != CreditOwnerMSP && ownerType != CreditOwnerClient {
+		return fmt.Errorf("invalid credit owner: ownerType must be %s or %s", CreditOwnerMSP, CreditOwnerClient)
+	}
+	if owner == "" {
+		return fmt.Errorf("invalid credit owner: owner is required")
+	}
+	return nil
+}
+
+// creditBackedError is returned when a proof record backing a credit would be deleted or voided,
+// which would leave the credit without the records it was issued against
+func creditBackedError(backing *CreditBacking) error {
+	return fmt.Errorf("%s backs credit %s and cannot be deleted or voided", backing.RecordID, backing.CreditID)
+}
+
+func creditBackingKey(recordID string) string {
+	return fmt.Sprintf("CREDIT_BACKING_%s", recordID)
+}
+
+func getCreditBacking(ctx contractapi.TransactionContextInterface, recordID string) (*CreditBacking, error) {
+	backingAsBytes, err := ctx.GetStub().GetState(creditBackingKey(recordID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if len(backingAsBytes) == 0 {
+		return nil, nil
+	}
+
+	var backing CreditBacking
+	err = json.Unmarshal(backingAsBytes, &backing)
+	if err != nil {
+		return nil, fmt.Errorf("invalid credit backing of %s: %v", recordID, err)
+	}
+	return &backing, nil
+}
+
+// getCredit reads a credit visible to the caller's sponsors or owned by the caller
+func getCredit(ctx contractapi.TransactionContextInterface, creditID string) (*Credit, error) {
+	creditAsBytes, err := ctx.GetStub().GetState(creditID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if len(creditAsBytes) == 0 {
+		return nil, fmt.Errorf("Credit %s does not exist", creditID)
+	}
+
+	var credit Credit
+	err = json.Unmarshal(creditAsBytes, &credit)
+	if err != nil || credit.DocType != "credit" {
+		return nil, fmt.Errorf("Credit %s does not exist", creditID)
+	}
+
+	// Owners see their credits even without access to the sponsor
+	access, err := getTenantAccess(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !access.CanSee(credit.SponsorID) {
+		owns, err := callerOwns(ctx, credit.OwnerType, credit.Owner)
+		if err != nil {
+			return nil, err
+		}
+		if !owns {
+			return nil, fmt.Errorf("Credit %s does not exist", creditID)
+		}
+	}
+	return &credit, nil
+}
+
+// callerOwns reports whether the caller's organization or identity is the given credit owner
+func callerOwns(ctx contractapi.TransactionContextInterface, ownerType string, owner string) (bool, error) {
+	if ownerType == CreditOwnerMSP {
+		mspID, err := ctx.GetClientIdentity().GetMSPID()
+		if err != nil {
+			return false, fmt.Errorf("failed to read client MSP ID: %v", err)
+		}
+		return mspID == owner, nil
+	}
+
+	identities, err := getCallerIdentities(ctx)
+	if err != nil {
+		return false, err
+	}
+	return containsString(identities, owner), nil
+}
+
+func putCredit(ctx contractapi.TransactionContextInterface, credit *Credit) error {
+	creditJSON, err := json.Marshal(credit)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(credit.CreditID, creditJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put credit to world state: %v", err)
+	}
+	return nil
+}
+
+func creditErrorResponse(err error) (string, error) {
+	response := CreditResponse{
+		Success: false,
+		Message: fmt.Sprintf("Error processing credits: %v", err),
+	}
+	responseJSON, _ := json.Marshal(response)
+	return string(responseJSON), nil
+}
@@ -0,0 +1,368 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// CreditTransfer represents the data of a Transfer call
+type CreditTransfer struct {
+	CreditID    string  `json:"creditId"`
+	Amount      float64 `json:"amount"`
+	ToOwnerType string  `json:"toOwnerType"`
+	ToOwner     string  `json:"toOwner"`
+}
+
+// CreditRetirement represents the data of a Retire call
+type CreditRetirement struct {
+	CreditID    string  `json:"creditId"`
+	Amount      float64 `json:"amount"`
+	Beneficiary string  `json:"beneficiary"`
+	Claim       string  `json:"claim"`
+}
+
+// RetirementCertificate records the retirement of credits against a claim
+type RetirementCertificate struct {
+	CertificateID string   `json:"certificateId"`
+	CreditID      string   `json:"creditId"`
+	SponsorID     string   `json:"sponsor_id"`
+	Amount        float64  `json:"amount"`
+	Unit          string   `json:"unit"`
+	OwnerType     string   `json:"ownerType"`
+	Owner         string   `json:"owner"`
+	Beneficiary   string   `json:"beneficiary"`
+	Claim         string   `json:"claim"`
+	SourceRecords []string `json:"sourceRecords"`
+	RetiredAt     string   `json:"retiredAt"`
+	RetiredBy     string   `json:"retiredBy"`
+	TxID          string   `json:"txId"`
+	DocType       string   `json:"docType"`
+}
+
+// CreditBalance represents the active and retired credits of an owner
+type CreditBalance struct {
+	OwnerType string   `json:"ownerType"`
+	Owner     string   `json:"owner"`
+	Balance   float64  `json:"balance"`
+	Retired   float64  `json:"retired"`
+	Unit      string   `json:"unit"`
+	Credits   []Credit `json:"credits"`
+}
+
+// CreditsTransferredPayload is the payload of a CreditsTransferred event
+type CreditsTransferredPayload struct {
+	CreditID      string  `json:"creditId"`
+	FromCreditID  string  `json:"fromCreditId"`
+	Amount        float64 `json:"amount"`
+	FromOwnerType string  `json:"fromOwnerType"`
+	FromOwner     string  `json:"fromOwner"`
+	ToOwnerType   string  `json:"toOwnerType"`
+	ToOwner       string  `json:"toOwner"`
+}
+
+// CreditsRetiredPayload is the payload of a CreditsRetired event
+type CreditsRetiredPayload struct {
+	CreditID      string  `json:"creditId"`
+	CertificateID string  `json:"certificateId"`
+	Amount        float64 `json:"amount"`
+	Beneficiary   string  `json:"beneficiary"`
+}
+
+// Transfer moves credits to another owner. Transferring part of a lot splits it, the
+// transferred amount becomes a new lot that keeps the source records of its parent.
+func (cm *CreditManager) Transfer(ctx contractapi.TransactionContextInterface, transferData string) (string, error) {
+	logger.Println("============= START : Transfer Credits ===========")
+
+	var transfer CreditTransfer
+	err := json.Unmarshal([]byte(transferData), &transfer)
+	if err != nil {
+		return creditErrorResponse(fmt.Errorf("invalid transfer: %v", err))
+	}
+	err = validateCreditOwner(transfer.ToOwnerType, transfer.ToOwner)
+	if err != nil {
+		return creditErrorResponse(err)
+	}
+
+	credit, err := getOwnedActiveCredit(ctx, transfer.CreditID)
+	if err != nil {
+		return creditErrorResponse(err)
+	}
+	amount, err := creditAmount(credit, transfer.Amount)
+	if err != nil {
+		return creditErrorResponse(err)
+	}
+
+	fromOwnerType, fromOwner := credit.OwnerType, credit.Owner
+	lot, err := splitCredit(ctx, credit, amount)
+	if err != nil {
+		return "", err
+	}
+	lot.OwnerType = transfer.ToOwnerType
+	lot.Owner = transfer.ToOwner
+	err = putCredit(ctx, lot)
+	if err != nil {
+		return "", err
+	}
+
+	err = emitEvents(ctx, []EventEntry{{
+		Type: EventCreditsTransferred,
+		Payload: CreditsTransferredPayload{
+			CreditID:      lot.CreditID,
+			FromCreditID:  credit.CreditID,
+			Amount:        lot.Amount,
+			FromOwnerType: fromOwnerType,
+			FromOwner:     fromOwner,
+			ToOwnerType:   lot.OwnerType,
+			ToOwner:       lot.Owner,
+		},
+	}})
+	if err != nil {
+		return "", err
+	}
+
+	logger.Println("============= END : Transfer Credits ===========")
+
+	response := CreditResponse{
+		Success: true,
+		Message: fmt.Sprintf("Transferred %.2f %s of credits", lot.Amount, CreditUnit),
+		Credit:  lot,
+	}
+	responseJSON, _ := json.Marshal(response)
+	return string(responseJSON), nil
+}
+
+// Retire retires credits against a claim and records a retirement certificate. Retired
+// credits can neither be transferred nor retired again.
+func (cm *CreditManager) Retire(ctx contractapi.TransactionContextInterface, retirementData string) (string, error) {
+	logger.Println("============= START : Retire Credits ===========")
+
+	var retirement CreditRetirement
+	err := json.Unmarshal([]byte(retirementData), &retirement)
+	if err != nil {
+		return creditErrorResponse(fmt.Errorf("invalid retirement: %v", err))
+	}
+	if retirement.Claim == "" {
+		return creditErrorResponse(fmt.Errorf("invalid retirement: claim is required"))
+	}
+
+	credit, err := getOwnedActiveCredit(ctx, retirement.CreditID)
+	if err != nil {
+		return creditErrorResponse(err)
+	}
+	amount, err := creditAmount(credit, retirement.Amount)
+	if err != nil {
+		return creditErrorResponse(err)
+	}
+
+	creator, err := getCreatorInfo(ctx)
+	if err != nil {
+		return creditErrorResponse(err)
+	}
+
+	lot, err := splitCredit(ctx, credit, amount)
+	if err != nil {
+		return "", err
+	}
+
+	txID := ctx.GetStub().GetTxID()
+	certificate := RetirementCertificate{
+		CertificateID: fmt.Sprintf("RETIREMENT_%s", txID),
+		CreditID:      lot.CreditID,
+		SponsorID:     lot.SponsorID,
+		Amount:        lot.Amount,
+		Unit:          lot.Unit,
+		OwnerType:     lot.OwnerType,
+		Owner:         lot.Owner,
+		Beneficiary:   retirement.Beneficiary,
+		Claim:         retirement.Claim,
+		SourceRecords: lot.SourceRecords,
+		RetiredAt:     getTxTimestamp(ctx),
+		RetiredBy:     creator.ID,
+		TxID:          txID,
+		DocType:       "retirementCertificate",
+	}
+	certificateJSON, err := json.Marshal(certificate)
+	if err != nil {
+		return "", err
+	}
+	err = ctx.GetStub().PutState(certificate.CertificateID, certificateJSON)
+	if err != nil {
+		return "", fmt.Errorf("failed to put retirement certificate to world state: %v", err)
+	}
+
+	lot.Status = CreditStatusRetired
+	lot.CertificateID = certificate.CertificateID
+	err = putCredit(ctx, lot)
+	if err != nil {
+		return "", err
+	}
+
+	err = emitEvents(ctx, []EventEntry{{
+		Type: EventCreditsRetired,
+		Payload: CreditsRetiredPayload{
+			CreditID:      lot.CreditID,
+			CertificateID: certificate.CertificateID,
+			Amount:        lot.Amount,
+			Beneficiary:   certificate.Beneficiary,
+		},
+	}})
+	if err != nil {
+		return "", err
+	}
+
+	logger.Println("============= END : Retire Credits ===========")
+
+	response := CreditResponse{
+		Success: true,
+		Message: fmt.Sprintf("Retired %.2f %s of credits, certificate %s", lot.Amount, CreditUnit, certificate.CertificateID),
+		Credit:  lot,
+	}
+	responseJSON, _ := json.Marshal(response)
+	return string(responseJSON), nil
+}
+
+// BalanceOf returns the active and retired credits of an owner. Callers other than the
+// owner only see the credits of their sponsors.
+func (cm *CreditManager) BalanceOf(ctx contractapi.TransactionContextInterface, ownerType string, owner string) (string, error) {
+	err := validateCreditOwner(ownerType, owner)
+	if err != nil {
+		return "", err
+	}
+
+	access, err := getTenantAccess(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	selector := map[string]interface{}{
+		"docType":   "credit",
+		"ownerType": ownerType,
+		"owner":     owner,
+	}
+	owns, err := callerOwns(ctx, ownerType, owner)
+	if err != nil {
+		return "", err
+	}
+	if !owns {
+		access.restrictSelector(selector)
+	}
+
+	results, err := queryDocuments(ctx, selector)
+	if err != nil {
+		return "", err
+	}
+
+	balance := CreditBalance{
+		OwnerType: ownerType,
+		Owner:     owner,
+		Unit:      CreditUnit,
+		Credits:   []Credit{},
+	}
+	for _, result := range results {
+		recordJSON, _ := json.Marshal(result["Record"])
+		var credit Credit
+		if json.Unmarshal(recordJSON, &credit) != nil {
+			continue
+		}
+		if credit.Status == CreditStatusRetired {
+			balance.Retired += credit.Amount
+		} else {
+			balance.Balance += credit.Amount
+		}
+		balance.Credits = append(balance.Credits, credit)
+	}
+	balance.Balance = math.Round(balance.Balance*100) / 100
+	balance.Retired = math.Round(balance.Retired*100) / 100
+
+	balanceJSON, err := json.Marshal(balance)
+	if err != nil {
+		return "", err
+	}
+	return string(balanceJSON), nil
+}
+
+// QueryRetirementCertificate queries a retirement certificate by ID
+func (cm *CreditManager) QueryRetirementCertificate(ctx contractapi.TransactionContextInterface, certificateID string) (string, error) {
+	certificateAsBytes, err := ctx.GetStub().GetState(certificateID)
+	if err != nil {
+		return "", fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if len(certificateAsBytes) == 0 {
+		return "", fmt.Errorf("Retirement certificate %s does not exist", certificateID)
+	}
+
+	var certificate RetirementCertificate
+	err = json.Unmarshal(certificateAsBytes, &certificate)
+	if err != nil || certificate.DocType != "retirementCertificate" {
+		return "", fmt.Errorf("Retirement certificate %s does not exist", certificateID)
+	}
+
+	access, err := getTenantAccess(ctx)
+	if err != nil {
+		return "", err
+	}
+	owns, err := callerOwns(ctx, certificate.OwnerType, certificate.Owner)
+	if err != nil {
+		return "", err
+	}
+	if !access.CanSee(certificate.SponsorID) && !owns {
+		return "", fmt.Errorf("Retirement certificate %s does not exist", certificateID)
+	}
+	return string(certificateAsBytes), nil
+}
+
+// getOwnedActiveCredit reads an active credit owned by the caller's organization or identity
+func getOwnedActiveCredit(ctx contractapi.TransactionContextInterface, creditID string) (*Credit, error) {
+	credit, err := getCredit(ctx, creditID)
+	if err != nil {
+		return nil, err
+	}
+	if credit.Status != CreditStatusActive {
+		return nil, fmt.Errorf("credit %s is %s", creditID, credit.Status)
+	}
+
+	owns, err := callerOwns(ctx, credit.OwnerType, credit.Owner)
+	if err != nil {
+		return nil, err
+	}
+	if !owns {
+		return nil, fmt.Errorf("credit %s is not owned by the caller", creditID)
+	}
+	return credit, nil
+}
+
+// creditAmount returns the amount of an operation on a credit, the whole lot when zero
+func creditAmount(credit *Credit, amount float64) (float64, error) {
+	if amount == 0 {
+		return credit.Amount, nil
+	}
+	amount = math.Round(amount*100) / 100
+	if amount <= 0 || amount > credit.Amount {
+		return 0, fmt.Errorf("invalid amount %.2f, credit %s holds %.2f %s", amount, credit.CreditID, credit.Amount, CreditUnit)
+	}
+	return amount, nil
+}
+
+// splitCredit returns the lot an operation on the given amount applies to. The whole credit is
+// returned as is, a partial amount is split off into a new lot and deducted from the credit.
+func splitCredit(ctx contractapi.TransactionContextInterface, credit *Credit, amount float64) (*Credit, error) {
+	if amount == credit.Amount {
+		return credit, nil
+	}
+
+	lot := *credit
+	lot.CreditID = fmt.Sprintf("CREDIT_%s", ctx.GetStub().GetTxID())
+	lot.Amount = amount
+	lot.ParentCreditID = credit.CreditID
+	lot.SourceRecords = append([]string{}, credit.SourceRecords...)
+
+	credit.Amount = math.Round((credit.Amount-amount)*100) / 100
+	err := putCredit(ctx, credit)
+	if err != nil {
+		return nil, err
+	}
+	return &lot, nil
+}
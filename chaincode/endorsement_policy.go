@@ -0,0 +1,267 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/fabric-chaincode-go/pkg/statebased"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// SponsorPolicy represents the organizations that must endorse changes to a sponsor's proof records
+type SponsorPolicy struct {
+	SponsorID      string   `json:"sponsor_id"`
+	SponsorMSP     string   `json:"sponsorMSP"`
+	AdditionalMSPs []string `json:"additionalMSPs"`
+	RoleType       string   `json:"roleType"`
+	UpdatedAt      string   `json:"updatedAt"`
+	UpdatedBy      string   `json:"updatedBy"`
+	DocType        string   `json:"docType"`
+}
+
+// RecordEndorsementPolicy represents the key-level endorsement policy of a proof record
+type RecordEndorsementPolicy struct {
+	RecordID string   `json:"recordId"`
+	Orgs     []string `json:"orgs"`
+}
+
+// EndorsementPolicyManager handles per-sponsor key-level endorsement policies
+type EndorsementPolicyManager struct{}
+
+// NewEndorsementPolicyManager creates a new EndorsementPolicyManager instance
+func NewEndorsementPolicyManager() *EndorsementPolicyManager {
+	return &EndorsementPolicyManager{}
+}
+
+// SetSponsorEndorsementPolicy creates or changes the endorsement policy of a sponsor.
+// An existing policy may be changed by an admin or by the sponsor's own organization.
+func (epm *EndorsementPolicyManager) SetSponsorEndorsementPolicy(ctx contractapi.TransactionContextInterface, policyData string) (string, error) {
+	var policy SponsorPolicy
+	err := json.Unmarshal([]byte(policyData), &policy)
+	if err != nil {
+		return "", fmt.Errorf("invalid sponsor policy: %v", err)
+	}
+	if policy.SponsorID == "" || policy.SponsorMSP == "" {
+		return "", fmt.Errorf("invalid sponsor policy: sponsor_id and sponsorMSP are required")
+	}
+	if policy.RoleType == "" {
+		policy.RoleType = string(statebased.RoleTypePeer)
+	}
+	if policy.RoleType != string(statebased.RoleTypePeer) && policy.RoleType != string(statebased.RoleTypeMember) {
+		return "", fmt.Errorf("invalid sponsor policy: unknown role type %s", policy.RoleType)
+	}
+
+	existing, err := getSponsorPolicy(ctx, policy.SponsorID)
+	if err != nil {
+		return "", err
+	}
+	err = checkSponsorPolicyAccess(ctx, policy.SponsorID, existing)
+	if err != nil {
+		return "", err
+	}
+
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return "", fmt.Errorf("failed to read client identity: %v", err)
+	}
+
+	if policy.AdditionalMSPs == nil {
+		policy.AdditionalMSPs = []string{}
+	}
+	sort.Strings(policy.AdditionalMSPs)
+	policy.UpdatedAt = getTxTimestamp(ctx)
+	policy.UpdatedBy = clientID
+	policy.DocType = "sponsorPolicy"
+
+	policyJSON, err := json.Marshal(policy)
+	if err != nil {
+		return "", err
+	}
+
+	err = ctx.GetStub().PutState(sponsorPolicyKey(policy.SponsorID), policyJSON)
+	if err != nil {
+		return "", fmt.Errorf("failed to put sponsor policy to world state: %v", err)
+	}
+
+	return string(policyJSON), nil
+}
+
+// QuerySponsorEndorsementPolicy returns the endorsement policy of a sponsor
+func (epm *EndorsementPolicyManager) QuerySponsorEndorsementPolicy(ctx contractapi.TransactionContextInterface, sponsorID string) (string, error) {
+	policy, err := getSponsorPolicy(ctx, sponsorID)
+	if err != nil {
+		return "", err
+	}
+	if policy == nil {
+		return "", fmt.Errorf("Sponsor policy %s does not exist", sponsorID)
+	}
+
+	err = checkSponsorPolicyAccess(ctx, sponsorID, policy)
+	if err != nil {
+		return "", err
+	}
+
+	policyJSON, err := json.Marshal(policy)
+	if err != nil {
+		return "", err
+	}
+	return string(policyJSON), nil
+}
+
+// QueryRecordEndorsementPolicy lists the organizations required to endorse changes to a proof record
+func (epm *EndorsementPolicyManager) QueryRecordEndorsementPolicy(ctx contractapi.TransactionContextInterface, recordID string) (string, error) {
+	policyBytes, err := ctx.GetStub().GetStateValidationParameter(recordID)
+	if err != nil {
+		return "", fmt.Errorf("failed to read endorsement policy of %s: %v", recordID, err)
+	}
+
+	orgs := []string{}
+	if len(policyBytes) > 0 {
+		endorsementPolicy, err := statebased.NewStateEP(policyBytes)
+		if err != nil {
+			return "", fmt.Errorf("invalid endorsement policy of %s: %v", recordID, err)
+		}
+		orgs = endorsementPolicy.ListOrgs()
+		sort.Strings(orgs)
+	}
+
+	policyJSON, err := json.Marshal(RecordEndorsementPolicy{RecordID: recordID, Orgs: orgs})
+	if err != nil {
+		return "", err
+	}
+	return string(policyJSON), nil
+}
+
+// ApplySponsorEndorsementPolicy re-applies the current sponsor policy to every proof record of the sponsor
+func (epm *EndorsementPolicyManager) ApplySponsorEndorsementPolicy(ctx contractapi.TransactionContextInterface, sponsorID string) (string, error) {
+	policy, err := getSponsorPolicy(ctx, sponsorID)
+	if err != nil {
+		return "", err
+	}
+	if policy == nil {
+		return "", fmt.Errorf("Sponsor policy %s does not exist", sponsorID)
+	}
+
+	err = checkSponsorPolicyAccess(ctx, sponsorID, policy)
+	if err != nil {
+		return "", err
+	}
+
+	records, err := queryDocuments(ctx, map[string]interface{}{
+		"docType":    "proofRecord",
+		"sponsor_id": sponsorID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	updated := []RecordEndorsementPolicy{}
+	for _, item := range records {
+		recordID := item["Key"].(string)
+		record := item["Record"].(map[string]interface{})
+		creatorMSP, _ := record["ownerMSP"].(string)
+
+		orgs, err := applyRecordEndorsementPolicy(ctx, recordID, policy, creatorMSP)
+		if err != nil {
+			return "", err
+		}
+		updated = append(updated, RecordEndorsementPolicy{RecordID: recordID, Orgs: orgs})
+	}
+
+	updatedJSON, err := json.Marshal(updated)
+	if err != nil {
+		return "", err
+	}
+	return string(updatedJSON), nil
+}
+
+// setRecordEndorsementPolicy requires the sponsor's organizations and the creating
+// organization to endorse any later change to a proof record
+func setRecordEndorsementPolicy(ctx contractapi.TransactionContextInterface, recordID string, sponsorID string, creatorMSP string) error {
+	policy, err := getSponsorPolicy(ctx, sponsorID)
+	if err != nil {
+		return err
+	}
+	_, err = applyRecordEndorsementPolicy(ctx, recordID, policy, creatorMSP)
+	return err
+}
+
+func applyRecordEndorsementPolicy(ctx contractapi.TransactionContextInterface, recordID string, policy *SponsorPolicy, creatorMSP string) ([]string, error) {
+	roleType := statebased.RoleTypePeer
+	orgs := []string{}
+	if creatorMSP != "" {
+		orgs = addUnique(orgs, creatorMSP)
+	}
+	if policy != nil {
+		roleType = statebased.RoleType(policy.RoleType)
+		orgs = addUnique(orgs, policy.SponsorMSP)
+		for _, mspID := range policy.AdditionalMSPs {
+			orgs = addUnique(orgs, mspID)
+		}
+	}
+	if len(orgs) == 0 {
+		return orgs, nil
+	}
+	sort.Strings(orgs)
+
+	endorsementPolicy, err := statebased.NewStateEP(nil)
+	if err != nil {
+		return nil, err
+	}
+	err = endorsementPolicy.AddOrgs(roleType, orgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build endorsement policy: %v", err)
+	}
+	policyBytes, err := endorsementPolicy.Policy()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build endorsement policy: %v", err)
+	}
+
+	err = ctx.GetStub().SetStateValidationParameter(recordID, policyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set endorsement policy of %s: %v", recordID, err)
+	}
+	return orgs, nil
+}
+
+// checkSponsorPolicyAccess allows admins, and the sponsor's own organization once a policy exists
+func checkSponsorPolicyAccess(ctx contractapi.TransactionContextInterface, sponsorID string, policy *SponsorPolicy) error {
+	isAdmin, err := callerHasRole(ctx, RoleAdmin)
+	if err != nil {
+		return err
+	}
+	if isAdmin {
+		return nil
+	}
+
+	callerMSP, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to read client MSP ID: %v", err)
+	}
+	if policy != nil && policy.SponsorMSP == callerMSP {
+		return nil
+	}
+	return fmt.Errorf("authorization denied: %s may not manage the endorsement policy of sponsor %s", callerMSP, sponsorID)
+}
+
+func getSponsorPolicy(ctx contractapi.TransactionContextInterface, sponsorID string) (*SponsorPolicy, error) {
+	policyAsBytes, err := ctx.GetStub().GetState(sponsorPolicyKey(sponsorID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if len(policyAsBytes) == 0 {
+		return nil, nil
+	}
+
+	var policy SponsorPolicy
+	err = json.Unmarshal(policyAsBytes, &policy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sponsor policy for %s: %v", sponsorID, err)
+	}
+	return &policy, nil
+}
+
+func sponsorPolicyKey(sponsorID string) string {
+	return fmt.Sprintf("SPONSOR_POLICY_%s", sponsorID)
+}
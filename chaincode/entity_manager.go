@@ -0,0 +1,763 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// EntityStatusInactive is the status of entities no longer allowed on new proof records
+const EntityStatusInactive = "inactive"
+
+// Sponsor represents a sponsor registered on the ledger
+type Sponsor struct {
+	SponsorID string `json:"sponsor_id"`
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	CreatedAt string `json:"createdAt"`
+	CreatedBy string `json:"createdBy"`
+	UpdatedAt string `json:"updatedAt"`
+	UpdatedBy string `json:"updatedBy"`
+	DocType   string `json:"docType"`
+}
+
+// Collector represents a collector registered on the ledger. The collector name is
+// personal data and goes to the private data collection when it is a private field.
+type Collector struct {
+	CollectorID   string                `json:"collectorId"`
+	CollectorName string                `json:"collector_name,omitempty"`
+	SponsorID     string                `json:"sponsor_id,omitempty"`
+	Status        string                `json:"status"`
+	OwnerMSP      string                `json:"ownerMSP"`
+	DelegatedMSPs []string              `json:"delegatedMSPs"`
+	PrivateData   *PrivateDataReference `json:"privateData,omitempty"`
+	Erasure       *PersonalDataErasure  `json:"personalDataErased,omitempty"`
+	CreatedAt     string                `json:"createdAt"`
+	CreatedBy     string                `json:"createdBy"`
+	UpdatedAt     string                `json:"updatedAt"`
+	UpdatedBy     string                `json:"updatedBy"`
+	DocType       string                `json:"docType"`
+}
+
+// Bulk represents a bulk of collected material registered on the ledger
+type Bulk struct {
+	BulkShortID string `json:"bulk_short_id"`
+	BulkName    string `json:"bulk_name"`
+	SponsorID   string `json:"sponsor_id"`
+	Status      string `json:"status"`
+	CreatedAt   string `json:"createdAt"`
+	CreatedBy   string `json:"createdBy"`
+	UpdatedAt   string `json:"updatedAt"`
+	UpdatedBy   string `json:"updatedBy"`
+	DocType     string `json:"docType"`
+}
+
+// EntityManager handles the sponsors, collectors and bulks registered on the ledger
+type EntityManager struct{}
+
+// NewEntityManager creates a new EntityManager instance
+func NewEntityManager() *EntityManager {
+	return &EntityManager{}
+}
+
+// CreateSponsor registers a sponsor
+func (em *EntityManager) CreateSponsor(ctx contractapi.TransactionContextInterface, sponsorData string) (string, error) {
+	var sponsor Sponsor
+	err := json.Unmarshal([]byte(sponsorData), &sponsor)
+	if err != nil {
+		return "", fmt.Errorf("invalid sponsor: %v", err)
+	}
+	if sponsor.SponsorID == "" || sponsor.Name == "" {
+		return "", fmt.Errorf("invalid sponsor: sponsor_id and name are required")
+	}
+
+	existing, err := getSponsor(ctx, sponsor.SponsorID)
+	if err != nil {
+		return "", err
+	}
+	if existing != nil {
+		return "", fmt.Errorf("Sponsor %s already exists", sponsor.SponsorID)
+	}
+
+	creator, err := getCreatorInfo(ctx)
+	if err != nil {
+		return "", err
+	}
+	sponsor.CreatedAt = getTxTimestamp(ctx)
+	sponsor.CreatedBy = creator.ID
+	return em.putSponsor(ctx, &sponsor, creator.ID)
+}
+
+// UpdateSponsor updates the name and status of a sponsor
+func (em *EntityManager) UpdateSponsor(ctx contractapi.TransactionContextInterface, sponsorID string, sponsorData string) (string, error) {
+	existing, err := getSponsor(ctx, sponsorID)
+	if err != nil {
+		return "", err
+	}
+	if existing == nil {
+		return "", fmt.Errorf("Sponsor %s does not exist", sponsorID)
+	}
+
+	sponsor := *existing
+	err = json.Unmarshal([]byte(sponsorData), &sponsor)
+	if err != nil {
+		return "", fmt.Errorf("invalid sponsor: %v", err)
+	}
+	if sponsor.SponsorID != sponsorID {
+		return "", fmt.Errorf("invalid sponsor: sponsor_id cannot be changed")
+	}
+	sponsor.CreatedAt, sponsor.CreatedBy = existing.CreatedAt, existing.CreatedBy
+
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return "", fmt.Errorf("failed to read client identity: %v", err)
+	}
+	return em.putSponsor(ctx, &sponsor, clientID)
+}
+
+// QuerySponsor queries a sponsor by ID
+func (em *EntityManager) QuerySponsor(ctx contractapi.TransactionContextInterface, sponsorID string) (string, error) {
+	sponsor, err := getSponsor(ctx, sponsorID)
+	if err != nil {
+		return "", err
+	}
+	if sponsor == nil {
+		return "", fmt.Errorf("Sponsor %s does not exist", sponsorID)
+	}
+	if err := checkSponsorAccess(ctx, sponsorID); err != nil {
+		return "", fmt.Errorf("Sponsor %s does not exist", sponsorID)
+	}
+
+	sponsorJSON, err := json.Marshal(sponsor)
+	if err != nil {
+		return "", err
+	}
+	return string(sponsorJSON), nil
+}
+
+// QueryAllSponsors queries the sponsors visible to the caller
+func (em *EntityManager) QueryAllSponsors(ctx contractapi.TransactionContextInterface) (string, error) {
+	return queryEntities(ctx, EntityTypeSponsor)
+}
+
+// DeleteSponsor removes a sponsor no proof record, ticket, collector or bulk refers to
+func (em *EntityManager) DeleteSponsor(ctx contractapi.TransactionContextInterface, sponsorID string) (string, error) {
+	sponsor, err := getSponsor(ctx, sponsorID)
+	if err != nil {
+		return "", err
+	}
+	if sponsor == nil {
+		return "", fmt.Errorf("Sponsor %s does not exist", sponsorID)
+	}
+
+	err = checkUnreferenced(ctx, fmt.Sprintf("Sponsor %s", sponsorID), map[string]interface{}{
+		"docType": map[string]interface{}{
+			"$in": []string{"proofRecord", "ticket", EntityTypeCollector, EntityTypeBulk},
+		},
+		"sponsor_id": sponsorID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	err = ctx.GetStub().DelState(entityKey(EntityTypeSponsor, sponsorID))
+	if err != nil {
+		return "", fmt.Errorf("failed to delete sponsor: %v", err)
+	}
+	sponsorJSON, _ := json.Marshal(sponsor)
+	return string(sponsorJSON), nil
+}
+
+// CreateCollector registers a collector. Collector names are unique, so proof records
+// naming the collector resolve to a single collector.
+func (em *EntityManager) CreateCollector(ctx contractapi.TransactionContextInterface, collectorData string) (string, error) {
+	var collector Collector
+	err := json.Unmarshal([]byte(collectorData), &collector)
+	if err != nil {
+		return "", fmt.Errorf("invalid collector: %v", err)
+	}
+	if collector.CollectorID == "" || collector.CollectorName == "" || collector.SponsorID == "" {
+		return "", fmt.Errorf("invalid collector: collectorId, collector_name and sponsor_id are required")
+	}
+
+	existing, err := getCollector(ctx, collector.CollectorID)
+	if err != nil {
+		return "", err
+	}
+	if existing != nil {
+		return "", fmt.Errorf("Collector %s already exists", collector.CollectorID)
+	}
+	if err := em.checkCollectorSponsor(ctx, collector.SponsorID); err != nil {
+		return "", err
+	}
+
+	creator, err := getCreatorInfo(ctx)
+	if err != nil {
+		return "", err
+	}
+	collector.OwnerMSP = creator.MSPID
+	collector.DelegatedMSPs = []string{}
+	collector.CreatedAt = getTxTimestamp(ctx)
+	collector.CreatedBy = creator.ID
+	return em.putCollector(ctx, &collector, creator.ID)
+}
+
+// UpdateCollector updates the name and status of a collector
+func (em *EntityManager) UpdateCollector(ctx contractapi.TransactionContextInterface, collectorID string, collectorData string) (string, error) {
+	existing, err := getCollector(ctx, collectorID)
+	if err != nil {
+		return "", err
+	}
+	if existing == nil {
+		return "", fmt.Errorf("Collector %s does not exist", collectorID)
+	}
+
+	collector := *existing
+	err = json.Unmarshal([]byte(collectorData), &collector)
+	if err != nil {
+		return "", fmt.Errorf("invalid collector: %v", err)
+	}
+	if collector.CollectorID != collectorID || collector.SponsorID != existing.SponsorID {
+		return "", fmt.Errorf("invalid collector: collectorId and sponsor_id cannot be changed")
+	}
+	collector.OwnerMSP, collector.DelegatedMSPs = existing.OwnerMSP, existing.DelegatedMSPs
+	collector.PrivateData, collector.Erasure = existing.PrivateData, existing.Erasure
+	collector.CreatedAt, collector.CreatedBy = existing.CreatedAt, existing.CreatedBy
+
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return "", fmt.Errorf("failed to read client identity: %v", err)
+	}
+	return em.putCollector(ctx, &collector, clientID)
+}
+
+// QueryCollector queries a collector by ID
+func (em *EntityManager) QueryCollector(ctx contractapi.TransactionContextInterface, collectorID string) (string, error) {
+	collectorAsBytes, err := ctx.GetStub().GetState(entityKey(EntityTypeCollector, collectorID))
+	if err != nil {
+		return "", fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if len(collectorAsBytes) == 0 {
+		return "", fmt.Errorf("Collector %s does not exist", collectorID)
+	}
+
+	var collector map[string]interface{}
+	err = json.Unmarshal(collectorAsBytes, &collector)
+	if err != nil {
+		return "", fmt.Errorf("invalid collector %s: %v", collectorID, err)
+	}
+
+	access, err := getTenantAccess(ctx)
+	if err != nil {
+		return "", err
+	}
+	if !access.CanSeeDocument(collector) {
+		return "", fmt.Errorf("Collector %s does not exist", collectorID)
+	}
+
+	privacyConfig, err := loadPrivacyConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+	err = mergeDocumentPrivateDetails(ctx, privacyConfig, collector)
+	if err != nil {
+		return "", err
+	}
+
+	view, err := getRedactionView(ctx)
+	if err != nil {
+		return "", err
+	}
+	view.redactDocument(collector)
+
+	collectorJSON, err := json.Marshal(collector)
+	if err != nil {
+		return "", err
+	}
+	return string(collectorJSON), nil
+}
+
+// QueryAllCollectors queries the collectors visible to the caller
+func (em *EntityManager) QueryAllCollectors(ctx contractapi.TransactionContextInterface) (string, error) {
+	return queryEntities(ctx, EntityTypeCollector)
+}
+
+// DeleteCollector removes a collector no proof record refers to, together with its private details
+func (em *EntityManager) DeleteCollector(ctx contractapi.TransactionContextInterface, collectorID string) (string, error) {
+	collector, err := getCollector(ctx, collectorID)
+	if err != nil {
+		return "", err
+	}
+	if collector == nil {
+		return "", fmt.Errorf("Collector %s does not exist", collectorID)
+	}
+
+	err = checkUnreferenced(ctx, fmt.Sprintf("Collector %s", collectorID), map[string]interface{}{
+		"docType":      "proofRecord",
+		"collector_id": collectorID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if collector.PrivateData != nil {
+		err = ctx.GetStub().DelPrivateData(collector.PrivateData.Collection, collector.PrivateData.Key)
+		if err != nil {
+			return "", fmt.Errorf("failed to delete private details %s: %v", collector.PrivateData.Key, err)
+		}
+	}
+	err = ctx.GetStub().DelState(entityKey(EntityTypeCollector, collectorID))
+	if err != nil {
+		return "", fmt.Errorf("failed to delete collector: %v", err)
+	}
+	collectorJSON, _ := json.Marshal(collector)
+	return string(collectorJSON), nil
+}
+
+// CreateBulk registers a bulk of a registered sponsor
+func (em *EntityManager) CreateBulk(ctx contractapi.TransactionContextInterface, bulkData string) (string, error) {
+	var bulk Bulk
+	err := json.Unmarshal([]byte(bulkData), &bulk)
+	if err != nil {
+		return "", fmt.Errorf("invalid bulk: %v", err)
+	}
+	if bulk.BulkShortID == "" || bulk.BulkName == "" || bulk.SponsorID == "" {
+		return "", fmt.Errorf("invalid bulk: bulk_short_id, bulk_name and sponsor_id are required")
+	}
+	if err := checkSponsorAccess(ctx, bulk.SponsorID); err != nil {
+		return "", err
+	}
+
+	existing, err := getBulk(ctx, bulk.BulkShortID)
+	if err != nil {
+		return "", err
+	}
+	if existing != nil {
+		return "", fmt.Errorf("Bulk %s already exists", bulk.BulkShortID)
+	}
+	sponsor, err := getSponsor(ctx, bulk.SponsorID)
+	if err != nil {
+		return "", err
+	}
+	if sponsor == nil {
+		return "", fmt.Errorf("Sponsor %s does not exist", bulk.SponsorID)
+	}
+
+	creator, err := getCreatorInfo(ctx)
+	if err != nil {
+		return "", err
+	}
+	bulk.CreatedAt = getTxTimestamp(ctx)
+	bulk.CreatedBy = creator.ID
+	return em.putBulk(ctx, &bulk, creator.ID)
+}
+
+// UpdateBulk updates the name and status of a bulk
+func (em *EntityManager) UpdateBulk(ctx contractapi.TransactionContextInterface, bulkShortID string, bulkData string) (string, error) {
+	existing, err := getBulk(ctx, bulkShortID)
+	if err != nil {
+		return "", err
+	}
+	if existing == nil {
+		return "", fmt.Errorf("Bulk %s does not exist", bulkShortID)
+	}
+	if err := checkSponsorAccess(ctx, existing.SponsorID); err != nil {
+		return "", err
+	}
+
+	bulk := *existing
+	err = json.Unmarshal([]byte(bulkData), &bulk)
+	if err != nil {
+		return "", fmt.Errorf("invalid bulk: %v", err)
+	}
+	if bulk.BulkShortID != bulkShortID || bulk.SponsorID != existing.SponsorID {
+		return "", fmt.Errorf("invalid bulk: bulk_short_id and sponsor_id cannot be changed")
+	}
+	bulk.CreatedAt, bulk.CreatedBy = existing.CreatedAt, existing.CreatedBy
+
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return "", fmt.Errorf("failed to read client identity: %v", err)
+	}
+	return em.putBulk(ctx, &bulk, clientID)
+}
+
+// QueryBulk queries a bulk by short ID
+func (em *EntityManager) QueryBulk(ctx contractapi.TransactionContextInterface, bulkShortID string) (string, error) {
+	bulk, err := getBulk(ctx, bulkShortID)
+	if err != nil {
+		return "", err
+	}
+	if bulk == nil {
+		return "", fmt.Errorf("Bulk %s does not exist", bulkShortID)
+	}
+	if err := checkSponsorAccess(ctx, bulk.SponsorID); err != nil {
+		return "", fmt.Errorf("Bulk %s does not exist", bulkShortID)
+	}
+
+	bulkJSON, err := json.Marshal(bulk)
+	if err != nil {
+		return "", err
+	}
+	return string(bulkJSON), nil
+}
+
+// QueryAllBulks queries the bulks visible to the caller
+func (em *EntityManager) QueryAllBulks(ctx contractapi.TransactionContextInterface) (string, error) {
+	return queryEntities(ctx, EntityTypeBulk)
+}
+
+// DeleteBulk removes a bulk no proof record refers to
+func (em *EntityManager) DeleteBulk(ctx contractapi.TransactionContextInterface, bulkShortID string) (string, error) {
+	bulk, err := getBulk(ctx, bulkShortID)
+	if err != nil {
+		return "", err
+	}
+	if bulk == nil {
+		return "", fmt.Errorf("Bulk %s does not exist", bulkShortID)
+	}
+
+	err = checkUnreferenced(ctx, fmt.Sprintf("Bulk %s", bulkShortID), map[string]interface{}{
+		"docType":       "proofRecord",
+		"bulk_short_id": bulkShortID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	err = ctx.GetStub().DelState(entityKey(EntityTypeBulk, bulkShortID))
+	if err != nil {
+		return "", fmt.Errorf("failed to delete bulk: %v", err)
+	}
+	bulkJSON, _ := json.Marshal(bulk)
+	return string(bulkJSON), nil
+}
+
+func (em *EntityManager) putSponsor(ctx contractapi.TransactionContextInterface, sponsor *Sponsor, updatedBy string) (string, error) {
+	status, err := entityStatus(sponsor.Status)
+	if err != nil {
+		return "", fmt.Errorf("invalid sponsor: %v", err)
+	}
+	sponsor.Status = status
+	sponsor.UpdatedAt = getTxTimestamp(ctx)
+	sponsor.UpdatedBy = updatedBy
+	sponsor.DocType = EntityTypeSponsor
+
+	return putEntity(ctx, entityKey(EntityTypeSponsor, sponsor.SponsorID), sponsor)
+}
+
+// putCollector stores a collector, moving a new collector name to the private data
+// collection when collector_name is a private field
+func (em *EntityManager) putCollector(ctx contractapi.TransactionContextInterface, collector *Collector, updatedBy string) (string, error) {
+	status, err := entityStatus(collector.Status)
+	if err != nil {
+		return "", fmt.Errorf("invalid collector: %v", err)
+	}
+	collector.Status = status
+	collector.UpdatedAt = getTxTimestamp(ctx)
+	collector.UpdatedBy = updatedBy
+	collector.DocType = EntityTypeCollector
+
+	key := entityKey(EntityTypeCollector, collector.CollectorID)
+	if collector.CollectorName != "" {
+		namesake, err := findCollectorByName(ctx, collector.SponsorID, collector.CollectorName)
+		if err != nil {
+			return "", err
+		}
+		// Collector names are personal data and stay out of the error messages
+		if namesake != nil && namesake.CollectorID != collector.CollectorID {
+			return "", fmt.Errorf("Collector name is already registered for sponsor %s", collector.SponsorID)
+		}
+
+		privacyConfig, err := loadPrivacyConfig(ctx)
+		if err != nil {
+			return "", err
+		}
+		if isPrivateField(privacyConfig.PrivateFields, "collector_name") {
+			reference, err := putPrivateDetails(ctx, privacyConfig, key, "collectorPrivate", map[string]interface{}{
+				"collector_name": collector.CollectorName,
+			})
+			if err != nil {
+				return "", err
+			}
+			collector.PrivateData = reference
+			collector.CollectorName = ""
+		}
+	}
+
+	return putEntity(ctx, key, collector)
+}
+
+// checkCollectorSponsor checks the sponsor a collector works for
+func (em *EntityManager) checkCollectorSponsor(ctx contractapi.TransactionContextInterface, sponsorID string) error {
+	if err := checkSponsorAccess(ctx, sponsorID); err != nil {
+		return err
+	}
+	sponsor, err := getSponsor(ctx, sponsorID)
+	if err != nil {
+		return err
+	}
+	if sponsor == nil {
+		return fmt.Errorf("Sponsor %s does not exist", sponsorID)
+	}
+	return nil
+}
+
+func (em *EntityManager) putBulk(ctx contractapi.TransactionContextInterface, bulk *Bulk, updatedBy string) (string, error) {
+	status, err := entityStatus(bulk.Status)
+	if err != nil {
+		return "", fmt.Errorf("invalid bulk: %v", err)
+	}
+	bulk.Status = status
+	bulk.UpdatedAt = getTxTimestamp(ctx)
+	bulk.UpdatedBy = updatedBy
+	bulk.DocType = EntityTypeBulk
+
+	return putEntity(ctx, entityKey(EntityTypeBulk, bulk.BulkShortID), bulk)
+}
+
+// entityStatus validates an entity status, new entities being active
+func entityStatus(status string) (string, error) {
+	switch status {
+	case "":
+		return EntityStatusActive, nil
+	case EntityStatusActive, EntityStatusInactive:
+		return status, nil
+	}
+	return "", fmt.Errorf("status must be %s or %s", EntityStatusActive, EntityStatusInactive)
+}
+
+func putEntity(ctx contractapi.TransactionContextInterface, key string, entity interface{}) (string, error) {
+	entityJSON, err := json.Marshal(entity)
+	if err != nil {
+		return "", err
+	}
+
+	err = ctx.GetStub().PutState(key, entityJSON)
+	if err != nil {
+		return "", fmt.Errorf("failed to put %s to world state: %v", key, err)
+	}
+	return string(entityJSON), nil
+}
+
+// queryEntities queries the entities of a type visible to the caller
+func queryEntities(ctx contractapi.TransactionContextInterface, entityType string) (string, error) {
+	access, err := getTenantAccess(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	selector := map[string]interface{}{
+		"docType": entityType,
+	}
+	access.restrictSelector(selector)
+
+	results, err := queryDocuments(ctx, selector)
+	if err != nil {
+		return "", err
+	}
+	if results == nil {
+		results = []map[string]interface{}{}
+	}
+
+	err = mergePrivateDetails(ctx, results)
+	if err != nil {
+		return "", err
+	}
+
+	view, err := getRedactionView(ctx)
+	if err != nil {
+		return "", err
+	}
+	view.redactResults(results)
+
+	resultsJSON, err := json.Marshal(results)
+	if err != nil {
+		return "", err
+	}
+	return string(resultsJSON), nil
+}
+
+// checkUnreferenced returns an error when documents matching the selector still refer to an entity
+func checkUnreferenced(ctx contractapi.TransactionContextInterface, name string, selector map[string]interface{}) error {
+	references, err := queryDocuments(ctx, selector)
+	if err != nil {
+		return err
+	}
+	if len(references) > 0 {
+		return fmt.Errorf("%s is still referenced by %d documents", name, len(references))
+	}
+	return nil
+}
+
+func getSponsor(ctx contractapi.TransactionContextInterface, sponsorID string) (*Sponsor, error) {
+	var sponsor Sponsor
+	found, err := getEntity(ctx, EntityTypeSponsor, sponsorID, &sponsor)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &sponsor, nil
+}
+
+func getCollector(ctx contractapi.TransactionContextInterface, collectorID string) (*Collector, error) {
+	var collector Collector
+	found, err := getEntity(ctx, EntityTypeCollector, collectorID, &collector)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &collector, nil
+}
+
+func getBulk(ctx contractapi.TransactionContextInterface, bulkShortID string) (*Bulk, error) {
+	var bulk Bulk
+	found, err := getEntity(ctx, EntityTypeBulk, bulkShortID, &bulk)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &bulk, nil
+}
+
+func getEntity(ctx contractapi.TransactionContextInterface, entityType string, entityID string, entity interface{}) (bool, error) {
+	entityAsBytes, err := ctx.GetStub().GetState(entityKey(entityType, entityID))
+	if err != nil {
+		return false, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if len(entityAsBytes) == 0 {
+		return false, nil
+	}
+
+	err = json.Unmarshal(entityAsBytes, entity)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s %s: %v", entityType, entityID, err)
+	}
+	return true, nil
+}
+
+// findCollectorByName returns the collector of a sponsor registered under a name, looking the
+// name up in the private data collection when collector_name is a private field
+func findCollectorByName(ctx contractapi.TransactionContextInterface, sponsorID string, name string) (*Collector, error) {
+	privacyConfig, err := loadPrivacyConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []string{}
+	if isPrivateField(privacyConfig.PrivateFields, "collector_name") {
+		keys, err = queryKeysByPrivateField(ctx, "collectorPrivate", "collector_name", name)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		results, err := queryDocuments(ctx, map[string]interface{}{
+			"docType":        EntityTypeCollector,
+			"collector_name": name,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, result := range results {
+			keys = append(keys, fmt.Sprint(result["Key"]))
+		}
+	}
+
+	prefix := entityKey(EntityTypeCollector, "")
+	for _, key := range keys {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		collector, err := getCollector(ctx, strings.TrimPrefix(key, prefix))
+		if err != nil {
+			return nil, err
+		}
+		if collector != nil && collector.SponsorID == sponsorID {
+			return collector, nil
+		}
+	}
+	return nil, nil
+}
+
+func entityKey(entityType string, entityID string) string {
+	return fmt.Sprintf("ENTITY_%s_%s", strings.ToUpper(entityType), entityID)
+}
+
+// referenceResolver adds the sponsor, collector and bulk documents a proof record refers
+// to under its references field, reading every entity once per transaction
+type referenceResolver struct {
+	ctx      contractapi.TransactionContextInterface
+	entities map[string]map[string]interface{}
+}
+
+func newReferenceResolver(ctx contractapi.TransactionContextInterface) *referenceResolver {
+	return &referenceResolver{ctx: ctx, entities: map[string]map[string]interface{}{}}
+}
+
+// resolve adds the references of a proof record. Records created before collectors were
+// registered on the ledger have no collector_id and only resolve their sponsor and bulk.
+func (rr *referenceResolver) resolve(record map[string]interface{}) error {
+	if record["docType"] != "proofRecord" {
+		return nil
+	}
+
+	references := map[string]interface{}{}
+	fields := []struct {
+		entityType string
+		field      string
+	}{
+		{EntityTypeSponsor, "sponsor_id"},
+		{EntityTypeCollector, "collector_id"},
+		{EntityTypeBulk, "bulk_short_id"},
+	}
+	for _, field := range fields {
+		entityID, ok := record[field.field].(string)
+		if !ok || entityID == "" {
+			continue
+		}
+		entity, err := rr.lookup(entityKey(field.entityType, entityID))
+		if err != nil {
+			return err
+		}
+		if entity != nil {
+			references[field.entityType] = entity
+		}
+	}
+
+	if len(references) > 0 {
+		record["references"] = references
+	}
+	return nil
+}
+
+// resolveResults adds the references of the proof records of query results
+func (rr *referenceResolver) resolveResults(results []map[string]interface{}) error {
+	for _, result := range results {
+		if record, ok := result["Record"].(map[string]interface{}); ok {
+			if err := rr.resolve(record); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (rr *referenceResolver) lookup(key string) (map[string]interface{}, error) {
+	if entity, cached := rr.entities[key]; cached {
+		return entity, nil
+	}
+
+	entityAsBytes, err := rr.ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+
+	var entity map[string]interface{}
+	if len(entityAsBytes) > 0 {
+		err = json.Unmarshal(entityAsBytes, &entity)
+		if err != nil {
+			return nil, fmt.Errorf("invalid entity %s: %v", key, err)
+		}
+	}
+	rr.entities[key] = entity
+	return entity, nil
+}
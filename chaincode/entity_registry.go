@@ -0,0 +1,196 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Entity types looked up in the registry
+const (
+	EntityTypeSponsor   = "sponsor"
+	EntityTypeCollector = "collector"
+	EntityTypeBulk      = "bulk"
+)
+
+// EntityStatusActive is the registry status of entities allowed on proof records
+const EntityStatusActive = "active"
+
+// RegistryEntity represents a sponsor, collector or bulk registered in the registry. SponsorID
+// is set for collectors and bulks belonging to a sponsor.
+type RegistryEntity struct {
+	Type      string `json:"type"`
+	ID        string `json:"id"`
+	Status    string `json:"status"`
+	SponsorID string `json:"sponsor_id,omitempty"`
+}
+
+// EntityRegistry looks up sponsors, collectors and bulks. LookupEntity returns nil for unknown entities.
+type EntityRegistry interface {
+	LookupEntity(ctx contractapi.TransactionContextInterface, entityType string, entityID string) (*RegistryEntity, error)
+}
+
+// ChaincodeRegistry looks entities up in a registry chaincode through InvokeChaincode. The
+// registry function is called with the entity type and ID and answers with the entity as
+// JSON, or with status 404 for unknown entities.
+type ChaincodeRegistry struct {
+	ChaincodeName string
+	Channel       string
+	Function      string
+}
+
+// LookupEntity invokes the registry chaincode for an entity
+func (cr *ChaincodeRegistry) LookupEntity(ctx contractapi.TransactionContextInterface, entityType string, entityID string) (*RegistryEntity, error) {
+	args := [][]byte{[]byte(cr.Function), []byte(entityType), []byte(entityID)}
+	response := ctx.GetStub().InvokeChaincode(cr.ChaincodeName, args, cr.Channel)
+
+	if response.Status == 404 || (response.Status == 200 && len(response.Payload) == 0) {
+		return nil, nil
+	}
+	if response.Status != 200 {
+		return nil, fmt.Errorf("registry chaincode %s failed with status %d: %s", cr.ChaincodeName, response.Status, response.Message)
+	}
+
+	var entity RegistryEntity
+	err := json.Unmarshal(response.Payload, &entity)
+	if err != nil {
+		return nil, fmt.Errorf("invalid entity from registry chaincode %s: %v", cr.ChaincodeName, err)
+	}
+	return &entity, nil
+}
+
+// LedgerRegistry looks entities up in the sponsors, collectors and bulks registered on the
+// ledger. Collectors are looked up by name among the collectors of SponsorID, the entity ID
+// is the collector ID.
+type LedgerRegistry struct {
+	SponsorID string
+}
+
+// LookupEntity reads a registered sponsor, collector or bulk
+func (lr *LedgerRegistry) LookupEntity(ctx contractapi.TransactionContextInterface, entityType string, entityID string) (*RegistryEntity, error) {
+	switch entityType {
+	case EntityTypeSponsor:
+		sponsor, err := getSponsor(ctx, entityID)
+		if err != nil || sponsor == nil {
+			return nil, err
+		}
+		return &RegistryEntity{Type: entityType, ID: sponsor.SponsorID, Status: sponsor.Status}, nil
+	case EntityTypeCollector:
+		collector, err := findCollectorByName(ctx, lr.SponsorID, entityID)
+		if err != nil || collector == nil {
+			return nil, err
+		}
+		return &RegistryEntity{Type: entityType, ID: collector.CollectorID, Status: collector.Status, SponsorID: collector.SponsorID}, nil
+	case EntityTypeBulk:
+		bulk, err := getBulk(ctx, entityID)
+		if err != nil || bulk == nil {
+			return nil, err
+		}
+		return &RegistryEntity{Type: entityType, ID: bulk.BulkShortID, Status: bulk.Status, SponsorID: bulk.SponsorID}, nil
+	}
+	return nil, fmt.Errorf("unknown entity type %s", entityType)
+}
+
+// StaticRegistry is an in-memory stand-in for the registry chaincode, keyed by entity type and ID
+type StaticRegistry struct {
+	Entities map[string]map[string]RegistryEntity
+}
+
+// NewStaticRegistry creates an empty StaticRegistry
+func NewStaticRegistry() *StaticRegistry {
+	return &StaticRegistry{Entities: map[string]map[string]RegistryEntity{}}
+}
+
+// Register adds or replaces an entity
+func (sr *StaticRegistry) Register(entityType string, entityID string, status string) {
+	if _, exists := sr.Entities[entityType]; !exists {
+		sr.Entities[entityType] = map[string]RegistryEntity{}
+	}
+	sr.Entities[entityType][entityID] = RegistryEntity{Type: entityType, ID: entityID, Status: status}
+}
+
+// LookupEntity returns a registered entity
+func (sr *StaticRegistry) LookupEntity(ctx contractapi.TransactionContextInterface, entityType string, entityID string) (*RegistryEntity, error) {
+	entity, exists := sr.Entities[entityType][entityID]
+	if !exists {
+		return nil, nil
+	}
+	return &entity, nil
+}
+
+// newEntityRegistry builds the registry for a registry configuration and the sponsor of the
+// validated record. Tests replace it to run against a StaticRegistry instead of a deployed
+// registry chaincode.
+var newEntityRegistry = func(config *RegistryConfig, sponsorID string) EntityRegistry {
+	if config.Source == RegistrySourceLedger {
+		return &LedgerRegistry{SponsorID: sponsorID}
+	}
+	return &ChaincodeRegistry{
+		ChaincodeName: config.ChaincodeName,
+		Channel:       config.Channel,
+		Function:      config.Function,
+	}
+}
+
+// validateRegistryEntities rejects proof records whose sponsor, collector or bulk is unknown
+// to the registry, not active there or registered for another sponsor, when registry
+// validation is enabled. It returns the fields linking the record to the collector
+// registered on the ledger, which keep the collector name itself off the public record.
+func validateRegistryEntities(ctx contractapi.TransactionContextInterface, record map[string]interface{}) (map[string]interface{}, error) {
+	links := map[string]interface{}{}
+	config, err := loadRegistryConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !config.Enabled {
+		return links, nil
+	}
+
+	sponsorID := fmt.Sprint(record["sponsor_id"])
+	registry := newEntityRegistry(config, sponsorID)
+	checks := []struct {
+		enabled    bool
+		entityType string
+		field      string
+	}{
+		{config.ValidateSponsor, EntityTypeSponsor, "sponsor_id"},
+		{config.ValidateCollector, EntityTypeCollector, "collector_name"},
+		{config.ValidateBulk, EntityTypeBulk, "bulk_short_id"},
+	}
+
+	for _, check := range checks {
+		if !check.enabled {
+			continue
+		}
+		value, exists := record[check.field]
+		if !exists || value == nil || fmt.Sprint(value) == "" {
+			return nil, fmt.Errorf("%s is required by the registry validation", check.field)
+		}
+		entityID := fmt.Sprint(value)
+		entity, err := registry.LookupEntity(ctx, check.entityType, entityID)
+		if err != nil {
+			return nil, err
+		}
+
+		// Collector names are personal data and stay out of the error messages
+		name := fmt.Sprintf("%s %s", check.entityType, entityID)
+		if check.entityType == EntityTypeCollector {
+			name = check.entityType
+		}
+		if entity == nil {
+			return nil, fmt.Errorf("%s is not registered", name)
+		}
+		if entity.Status != EntityStatusActive {
+			return nil, fmt.Errorf("%s is %s in the registry", name, entity.Status)
+		}
+		if entity.SponsorID != "" && entity.SponsorID != sponsorID {
+			return nil, fmt.Errorf("%s is registered for sponsor %s", name, entity.SponsorID)
+		}
+
+		if check.entityType == EntityTypeCollector && config.Source == RegistrySourceLedger {
+			links["collector_id"] = entity.ID
+		}
+	}
+	return links, nil
+}
@@ -0,0 +1,151 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// useStaticRegistry enables registry validation against the given registry for the test
+func useStaticRegistry(t *testing.T, ctx *contractapi.TransactionContext, registry *StaticRegistry) {
+	t.Helper()
+	original := newEntityRegistry
+	newEntityRegistry = func(config *RegistryConfig, sponsorID string) EntityRegistry { return registry }
+	t.Cleanup(func() { newEntityRegistry = original })
+
+	_, err := NewConfigManager().SetRegistryConfig(ctx, `{"enabled":true,"validateBulk":true}`)
+	if err != nil {
+		t.Fatalf("SetRegistryConfig failed: %v", err)
+	}
+}
+
+func registryTestRecord() map[string]interface{} {
+	return map[string]interface{}{
+		"sponsor_id":     "S1",
+		"collector_name": "Alice",
+		"bulk_short_id":  "B-1",
+	}
+}
+
+func TestSetRegistryConfigKeepsDefaultValidation(t *testing.T) {
+	ctx := newTestContext(t)
+
+	configJSON, err := NewConfigManager().SetRegistryConfig(ctx, `{"enabled":true,"chaincodeName":"registry"}`)
+	if err != nil {
+		t.Fatalf("SetRegistryConfig failed: %v", err)
+	}
+	var config RegistryConfig
+	if err := json.Unmarshal([]byte(configJSON), &config); err != nil {
+		t.Fatalf("invalid config %s: %v", configJSON, err)
+	}
+	if !config.ValidateSponsor || !config.ValidateCollector {
+		t.Errorf("config left out of the call turned validation off: %s", configJSON)
+	}
+
+	_, err = NewConfigManager().SetRegistryConfig(ctx,
+		`{"enabled":true,"validateSponsor":false,"validateCollector":false,"validateBulk":false}`)
+	if err == nil {
+		t.Errorf("enabled config validating nothing was accepted")
+	}
+}
+
+func TestValidateRegistryEntities(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(registry *StaticRegistry)
+		record  func(record map[string]interface{})
+		wantErr string
+	}{
+		{
+			name:  "active entities",
+			setup: func(registry *StaticRegistry) {},
+		},
+		{
+			name:    "unknown sponsor",
+			setup:   func(registry *StaticRegistry) { delete(registry.Entities[EntityTypeSponsor], "S1") },
+			wantErr: "sponsor S1 is not registered",
+		},
+		{
+			name: "suspended collector",
+			setup: func(registry *StaticRegistry) {
+				registry.Register(EntityTypeCollector, "Alice", "suspended")
+			},
+			wantErr: "collector is suspended in the registry",
+		},
+		{
+			name: "unknown bulk",
+			setup: func(registry *StaticRegistry) {
+				delete(registry.Entities[EntityTypeBulk], "B-1")
+			},
+			wantErr: "bulk B-1 is not registered",
+		},
+		{
+			name:    "missing collector",
+			setup:   func(registry *StaticRegistry) {},
+			record:  func(record map[string]interface{}) { delete(record, "collector_name") },
+			wantErr: "collector_name is required",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := newTestContext(t)
+			registry := NewStaticRegistry()
+			registry.Register(EntityTypeSponsor, "S1", EntityStatusActive)
+			registry.Register(EntityTypeCollector, "Alice", EntityStatusActive)
+			registry.Register(EntityTypeBulk, "B-1", EntityStatusActive)
+			test.setup(registry)
+			useStaticRegistry(t, ctx, registry)
+
+			record := registryTestRecord()
+			if test.record != nil {
+				test.record(record)
+			}
+			_, err := validateRegistryEntities(ctx, record)
+			if test.wantErr == "" {
+				if err != nil {
+					t.Fatalf("validateRegistryEntities failed: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Fatalf("validateRegistryEntities error = %v, want %q", err, test.wantErr)
+			}
+			if strings.Contains(err.Error(), "Alice") {
+				t.Errorf("error exposes the collector name: %v", err)
+			}
+		})
+	}
+}
+
+func TestRegistryValidatesAgainstTheLedgerByDefault(t *testing.T) {
+	ctx := newTestContext(t)
+	_, err := validateRegistryEntities(ctx, registryTestRecord())
+	if err == nil || !strings.Contains(err.Error(), "sponsor S1 is not registered") {
+		t.Errorf("validation without registry config error = %v, want unregistered sponsor", err)
+	}
+}
+
+func TestLoadRegistryConfigKeepsStoredFields(t *testing.T) {
+	ctx := newTestContext(t)
+
+	// A config stored before bulk validation and the ledger registries existed
+	err := ctx.GetStub().PutState(RegistryConfigKey,
+		[]byte(`{"enabled":true,"chaincodeName":"registry","function":"GetEntity","validateSponsor":true,"validateCollector":false}`))
+	if err != nil {
+		t.Fatalf("PutState failed: %v", err)
+	}
+
+	config, err := loadRegistryConfig(ctx)
+	if err != nil {
+		t.Fatalf("loadRegistryConfig failed: %v", err)
+	}
+	if !config.ValidateSponsor || config.ValidateCollector || config.ValidateBulk {
+		t.Errorf("stored config picked up the new defaults: %+v", config)
+	}
+	if config.Source != RegistrySourceChaincode {
+		t.Errorf("source = %q, want %q", config.Source, RegistrySourceChaincode)
+	}
+}
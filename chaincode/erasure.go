@@ -0,0 +1,404 @@
+package chaincode
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Transient map entries read by the erasure transactions
+const (
+	TransientPseudonymKey     = "pseudonymKey"
+	TransientCollectorNameKey = "collector_name"
+)
+
+// PseudonymKeyCheckKey is the world state key of the fingerprint of the pseudonym key
+const PseudonymKeyCheckKey = "CONFIG_PSEUDONYM_KEY"
+
+// minPseudonymKeyLength is the minimum length in bytes of the pseudonym key
+const minPseudonymKeyLength = 32
+
+// PersonalDataErasure records the erasure of a document's personal data in its public part
+type PersonalDataErasure struct {
+	Pseudonym         string `json:"pseudonym"`
+	Reason            string `json:"reason"`
+	ErasedAt          string `json:"erasedAt"`
+	ErasedBy          string `json:"erasedBy"`
+	TxID              string `json:"txId"`
+	CommitmentCleared bool   `json:"commitmentCleared,omitempty"`
+}
+
+// PersonalDataErasedPayload is the payload of a PersonalDataErased event
+type PersonalDataErasedPayload struct {
+	Key       string `json:"key"`
+	DocType   string `json:"docType"`
+	Pseudonym string `json:"pseudonym"`
+	ErasedAt  string `json:"erasedAt"`
+}
+
+// CollectorErasureResponse represents the response of an EraseCollectorPersonalData call
+type CollectorErasureResponse struct {
+	Pseudonym      string          `json:"pseudonym"`
+	ErasedKeys     []string        `json:"erasedKeys"`
+	SkippedRecords []SkippedRecord `json:"skippedRecords"`
+}
+
+// pseudonymKeyCheck is the world state document holding the fingerprint of the pseudonym key
+type pseudonymKeyCheck struct {
+	Fingerprint string `json:"fingerprint"`
+	DocType     string `json:"docType"`
+}
+
+// erasableDocTypes are the document types carrying personal data
+var erasableDocTypes = []string{"proofRecord", "ticket", EntityTypeCollector}
+
+// ErasureManager handles right-to-erasure requests
+type ErasureManager struct{}
+
+// NewErasureManager creates a new ErasureManager instance
+func NewErasureManager() *ErasureManager {
+	return &ErasureManager{}
+}
+
+// ErasePersonalData removes the personal data of a proof record, ticket or collector. The private details
+// are deleted from the collection and the public document keeps a pseudonymous reference instead of their
+// hash. The commitment over the whole record, which a leaked salt would let anyone match against guessed
+// personal data, is cleared; the commitment over the public fields, the weights and increments are kept.
+func (em *ErasureManager) ErasePersonalData(ctx contractapi.TransactionContextInterface, key string, reason string) (string, error) {
+	logger.Println("============= START : Erase Personal Data ===========")
+
+	pseudonymKey, err := loadPseudonymKey(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	document, err := getDocument(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	docType, _ := document["docType"].(string)
+	if !isErasableDocType(docType) {
+		return "", fmt.Errorf("Document %s is not a proof record, ticket or collector", key)
+	}
+
+	access, err := getTenantAccess(ctx)
+	if err != nil {
+		return "", err
+	}
+	if !access.CanSeeDocument(document) {
+		return "", fmt.Errorf("Document %s does not exist", key)
+	}
+
+	if _, erased := document["personalDataErased"]; erased {
+		return "", fmt.Errorf("personal data of %s was already erased", key)
+	}
+
+	err = checkCanModify(ctx, key, document)
+	if err != nil {
+		return "", err
+	}
+
+	creator, err := getCreatorInfo(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	erasure, err := em.erase(ctx, key, document, pseudonymKey, reason, creator)
+	if err != nil {
+		return "", err
+	}
+
+	err = emitEvents(ctx, []EventEntry{{
+		Type: EventPersonalDataErased,
+		Payload: PersonalDataErasedPayload{
+			Key:       key,
+			DocType:   docType,
+			Pseudonym: erasure.Pseudonym,
+			ErasedAt:  erasure.ErasedAt,
+		},
+	}})
+	if err != nil {
+		return "", err
+	}
+
+	logger.Println("============= END : Erase Personal Data ===========")
+	documentJSON, _ := json.Marshal(document)
+	return string(documentJSON), nil
+}
+
+// EraseCollectorPersonalData erases the personal data of every proof record, ticket and registry entry
+// of a collector. The collector name is read from the transient map so it never reaches the ledger;
+// documents the caller's organization may not modify are skipped and reported.
+func (em *ErasureManager) EraseCollectorPersonalData(ctx contractapi.TransactionContextInterface, reason string) (string, error) {
+	logger.Println("============= START : Erase Collector Personal Data ===========")
+
+	pseudonymKey, err := loadPseudonymKey(ctx)
+	if err != nil {
+		return "", err
+	}
+	collectorName, err := readTransientPayload(ctx, TransientCollectorNameKey)
+	if err != nil {
+		return "", err
+	}
+
+	keys, err := collectorDocumentKeys(ctx, collectorName)
+	if err != nil {
+		return "", err
+	}
+
+	access, err := getTenantAccess(ctx)
+	if err != nil {
+		return "", err
+	}
+	creator, err := getCreatorInfo(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	response := CollectorErasureResponse{
+		Pseudonym:      collectorPseudonym(pseudonymKey, collectorName),
+		ErasedKeys:     []string{},
+		SkippedRecords: []SkippedRecord{},
+	}
+	events := []EventEntry{}
+	for _, key := range keys {
+		document, err := getDocument(ctx, key)
+		if err != nil {
+			return "", err
+		}
+		if _, erased := document["personalDataErased"]; erased || !access.CanSeeDocument(document) {
+			continue
+		}
+		if err := checkCanModify(ctx, key, document); err != nil {
+			response.SkippedRecords = append(response.SkippedRecords, SkippedRecord{RecordID: key, Reason: err.Error()})
+			continue
+		}
+
+		erasure, err := em.erase(ctx, key, document, pseudonymKey, reason, creator)
+		if err != nil {
+			return "", err
+		}
+		response.ErasedKeys = append(response.ErasedKeys, key)
+		events = append(events, EventEntry{
+			Type: EventPersonalDataErased,
+			Payload: PersonalDataErasedPayload{
+				Key:       key,
+				DocType:   fmt.Sprint(document["docType"]),
+				Pseudonym: erasure.Pseudonym,
+				ErasedAt:  erasure.ErasedAt,
+			},
+		})
+	}
+	// The collector name stays out of the error messages
+	if len(response.ErasedKeys) == 0 && len(response.SkippedRecords) == 0 {
+		return "", fmt.Errorf("Personal data of the collector does not exist")
+	}
+
+	err = emitEvents(ctx, events)
+	if err != nil {
+		return "", err
+	}
+
+	logger.Println("============= END : Erase Collector Personal Data ===========")
+	responseJSON, _ := json.Marshal(response)
+	return string(responseJSON), nil
+}
+
+// erase removes the personal data of a document the caller may modify and stores its erased public part
+func (em *ErasureManager) erase(ctx contractapi.TransactionContextInterface, key string, document map[string]interface{}, pseudonymKey []byte, reason string, creator *CreatorInfo) (*PersonalDataErasure, error) {
+	privacyConfig, err := loadPrivacyConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	privateFields := privacyConfig.PrivateFields
+	switch document["docType"] {
+	case "ticket":
+		privateFields = privacyConfig.TicketPrivateFields
+	case EntityTypeCollector:
+		privateFields = []string{"collector_name"}
+	}
+
+	// The collector name gives the pseudonym, so erasures of one collector share it
+	details, err := getPrivateDetails(ctx, document)
+	if err != nil {
+		return nil, err
+	}
+
+	// DelPrivateData removes the details from the current state of the collection, the blockToLive
+	// of the collection bounds how long peers keep them in their private data store
+	err = deletePrivateDetails(ctx, document)
+	if err != nil {
+		return nil, err
+	}
+
+	// Documents written before the private data collection carry the fields publicly. They are
+	// dropped from the current state, their history is beyond the reach of the chaincode.
+	legacyFields := splitPrivateFields(document, privateFields)
+	if _, hasPrivateData := document["privateData"]; !hasPrivateData && len(legacyFields) == 0 {
+		return nil, fmt.Errorf("Document %s has no personal data", key)
+	}
+	delete(document, "privateData")
+
+	pseudonym := documentPseudonym(pseudonymKey, key)
+	if collectorName, ok := details["collector_name"].(string); ok && collectorName != "" {
+		pseudonym = collectorPseudonym(pseudonymKey, collectorName)
+	} else if collectorName, ok := legacyFields["collector_name"].(string); ok && collectorName != "" {
+		pseudonym = collectorPseudonym(pseudonymKey, collectorName)
+	}
+
+	erasure := &PersonalDataErasure{
+		Pseudonym: pseudonym,
+		Reason:    reason,
+		ErasedAt:  getTxTimestamp(ctx),
+		ErasedBy:  creator.ID,
+		TxID:      ctx.GetStub().GetTxID(),
+	}
+	// The public commitment does not cover the personal fields and keeps the weights verifiable.
+	// Commitments created without one are cleared as a whole.
+	if commitment, committed := document["commitment"].(map[string]interface{}); committed {
+		if publicValue, _ := commitment["publicValue"].(string); publicValue != "" {
+			delete(commitment, "value")
+		} else {
+			delete(document, "commitment")
+		}
+		erasure.CommitmentCleared = true
+	}
+	document["personalDataErased"] = erasure
+
+	documentJSON, err := json.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.GetStub().PutState(key, documentJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put %s to world state: %v", key, err)
+	}
+	return erasure, nil
+}
+
+// collectorDocumentKeys returns the sorted keys of the documents holding the name of a collector,
+// in the private data collection or, for documents written before it, publicly
+func collectorDocumentKeys(ctx contractapi.TransactionContextInterface, collectorName string) ([]string, error) {
+	keys := []string{}
+	for _, docType := range erasableDocTypes {
+		privateKeys, err := queryKeysByPrivateField(ctx, docType+"Private", "collector_name", collectorName)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range privateKeys {
+			keys = addUnique(keys, key)
+		}
+
+		legacyDocuments, err := queryDocuments(ctx, map[string]interface{}{
+			"docType":        docType,
+			"collector_name": collectorName,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range legacyDocuments {
+			keys = addUnique(keys, item["Key"].(string))
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// getPrivateDetails returns the private details referenced by a public document. Documents without
+// details, and details the collection already purged, return none. Failing reads are returned rather
+// than giving the erasure a pseudonym of the document instead of the collector's.
+func getPrivateDetails(ctx contractapi.TransactionContextInterface, document map[string]interface{}) (map[string]interface{}, error) {
+	reference, ok := document["privateData"].(map[string]interface{})
+	if !ok {
+		return map[string]interface{}{}, nil
+	}
+
+	detailsAsBytes, err := ctx.GetStub().GetPrivateData(fmt.Sprint(reference["collection"]), fmt.Sprint(reference["key"]))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private details %s: %v", reference["key"], err)
+	}
+	if len(detailsAsBytes) == 0 {
+		return map[string]interface{}{}, nil
+	}
+
+	var details map[string]interface{}
+	err = json.Unmarshal(detailsAsBytes, &details)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private details %s: %v", reference["key"], err)
+	}
+	return details, nil
+}
+
+// loadPseudonymKey reads the secret pseudonym key from the transient map. The first erasure records
+// the fingerprint of the key, later erasures must use the same key so pseudonyms stay stable.
+func loadPseudonymKey(ctx contractapi.TransactionContextInterface) ([]byte, error) {
+	key, err := readTransientPayload(ctx, TransientPseudonymKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) < minPseudonymKeyLength {
+		return nil, fmt.Errorf("pseudonym key must be at least %d bytes", minPseudonymKeyLength)
+	}
+
+	fingerprint := sha256.Sum256([]byte(key))
+	check := pseudonymKeyCheck{
+		Fingerprint: hex.EncodeToString(fingerprint[:]),
+		DocType:     "pseudonymKey",
+	}
+
+	checkAsBytes, err := ctx.GetStub().GetState(PseudonymKeyCheckKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if len(checkAsBytes) > 0 {
+		var registered pseudonymKeyCheck
+		err = json.Unmarshal(checkAsBytes, &registered)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pseudonym key fingerprint in world state: %v", err)
+		}
+		if registered.Fingerprint != check.Fingerprint {
+			return nil, fmt.Errorf("pseudonym key does not match the key of earlier erasures")
+		}
+		return []byte(key), nil
+	}
+
+	checkJSON, err := json.Marshal(check)
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.GetStub().PutState(PseudonymKeyCheckKey, checkJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put pseudonym key fingerprint to world state: %v", err)
+	}
+	return []byte(key), nil
+}
+
+// collectorPseudonym returns the keyed pseudonym of a collector, identical for all of its documents
+func collectorPseudonym(pseudonymKey []byte, collectorName string) string {
+	return keyedHash(pseudonymKey, "collector:"+collectorName)
+}
+
+// documentPseudonym returns the keyed pseudonym of a document whose personal data names no collector
+func documentPseudonym(pseudonymKey []byte, key string) string {
+	return keyedHash(pseudonymKey, "document:"+key)
+}
+
+func keyedHash(key []byte, value string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func isErasableDocType(docType string) bool {
+	for _, erasable := range erasableDocTypes {
+		if erasable == docType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package chaincode
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+)
+
+// unreadablePrivateDataStub is a mock stub whose private data collection cannot be read
+type unreadablePrivateDataStub struct {
+	*shimtest.MockStub
+}
+
+func (us *unreadablePrivateDataStub) GetPrivateData(collection string, key string) ([]byte, error) {
+	return nil, errors.New("collection unavailable")
+}
+
+func TestGetPrivateDetailsReturnsReadErrors(t *testing.T) {
+	ctx := newTestContext(t)
+	document := map[string]interface{}{
+		"privateData": map[string]interface{}{"collection": PrivateCollectionName, "key": "R1"},
+	}
+
+	details, err := getPrivateDetails(ctx, document)
+	if err != nil || len(details) != 0 {
+		t.Errorf("details purged from the collection = %v, %v, want none", details, err)
+	}
+
+	ctx.SetStub(&unreadablePrivateDataStub{MockStub: ctx.GetStub().(*shimtest.MockStub)})
+	if _, err := getPrivateDetails(ctx, document); err == nil || !strings.Contains(err.Error(), "collection unavailable") {
+		t.Errorf("getPrivateDetails error = %v, want the read error", err)
+	}
+}
+
+func TestCommitmentKeepsPublicFieldsVerifiable(t *testing.T) {
+	ctx := newTestContext(t)
+	setTransient(ctx, map[string][]byte{TransientSaltKey: []byte("secret")})
+	record := map[string]interface{}{"sponsor_id": "S1", "collector_name": "Alice", "chained_weight": 10.0}
+
+	commitment, err := newCommitment(ctx, record, []string{"collector_name", "driver"})
+	if err != nil {
+		t.Fatalf("newCommitment failed: %v", err)
+	}
+	if len(commitment.PersonalFields) != 1 || commitment.PersonalFields[0] != "collector_name" {
+		t.Errorf("personal fields = %v, want the submitted collector_name only", commitment.PersonalFields)
+	}
+	public, _ := commitmentValue(map[string]interface{}{"sponsor_id": "S1", "chained_weight": 10.0}, "secret")
+	if commitment.PublicValue != public || commitment.Value == public {
+		t.Errorf("public commitment = %s, want %s over the public fields only", commitment.PublicValue, public)
+	}
+	if _, kept := record["collector_name"]; !kept {
+		t.Errorf("newCommitment removed the personal fields of the record")
+	}
+
+	setTransient(ctx, map[string][]byte{})
+	if commitment, err := newCommitment(ctx, record, nil); commitment != nil || err != nil {
+		t.Errorf("commitment without salt = %v, %v, want none", commitment, err)
+	}
+}
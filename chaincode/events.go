@@ -0,0 +1,116 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// EventSchemaVersion is the version of the chaincode event payload schema
+const EventSchemaVersion = "1.0"
+
+// Chaincode event types
+const (
+	EventProofRecordCreated      = "ProofRecordCreated"
+	EventTicketCreated           = "TicketCreated"
+	EventWeightViolationDetected = "WeightViolationDetected"
+	EventRecordsDeleted          = "RecordsDeleted"
+	EventOwnershipTransferred    = "OwnershipTransferred"
+	EventRecordsVoided           = "RecordsVoided"
+	EventViolationActionProposed = "ViolationActionProposed"
+	EventProposalApproved        = "ProposalApproved"
+	EventPersonalDataErased      = "PersonalDataErased"
+	EventCreditsIssued           = "CreditsIssued"
+	EventCreditsTransferred      = "CreditsTransferred"
+	EventCreditsRetired          = "CreditsRetired"
+	EventBatch                   = "EventBatch"
+)
+
+// EventEnvelope is the payload of every chaincode event. Fabric allows a single
+// event per transaction, so all entries raised by a transaction are batched in it.
+type EventEnvelope struct {
+	SchemaVersion string       `json:"schemaVersion"`
+	EventName     string       `json:"eventName"`
+	TxID          string       `json:"txId"`
+	Timestamp     string       `json:"timestamp"`
+	Events        []EventEntry `json:"events"`
+}
+
+// EventEntry represents a single event raised by a transaction
+type EventEntry struct {
+	Type    string      `json:"type"`
+	Payload interface{} `json:"payload"`
+}
+
+// ProofRecordCreatedPayload is the payload of a ProofRecordCreated event
+type ProofRecordCreatedPayload struct {
+	RecordID       string   `json:"recordId"`
+	SponsorID      string   `json:"sponsor_id"`
+	BulkShortID    string   `json:"bulk_short_id"`
+	ChainedWeight  float64  `json:"chained_weight"`
+	StoreIncrement *float64 `json:"store_increment"`
+	PressIncrement *float64 `json:"press_increment"`
+	OwnerMSP       string   `json:"ownerMSP"`
+	CreatedAt      string   `json:"createdAt"`
+}
+
+// TicketCreatedPayload is the payload of a TicketCreated event
+type TicketCreatedPayload struct {
+	TicketKey      string  `json:"ticketKey"`
+	TicketID       string  `json:"id"`
+	SponsorID      string  `json:"sponsor_id,omitempty"`
+	IncrementID    float64 `json:"incrementId"`
+	ReceivedWeight float64 `json:"receivedWeight"`
+	OwnerMSP       string  `json:"ownerMSP"`
+	CreatedAt      string  `json:"createdAt"`
+}
+
+// WeightViolationDetectedPayload is the payload of a WeightViolationDetected event
+type WeightViolationDetectedPayload struct {
+	SponsorID      string   `json:"sponsor_id,omitempty"`
+	IncrementField string   `json:"incrementField"`
+	IncrementID    int      `json:"incrementId"`
+	ChainedWeight  float64  `json:"chainedWeight"`
+	ReceivedWeight float64  `json:"receivedWeight"`
+	Aggregation    string   `json:"aggregation"`
+	RecordIDs      []string `json:"recordIds"`
+}
+
+// RecordsDeletedPayload is the payload of a RecordsDeleted event
+type RecordsDeletedPayload struct {
+	Reason    string   `json:"reason"`
+	RecordIDs []string `json:"recordIds"`
+}
+
+// emitEvents sets the chaincode event of the transaction. A single entry is
+// published under its own type, several entries under EventBatch.
+func emitEvents(ctx contractapi.TransactionContextInterface, entries []EventEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	eventName := EventBatch
+	if len(entries) == 1 {
+		eventName = entries[0].Type
+	}
+
+	envelope := EventEnvelope{
+		SchemaVersion: EventSchemaVersion,
+		EventName:     eventName,
+		TxID:          ctx.GetStub().GetTxID(),
+		Timestamp:     getTxTimestamp(ctx),
+		Events:        entries,
+	}
+
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %v", err)
+	}
+
+	err = ctx.GetStub().SetEvent(eventName, payload)
+	if err != nil {
+		return fmt.Errorf("failed to set event: %v", err)
+	}
+	return nil
+}
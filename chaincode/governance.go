@@ -0,0 +1,497 @@
+package chaincode
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Destructive actions a violation proposal can carry out
+const (
+	ProposalActionDelete = "delete"
+	ProposalActionVoid   = "void"
+)
+
+// Violation proposal statuses
+const (
+	ProposalStatusPending  = "pending"
+	ProposalStatusExecuted = "executed"
+	ProposalStatusStale    = "stale"
+)
+
+// RecordStatusVoided marks a proof record voided by an executed proposal
+const RecordStatusVoided = "voided"
+
+// ProposalRequest represents the data of a ProposeViolationAction call
+type ProposalRequest struct {
+	IncrementField string             `json:"incrementField"`
+	Action         string             `json:"action"`
+	Reason         string             `json:"reason"`
+	Options        *ComparisonOptions `json:"options"`
+}
+
+// ProposalApproval represents the approval of a proposal by an organization
+type ProposalApproval struct {
+	MSPID      string `json:"mspId"`
+	ApprovedBy string `json:"approvedBy"`
+	ApprovedAt string `json:"approvedAt"`
+}
+
+// ViolationProposal represents a planned destructive reconciliation awaiting approval
+type ViolationProposal struct {
+	ProposalID     string             `json:"proposalId"`
+	IncrementField string             `json:"incrementField"`
+	Action         string             `json:"action"`
+	Reason         string             `json:"reason"`
+	Options        *ComparisonOptions `json:"options"`
+	SponsorScope   *TenantAccess      `json:"sponsorScope"`
+	Results        []ComparisonResult `json:"results"`
+	Rejections     []RecordRejection  `json:"rejections"`
+	Fingerprints   map[string]string  `json:"fingerprints"`
+	Quorum         int                `json:"quorum"`
+	Approvals      []ProposalApproval `json:"approvals"`
+	Status         string             `json:"status"`
+	AffectedKeys   []string           `json:"affectedKeys"`
+	SkippedRecords []SkippedRecord    `json:"skippedRecords"`
+	StaleKeys      []string           `json:"staleKeys,omitempty"`
+	StaleReason    string             `json:"staleReason,omitempty"`
+	ProposedBy     string             `json:"proposedBy"`
+	ProposedMSP    string             `json:"proposedMSP"`
+	CreatedAt      string             `json:"createdAt"`
+	ClosedAt       string             `json:"closedAt,omitempty"`
+	DocType        string             `json:"docType"`
+}
+
+// ProposalResponse represents the response of a proposal operation
+type ProposalResponse struct {
+	Success  bool               `json:"success"`
+	Message  string             `json:"message"`
+	Proposal *ViolationProposal `json:"proposal,omitempty"`
+}
+
+// RecordsVoidedPayload is the payload of a RecordsVoided event
+type RecordsVoidedPayload struct {
+	Reason     string   `json:"reason"`
+	ProposalID string   `json:"proposalId"`
+	RecordIDs  []string `json:"recordIds"`
+}
+
+// ProposalPayload is the payload of the ViolationActionProposed and ProposalApproved events
+type ProposalPayload struct {
+	ProposalID string `json:"proposalId"`
+	Action     string `json:"action"`
+	Status     string `json:"status"`
+	Approvals  int    `json:"approvals"`
+	Quorum     int    `json:"quorum"`
+}
+
+// GovernanceManager handles multi-party approval of destructive reconciliation
+type GovernanceManager struct{}
+
+// NewGovernanceManager creates a new GovernanceManager instance
+func NewGovernanceManager() *GovernanceManager {
+	return &GovernanceManager{}
+}
+
+// ProposeViolationAction records the deletions or voids a comparison would perform.
+// The proposing organization counts as the first approval.
+func (gm *GovernanceManager) ProposeViolationAction(ctx contractapi.TransactionContextInterface, proposalData string) (string, error) {
+	logger.Println("============= START : Propose Violation Action ===========")
+
+	var request ProposalRequest
+	err := json.Unmarshal([]byte(proposalData), &request)
+	if err != nil {
+		return "", fmt.Errorf("invalid proposal: %v", err)
+	}
+	if request.IncrementField != "press_increment" && request.IncrementField != "store_increment" {
+		return "", fmt.Errorf("invalid proposal: incrementField must be press_increment or store_increment")
+	}
+	if request.Action != ProposalActionDelete && request.Action != ProposalActionVoid {
+		return "", fmt.Errorf("invalid proposal: action must be %s or %s", ProposalActionDelete, ProposalActionVoid)
+	}
+
+	if request.Options != nil && request.Options.Scope != nil && request.Options.Scope.CollectorName != "" {
+		return "", fmt.Errorf("invalid proposal: proposals are public and cannot be scoped to a collector")
+	}
+
+	optionsJSON := ""
+	if request.Options != nil {
+		optionsBytes, _ := json.Marshal(request.Options)
+		optionsJSON = string(optionsBytes)
+	}
+
+	wc := NewWeightComparison()
+	comparisonOptions, err := wc.parseOptions(ctx, optionsJSON)
+	if err != nil {
+		return "", err
+	}
+	access, err := getTenantAccess(ctx)
+	if err != nil {
+		return "", err
+	}
+	violations, err := wc.findViolations(ctx, request.IncrementField, comparisonOptions, access)
+	if err != nil {
+		return "", err
+	}
+	if len(violations) == 0 {
+		return "", fmt.Errorf("no weight violations found, nothing to propose")
+	}
+
+	governance, err := loadGovernanceConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	creator, err := getCreatorInfo(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	proposal := &ViolationProposal{
+		ProposalID:     fmt.Sprintf("PROPOSAL_%s", ctx.GetStub().GetTxID()),
+		IncrementField: request.IncrementField,
+		Action:         request.Action,
+		Reason:         request.Reason,
+		Options:        comparisonOptions,
+		SponsorScope:   access,
+		Results:        []ComparisonResult{},
+		Rejections:     []RecordRejection{},
+		Fingerprints:   map[string]string{},
+		Quorum:         governance.Quorum,
+		Approvals:      []ProposalApproval{},
+		Status:         ProposalStatusPending,
+		AffectedKeys:   []string{},
+		SkippedRecords: []SkippedRecord{},
+		ProposedBy:     creator.ID,
+		ProposedMSP:    creator.MSPID,
+		CreatedAt:      getTxTimestamp(ctx),
+		DocType:        "violationProposal",
+	}
+
+	// Fingerprint every record and ticket of the violating groups so the
+	// proposal only executes against the data it was approved for
+	for _, violation := range violations {
+		proposal.Results = append(proposal.Results, violation.Result)
+		proposal.Rejections = append(proposal.Rejections, violation.Rejections...)
+
+		keys := append([]string{}, violation.Group.RecordIDs...)
+		for _, ticket := range violation.Group.Tickets {
+			keys = append(keys, ticket.TicketKey)
+		}
+		for _, key := range keys {
+			fingerprint, err := documentFingerprint(ctx, key)
+			if err != nil {
+				return "", err
+			}
+			proposal.Fingerprints[key] = fingerprint
+		}
+	}
+
+	return gm.approve(ctx, proposal, creator, EventViolationActionProposed)
+}
+
+// ApproveProposal adds the caller's organization to the approvals of a proposal and
+// executes it once the quorum is reached
+func (gm *GovernanceManager) ApproveProposal(ctx contractapi.TransactionContextInterface, proposalID string) (string, error) {
+	logger.Println("============= START : Approve Proposal ===========")
+
+	proposal, err := getProposal(ctx, proposalID)
+	if err != nil {
+		return "", err
+	}
+	if proposal.Status != ProposalStatusPending {
+		return "", fmt.Errorf("proposal %s is %s", proposalID, proposal.Status)
+	}
+
+	creator, err := getCreatorInfo(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, approval := range proposal.Approvals {
+		if approval.MSPID == creator.MSPID {
+			return "", fmt.Errorf("proposal %s was already approved by %s", proposalID, creator.MSPID)
+		}
+	}
+
+	return gm.approve(ctx, proposal, creator, EventProposalApproved)
+}
+
+// QueryProposal queries a violation proposal by ID
+func (gm *GovernanceManager) QueryProposal(ctx contractapi.TransactionContextInterface, proposalID string) (string, error) {
+	proposal, err := getProposal(ctx, proposalID)
+	if err != nil {
+		return "", err
+	}
+
+	access, err := getTenantAccess(ctx)
+	if err != nil {
+		return "", err
+	}
+	if !canSeeProposal(access, proposal) {
+		return "", fmt.Errorf("Proposal %s does not exist", proposalID)
+	}
+
+	proposalJSON, err := json.Marshal(proposal)
+	if err != nil {
+		return "", err
+	}
+	return string(proposalJSON), nil
+}
+
+// QueryProposalsByStatus queries the violation proposals with the given status
+func (gm *GovernanceManager) QueryProposalsByStatus(ctx contractapi.TransactionContextInterface, status string) (string, error) {
+	results, err := queryDocuments(ctx, map[string]interface{}{
+		"docType": "violationProposal",
+		"status":  status,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	access, err := getTenantAccess(ctx)
+	if err != nil {
+		return "", err
+	}
+	visible := []map[string]interface{}{}
+	for _, result := range results {
+		var proposal ViolationProposal
+		recordJSON, _ := json.Marshal(result["Record"])
+		if json.Unmarshal(recordJSON, &proposal) == nil && canSeeProposal(access, &proposal) {
+			visible = append(visible, result)
+		}
+	}
+
+	resultsJSON, err := json.Marshal(visible)
+	if err != nil {
+		return "", err
+	}
+	return string(resultsJSON), nil
+}
+
+func (gm *GovernanceManager) approve(ctx contractapi.TransactionContextInterface, proposal *ViolationProposal, creator *CreatorInfo, eventType string) (string, error) {
+	proposal.Approvals = append(proposal.Approvals, ProposalApproval{
+		MSPID:      creator.MSPID,
+		ApprovedBy: creator.ID,
+		ApprovedAt: getTxTimestamp(ctx),
+	})
+
+	events := []EventEntry{}
+	message := fmt.Sprintf("Proposal has %d of %d approvals", len(proposal.Approvals), proposal.Quorum)
+	if len(proposal.Approvals) >= proposal.Quorum {
+		executionEvents, err := gm.execute(ctx, proposal)
+		if err != nil {
+			return "", err
+		}
+		events = append(events, executionEvents...)
+		message = fmt.Sprintf("Proposal %s", proposal.Status)
+	}
+
+	events = append([]EventEntry{{
+		Type: eventType,
+		Payload: ProposalPayload{
+			ProposalID: proposal.ProposalID,
+			Action:     proposal.Action,
+			Status:     proposal.Status,
+			Approvals:  len(proposal.Approvals),
+			Quorum:     proposal.Quorum,
+		},
+	}}, events...)
+
+	proposalJSON, err := json.Marshal(proposal)
+	if err != nil {
+		return "", err
+	}
+	err = ctx.GetStub().PutState(proposal.ProposalID, proposalJSON)
+	if err != nil {
+		return "", fmt.Errorf("failed to put proposal to world state: %v", err)
+	}
+
+	err = emitEvents(ctx, events)
+	if err != nil {
+		return "", err
+	}
+
+	logger.Println("============= END : Approve Proposal ===========")
+
+	response := ProposalResponse{
+		Success:  true,
+		Message:  message,
+		Proposal: proposal,
+	}
+	responseJSON, _ := json.Marshal(response)
+	return string(responseJSON), nil
+}
+
+// execute carries out the proposed action, unless any fingerprinted document changed since the proposal
+// or the comparison, run again, no longer finds the proposed violations and rejections. Running it again
+// catches documents added to the violating groups, which the fingerprints do not cover.
+func (gm *GovernanceManager) execute(ctx contractapi.TransactionContextInterface, proposal *ViolationProposal) ([]EventEntry, error) {
+	proposal.ClosedAt = getTxTimestamp(ctx)
+
+	keys := make([]string, 0, len(proposal.Fingerprints))
+	for key := range proposal.Fingerprints {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	staleKeys := []string{}
+	for _, key := range keys {
+		fingerprint, err := documentFingerprint(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if fingerprint != proposal.Fingerprints[key] {
+			staleKeys = append(staleKeys, key)
+		}
+	}
+	if len(staleKeys) > 0 {
+		proposal.Status = ProposalStatusStale
+		proposal.StaleKeys = staleKeys
+		proposal.StaleReason = "documents changed since the proposal"
+		return []EventEntry{}, nil
+	}
+
+	// The comparison runs again with the proposer's sponsors, the approver may see more or fewer of them
+	if proposal.SponsorScope == nil {
+		proposal.Status = ProposalStatusStale
+		proposal.StaleReason = "proposal does not record the proposer's sponsors"
+		return []EventEntry{}, nil
+	}
+	violations, err := NewWeightComparison().findViolations(ctx, proposal.IncrementField, proposal.Options, proposal.SponsorScope)
+	if err != nil {
+		return nil, err
+	}
+	results := []ComparisonResult{}
+	rejections := []RecordRejection{}
+	for _, violation := range violations {
+		results = append(results, violation.Result)
+		rejections = append(rejections, violation.Rejections...)
+	}
+	if !sameJSON(results, proposal.Results) || !sameJSON(rejections, proposal.Rejections) {
+		proposal.Status = ProposalStatusStale
+		proposal.StaleReason = "weight violations changed since the proposal"
+		return []EventEntry{}, nil
+	}
+
+	approvingMSPs := []string{}
+	for _, approval := range proposal.Approvals {
+		approvingMSPs = append(approvingMSPs, approval.MSPID)
+	}
+
+	for _, rejection := range proposal.Rejections {
+		document, err := getDocument(ctx, rejection.RecordID)
+		if err != nil {
+			return nil, err
+		}
+		if !canModifyAsAny(document, approvingMSPs) {
+			proposal.SkippedRecords = append(proposal.SkippedRecords, SkippedRecord{
+				RecordID: rejection.RecordID,
+				Reason:   "owner organization is not among the approving organizations",
+			})
+			continue
+		}
+		// Credits may have been issued since the proposal, their records stay in place
+		backing, err := getCreditBacking(ctx, rejection.RecordID)
+		if err != nil {
+			return nil, err
+		}
+		if backing != nil {
+			proposal.SkippedRecords = append(proposal.SkippedRecords, SkippedRecord{
+				RecordID: rejection.RecordID,
+				Reason:   creditBackedError(backing).Error(),
+			})
+			continue
+		}
+
+		if proposal.Action == ProposalActionDelete {
+			err = deletePrivateDetails(ctx, document)
+			if err == nil {
+				err = ctx.GetStub().DelState(rejection.RecordID)
+			}
+		} else {
+			document["status"] = RecordStatusVoided
+			document["voidedAt"] = proposal.ClosedAt
+			document["voidReason"] = rejection.Reason
+			document["voidProposalId"] = proposal.ProposalID
+			documentJSON, _ := json.Marshal(document)
+			err = ctx.GetStub().PutState(rejection.RecordID, documentJSON)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to %s %s: %v", proposal.Action, rejection.RecordID, err)
+		}
+		proposal.AffectedKeys = append(proposal.AffectedKeys, rejection.RecordID)
+	}
+	proposal.Status = ProposalStatusExecuted
+
+	if len(proposal.AffectedKeys) == 0 {
+		return []EventEntry{}, nil
+	}
+	if proposal.Action == ProposalActionDelete {
+		return []EventEntry{{
+			Type: EventRecordsDeleted,
+			Payload: RecordsDeletedPayload{
+				Reason:    fmt.Sprintf("weight violation, proposal %s", proposal.ProposalID),
+				RecordIDs: proposal.AffectedKeys,
+			},
+		}}, nil
+	}
+	return []EventEntry{{
+		Type: EventRecordsVoided,
+		Payload: RecordsVoidedPayload{
+			Reason:     "weight violation",
+			ProposalID: proposal.ProposalID,
+			RecordIDs:  proposal.AffectedKeys,
+		},
+	}}, nil
+}
+
+func getProposal(ctx contractapi.TransactionContextInterface, proposalID string) (*ViolationProposal, error) {
+	proposalAsBytes, err := ctx.GetStub().GetState(proposalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if len(proposalAsBytes) == 0 {
+		return nil, fmt.Errorf("Proposal %s does not exist", proposalID)
+	}
+
+	var proposal ViolationProposal
+	err = json.Unmarshal(proposalAsBytes, &proposal)
+	if err != nil || proposal.DocType != "violationProposal" {
+		return nil, fmt.Errorf("Proposal %s does not exist", proposalID)
+	}
+	return &proposal, nil
+}
+
+// canSeeProposal reports whether the caller may see every sponsor whose results a proposal holds
+func canSeeProposal(access *TenantAccess, proposal *ViolationProposal) bool {
+	for _, result := range proposal.Results {
+		if !access.CanSee(result.SponsorID) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameJSON reports whether two values have the same JSON encoding
+func sameJSON(a interface{}, b interface{}) bool {
+	aJSON, errA := json.Marshal(a)
+	bJSON, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(aJSON) == string(bJSON)
+}
+
+// documentFingerprint returns the SHA-256 of a document's current state, or "" if it does not exist
+func documentFingerprint(ctx contractapi.TransactionContextInterface, key string) (string, error) {
+	documentAsBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if len(documentAsBytes) == 0 {
+		return "", nil
+	}
+	hash := sha256.Sum256(documentAsBytes)
+	return hex.EncodeToString(hash[:]), nil
+}
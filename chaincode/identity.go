@@ -0,0 +1,145 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// CreatorInfo represents the client identity that created a document
+type CreatorInfo struct {
+	ID         string            `json:"id"`
+	MSPID      string            `json:"mspId"`
+	SubjectCN  string            `json:"subjectCN"`
+	Subject    string            `json:"subject"`
+	Issuer     string            `json:"issuer"`
+	IssuerCN   string            `json:"issuerCN"`
+	Attributes map[string]string `json:"attributes"`
+}
+
+// creatorDimensions are the identity dimensions documents can be queried by
+var creatorDimensions = []string{"id", "mspId", "subjectCN", "subject", "issuer", "issuerCN"}
+
+// getCreatorInfo captures the caller's identity. Unlike the bare client ID, a missing
+// identity or certificate is an error rather than an empty value.
+func getCreatorInfo(ctx contractapi.TransactionContextInterface) (*CreatorInfo, error) {
+	clientIdentity := ctx.GetClientIdentity()
+	if clientIdentity == nil {
+		return nil, fmt.Errorf("client identity is not available")
+	}
+
+	clientID, err := clientIdentity.GetID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client identity: %v", err)
+	}
+	if clientID == "" {
+		return nil, fmt.Errorf("client identity is empty")
+	}
+
+	mspID, err := clientIdentity.GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client MSP ID: %v", err)
+	}
+	if mspID == "" {
+		return nil, fmt.Errorf("client MSP ID is empty")
+	}
+
+	cert, err := clientIdentity.GetX509Certificate()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client certificate: %v", err)
+	}
+	if cert == nil {
+		return nil, fmt.Errorf("client certificate is not available")
+	}
+
+	config, err := loadIdentityConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes := map[string]string{}
+	for _, name := range config.Attributes {
+		value, found, err := clientIdentity.GetAttributeValue(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client identity attributes: %v", err)
+		}
+		if found {
+			attributes[name] = value
+		}
+	}
+
+	return &CreatorInfo{
+		ID:         clientID,
+		MSPID:      mspID,
+		SubjectCN:  cert.Subject.CommonName,
+		Subject:    cert.Subject.String(),
+		Issuer:     cert.Issuer.String(),
+		IssuerCN:   cert.Issuer.CommonName,
+		Attributes: attributes,
+	}, nil
+}
+
+// QueryDocumentsByCreator queries proof records and tickets by an identity dimension of their creator.
+// The dimension is one of id, mspId, subjectCN, subject, issuer, issuerCN or attributes.<name>.
+func (qu *QueryUtils) QueryDocumentsByCreator(ctx contractapi.TransactionContextInterface, dimension string, value string) (string, error) {
+	logger.Printf("============= START : Query Documents By Creator %s ===========\n", dimension)
+
+	if !isCreatorDimension(dimension) {
+		return "", fmt.Errorf("invalid creator dimension %s", dimension)
+	}
+
+	access, err := getTenantAccess(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	view, err := getRedactionView(ctx)
+	if err != nil {
+		return "", err
+	}
+	err = view.checkQueryField("creator")
+	if err != nil {
+		return "", err
+	}
+
+	selector := map[string]interface{}{
+		"docType": map[string]interface{}{
+			"$in": []string{"proofRecord", "ticket"},
+		},
+		"creator." + dimension: value,
+	}
+	access.restrictSelector(selector)
+
+	results, err := queryDocuments(ctx, selector)
+	if err != nil {
+		return "", err
+	}
+
+	err = mergePrivateDetails(ctx, results)
+	if err != nil {
+		return "", err
+	}
+	view.redactResults(results)
+
+	resultsJSON, err := json.Marshal(results)
+	if err != nil {
+		return "", err
+	}
+
+	logger.Println("============= END : Query Documents By Creator ===========")
+	return string(resultsJSON), nil
+}
+
+func isCreatorDimension(dimension string) bool {
+	if strings.HasPrefix(dimension, "attributes.") {
+		return len(dimension) > len("attributes.")
+	}
+	for _, creatorDimension := range creatorDimensions {
+		if creatorDimension == dimension {
+			return true
+		}
+	}
+	return false
+}
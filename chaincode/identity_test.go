@@ -0,0 +1,92 @@
+package chaincode
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+)
+
+func testCertificate() *x509.Certificate {
+	return &x509.Certificate{
+		Subject: pkix.Name{CommonName: "user1", Organization: []string{"Org1"}},
+		Issuer:  pkix.Name{CommonName: "ca.org1", Organization: []string{"Org1"}},
+	}
+}
+
+func TestGetCreatorInfo(t *testing.T) {
+	ctx := newTestContext(t)
+	identity := setCaller(ctx, "collector")
+	identity.cert = testCertificate()
+	identity.attributes["site"] = "north"
+	identity.attributes["clearance"] = "secret"
+
+	creator, err := getCreatorInfo(ctx)
+	if err != nil {
+		t.Fatalf("getCreatorInfo failed: %v", err)
+	}
+	if creator.MSPID != "Org1MSP" || creator.SubjectCN != "user1" || creator.IssuerCN != "ca.org1" {
+		t.Errorf("creator = %+v", creator)
+	}
+	if creator.Subject != "CN=user1,O=Org1" || creator.Issuer != "CN=ca.org1,O=Org1" {
+		t.Errorf("subject = %s, issuer = %s", creator.Subject, creator.Issuer)
+	}
+	if len(creator.Attributes) != 1 || creator.Attributes["site"] != "north" {
+		t.Errorf("attributes = %v, want the configured site attribute only", creator.Attributes)
+	}
+
+	if _, err := NewConfigManager().SetIdentityConfig(ctx, `{"attributes":["clearance"]}`); err != nil {
+		t.Fatalf("SetIdentityConfig failed: %v", err)
+	}
+	creator, err = getCreatorInfo(ctx)
+	if err != nil {
+		t.Fatalf("getCreatorInfo failed: %v", err)
+	}
+	if len(creator.Attributes) != 1 || creator.Attributes["clearance"] != "secret" {
+		t.Errorf("attributes = %v, want the configured clearance attribute only", creator.Attributes)
+	}
+}
+
+func TestGetCreatorInfoRequiresACertificate(t *testing.T) {
+	ctx := newTestContext(t)
+	setCaller(ctx, "collector")
+	if _, err := getCreatorInfo(ctx); err == nil {
+		t.Errorf("identity without certificate was accepted")
+	}
+}
+
+func TestCreateTicketUsesTheTransactionTimestamp(t *testing.T) {
+	ctx := newTestContext(t)
+	identity := setCaller(ctx, "weighbridge")
+	identity.cert = testCertificate()
+	ctx.GetStub().(*shimtest.MockStub).TxTimestamp.Seconds = 946684800
+
+	responseJSON, err := NewTicketManager().CreateTicket(ctx, `{"id":"T1","incrementId":1,"receivedWeight":10}`)
+	if err != nil {
+		t.Fatalf("CreateTicket failed: %v", err)
+	}
+	var response CreateTicketResponse
+	if err := json.Unmarshal([]byte(responseJSON), &response); err != nil {
+		t.Fatalf("invalid response %s: %v", responseJSON, err)
+	}
+	if !response.Success || response.Ticket.CreatedAt != "2000-01-01T00:00:00Z" {
+		t.Errorf("response = %s, want the ticket created at the transaction timestamp", responseJSON)
+	}
+}
+
+func TestIsCreatorDimension(t *testing.T) {
+	tests := map[string]bool{
+		"mspId":           true,
+		"subjectCN":       true,
+		"attributes.site": true,
+		"attributes.":     false,
+		"email":           false,
+	}
+	for dimension, want := range tests {
+		if got := isCreatorDimension(dimension); got != want {
+			t.Errorf("isCreatorDimension(%q) = %v, want %v", dimension, got, want)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package chaincode
+
+import (
+	"io"
+	"log"
+	"os"
+)
+
+// logger prints the progress and error messages of the contract, to standard output by default
+var logger = log.New(os.Stdout, "", 0)
+
+// SetLogOutput redirects the messages of the contract, for hosts whose standard output
+// carries the results of transactions
+func SetLogOutput(w io.Writer) {
+	logger.SetOutput(w)
+}
@@ -0,0 +1,350 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// OwnershipError is returned when the caller's organization may not modify a document
+type OwnershipError struct {
+	Key       string
+	CallerMSP string
+	OwnerMSP  string
+}
+
+func (e *OwnershipError) Error() string {
+	if e.OwnerMSP == "" {
+		return fmt.Sprintf("ownership denied: %s has no owner organization and may only be modified by an admin", e.Key)
+	}
+	return fmt.Sprintf("ownership denied: %s is owned by %s and %s is not the owner or a delegate", e.Key, e.OwnerMSP, e.CallerMSP)
+}
+
+// OwnershipTransferredPayload is the payload of an OwnershipTransferred event
+type OwnershipTransferredPayload struct {
+	Keys        []string `json:"keys"`
+	PreviousMSP string   `json:"previousMSP"`
+	OwnerMSP    string   `json:"ownerMSP"`
+}
+
+// OwnershipResponse represents the response of an ownership operation
+type OwnershipResponse struct {
+	Success bool     `json:"success"`
+	Message string   `json:"message"`
+	Keys    []string `json:"keys"`
+}
+
+// OwnershipManager handles the owner and delegated organizations of documents
+type OwnershipManager struct{}
+
+// NewOwnershipManager creates a new OwnershipManager instance
+func NewOwnershipManager() *OwnershipManager {
+	return &OwnershipManager{}
+}
+
+// TransferOwnership transfers a proof record or ticket to another organization
+func (om *OwnershipManager) TransferOwnership(ctx contractapi.TransactionContextInterface, key string, newOwnerMSP string) (string, error) {
+	logger.Println("============= START : Transfer Ownership ===========")
+
+	callerMSP, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return "", fmt.Errorf("failed to read client MSP ID: %v", err)
+	}
+
+	previousMSP, err := om.transferDocument(ctx, key, callerMSP, newOwnerMSP)
+	if err != nil {
+		return "", err
+	}
+
+	err = emitEvents(ctx, []EventEntry{{
+		Type: EventOwnershipTransferred,
+		Payload: OwnershipTransferredPayload{
+			Keys:        []string{key},
+			PreviousMSP: previousMSP,
+			OwnerMSP:    newOwnerMSP,
+		},
+	}})
+	if err != nil {
+		return "", err
+	}
+
+	logger.Println("============= END : Transfer Ownership ===========")
+
+	response := OwnershipResponse{
+		Success: true,
+		Message: fmt.Sprintf("Ownership transferred to %s", newOwnerMSP),
+		Keys:    []string{key},
+	}
+	responseJSON, _ := json.Marshal(response)
+	return string(responseJSON), nil
+}
+
+// TransferBulkOwnership transfers every proof record of a bulk owned by the caller's organization
+func (om *OwnershipManager) TransferBulkOwnership(ctx contractapi.TransactionContextInterface, bulkShortID string, newOwnerMSP string) (string, error) {
+	logger.Println("============= START : Transfer Bulk Ownership ===========")
+
+	callerMSP, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return "", fmt.Errorf("failed to read client MSP ID: %v", err)
+	}
+
+	records, err := queryDocuments(ctx, map[string]interface{}{
+		"docType":       "proofRecord",
+		"bulk_short_id": bulkShortID,
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(records) == 0 {
+		return "", fmt.Errorf("Bulk %s has no proof records", bulkShortID)
+	}
+
+	keys := []string{}
+	for _, item := range records {
+		key := item["Key"].(string)
+		_, err := om.transferDocument(ctx, key, callerMSP, newOwnerMSP)
+		if err != nil {
+			return "", err
+		}
+		keys = append(keys, key)
+	}
+
+	err = emitEvents(ctx, []EventEntry{{
+		Type: EventOwnershipTransferred,
+		Payload: OwnershipTransferredPayload{
+			Keys:        keys,
+			PreviousMSP: callerMSP,
+			OwnerMSP:    newOwnerMSP,
+		},
+	}})
+	if err != nil {
+		return "", err
+	}
+
+	logger.Println("============= END : Transfer Bulk Ownership ===========")
+
+	response := OwnershipResponse{
+		Success: true,
+		Message: fmt.Sprintf("Bulk %s transferred to %s", bulkShortID, newOwnerMSP),
+		Keys:    keys,
+	}
+	responseJSON, _ := json.Marshal(response)
+	return string(responseJSON), nil
+}
+
+// DelegateOwnership allows another organization to modify a document owned by the caller's organization
+func (om *OwnershipManager) DelegateOwnership(ctx contractapi.TransactionContextInterface, key string, delegateMSP string) (string, error) {
+	return om.updateDelegates(ctx, key, func(delegates []string) []string {
+		return addUnique(delegates, delegateMSP)
+	})
+}
+
+// RevokeDelegation withdraws a delegation granted by the caller's organization
+func (om *OwnershipManager) RevokeDelegation(ctx contractapi.TransactionContextInterface, key string, delegateMSP string) (string, error) {
+	return om.updateDelegates(ctx, key, func(delegates []string) []string {
+		remaining := []string{}
+		for _, delegate := range delegates {
+			if delegate != delegateMSP {
+				remaining = append(remaining, delegate)
+			}
+		}
+		return remaining
+	})
+}
+
+func (om *OwnershipManager) updateDelegates(ctx contractapi.TransactionContextInterface, key string, update func([]string) []string) (string, error) {
+	callerMSP, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return "", fmt.Errorf("failed to read client MSP ID: %v", err)
+	}
+
+	document, err := getOwnableDocument(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	ownerMSP, _ := document["ownerMSP"].(string)
+	if ownerMSP == "" || ownerMSP != callerMSP {
+		return "", &OwnershipError{Key: key, CallerMSP: callerMSP, OwnerMSP: ownerMSP}
+	}
+
+	document["delegatedMSPs"] = update(getDelegatedMSPs(document))
+
+	documentJSON, err := json.Marshal(document)
+	if err != nil {
+		return "", err
+	}
+	err = ctx.GetStub().PutState(key, documentJSON)
+	if err != nil {
+		return "", fmt.Errorf("failed to put %s to world state: %v", key, err)
+	}
+
+	return string(documentJSON), nil
+}
+
+// transferDocument moves a document to a new owner organization and clears its delegations
+func (om *OwnershipManager) transferDocument(ctx contractapi.TransactionContextInterface, key string, callerMSP string, newOwnerMSP string) (string, error) {
+	if newOwnerMSP == "" {
+		return "", fmt.Errorf("new owner MSP ID is required")
+	}
+
+	document, err := getOwnableDocument(ctx, key)
+	if err != nil {
+		return "", err
+	}
+
+	ownerMSP, _ := document["ownerMSP"].(string)
+	if ownerMSP != callerMSP {
+		// Documents created before ownership was recorded may only be claimed by an admin
+		isAdmin, err := callerHasRole(ctx, RoleAdmin)
+		if err != nil {
+			return "", err
+		}
+		if ownerMSP != "" || !isAdmin {
+			return "", &OwnershipError{Key: key, CallerMSP: callerMSP, OwnerMSP: ownerMSP}
+		}
+	}
+
+	document["ownerMSP"] = newOwnerMSP
+	document["delegatedMSPs"] = []string{}
+
+	documentJSON, err := json.Marshal(document)
+	if err != nil {
+		return "", err
+	}
+	err = ctx.GetStub().PutState(key, documentJSON)
+	if err != nil {
+		return "", fmt.Errorf("failed to put %s to world state: %v", key, err)
+	}
+
+	// The previous owner must no longer be able to endorse changes to the proof record
+	if document["docType"] == "proofRecord" {
+		sponsorID, _ := document["sponsor_id"].(string)
+		err = setRecordEndorsementPolicy(ctx, key, sponsorID, newOwnerMSP)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return ownerMSP, nil
+}
+
+// checkCanModify verifies that the caller's organization owns the document or was delegated to
+func checkCanModify(ctx contractapi.TransactionContextInterface, key string, document map[string]interface{}) error {
+	callerMSP, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to read client MSP ID: %v", err)
+	}
+
+	ownerMSP, _ := document["ownerMSP"].(string)
+	if ownerMSP == "" {
+		isAdmin, err := callerHasRole(ctx, RoleAdmin)
+		if err != nil {
+			return err
+		}
+		if isAdmin {
+			return nil
+		}
+		return &OwnershipError{Key: key, CallerMSP: callerMSP}
+	}
+
+	if ownerMSP == callerMSP {
+		return nil
+	}
+	for _, delegate := range getDelegatedMSPs(document) {
+		if delegate == callerMSP {
+			return nil
+		}
+	}
+	return &OwnershipError{Key: key, CallerMSP: callerMSP, OwnerMSP: ownerMSP}
+}
+
+// canModifyAsAny reports whether any of the given organizations owns the document or was
+// delegated to. Documents without an owner organization may be modified by any of them.
+func canModifyAsAny(document map[string]interface{}, mspIDs []string) bool {
+	ownerMSP, _ := document["ownerMSP"].(string)
+	if ownerMSP == "" {
+		return true
+	}
+
+	allowed := append([]string{ownerMSP}, getDelegatedMSPs(document)...)
+	for _, mspID := range mspIDs {
+		for _, allowedMSP := range allowed {
+			if mspID == allowedMSP {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// deleteOwnedDocument deletes a document after checking the caller's organization may modify it
+// and that it backs no issued credit
+func deleteOwnedDocument(ctx contractapi.TransactionContextInterface, key string) error {
+	document, err := getDocument(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	err = checkCanModify(ctx, key, document)
+	if err != nil {
+		return err
+	}
+
+	backing, err := getCreditBacking(ctx, key)
+	if err != nil {
+		return err
+	}
+	if backing != nil {
+		return creditBackedError(backing)
+	}
+
+	err = deletePrivateDetails(ctx, document)
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().DelState(key)
+}
+
+// getDocument reads a JSON document from world state
+func getDocument(ctx contractapi.TransactionContextInterface, key string) (map[string]interface{}, error) {
+	documentAsBytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if len(documentAsBytes) == 0 {
+		return nil, fmt.Errorf("Document %s does not exist", key)
+	}
+
+	var document map[string]interface{}
+	err = json.Unmarshal(documentAsBytes, &document)
+	if err != nil {
+		return nil, fmt.Errorf("invalid document %s: %v", key, err)
+	}
+	return document, nil
+}
+
+// getOwnableDocument reads a proof record or ticket, the only documents whose ownership may change hands.
+// Configuration, registry, credit and governance documents are reported as missing.
+func getOwnableDocument(ctx contractapi.TransactionContextInterface, key string) (map[string]interface{}, error) {
+	document, err := getDocument(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if document["docType"] != "proofRecord" && document["docType"] != "ticket" {
+		return nil, fmt.Errorf("Document %s does not exist", key)
+	}
+	return document, nil
+}
+
+func getDelegatedMSPs(document map[string]interface{}) []string {
+	delegates := []string{}
+	values, _ := document["delegatedMSPs"].([]interface{})
+	for _, value := range values {
+		if delegate, ok := value.(string); ok {
+			delegates = append(delegates, delegate)
+		}
+	}
+	return delegates
+}
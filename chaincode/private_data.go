@@ -0,0 +1,288 @@
+package chaincode
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// PrivateCollectionName is the default private data collection holding the personal fields of proof
+// records, tickets and collectors, as defined for the test network in collections_config.json. Other
+// networks define a collection of their own organizations and name it in the privacy config. Peers
+// purge private details blockToLive blocks after they were last written, which bounds how long erased
+// personal data survives in the private data store of the peers.
+const PrivateCollectionName = "collectorPrivateDetails"
+
+// TransientRecordKey and TransientTicketKey are the transient map entries holding the payload of
+// CreateProofRecordPrivate and CreateTicketPrivate
+const (
+	TransientRecordKey = "record"
+	TransientTicketKey = "ticket"
+)
+
+// protectedFields are the proof record and ticket fields that must stay in the public document
+var protectedFields = []string{
+	"id",
+	"receivedWeight",
+	"incrementId",
+	"sponsor_id",
+	"proof_short_id",
+	"bulk_short_id",
+	"collector_id",
+	"parent_increment",
+	"chained_weight",
+	"store_increment",
+	"press_increment",
+	"recordId",
+	"createdAt",
+	"createdBy",
+	"creator",
+	"ownerMSP",
+	"delegatedMSPs",
+	"docType",
+}
+
+// PrivateDataReference points a public proof record at its private details. The hash covers the
+// details together with their secret salt, so it cannot be matched against guessed values.
+type PrivateDataReference struct {
+	Collection string `json:"collection"`
+	Key        string `json:"key"`
+	Hash       string `json:"hash"`
+}
+
+// splitPrivateFields removes the given private fields from a document and returns them
+func splitPrivateFields(document map[string]interface{}, privateFields []string) map[string]interface{} {
+	details := map[string]interface{}{}
+	for _, field := range privateFields {
+		if value, exists := document[field]; exists {
+			details[field] = value
+			delete(document, field)
+		}
+	}
+	return details
+}
+
+// readTransientPayload returns the payload of a transaction passed under the given transient map key
+func readTransientPayload(ctx contractapi.TransactionContextInterface, key string) (string, error) {
+	transientMap, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return "", fmt.Errorf("failed to read transient map: %v", err)
+	}
+
+	payload, exists := transientMap[key]
+	if !exists || len(payload) == 0 {
+		return "", fmt.Errorf("transient map entry %s is missing", key)
+	}
+	return string(payload), nil
+}
+
+// readTransientSalt returns the secret salt of the transient map, or "" when the caller passed none
+func readTransientSalt(ctx contractapi.TransactionContextInterface) (string, error) {
+	transientMap, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return "", fmt.Errorf("failed to read transient map: %v", err)
+	}
+	return string(transientMap[TransientSaltKey]), nil
+}
+
+// putPrivateDetails stores the private fields of a document in the private data collection
+// and returns the reference to keep in the public document. The details are stored with the
+// secret salt of the transient map: Fabric publishes the hash of every private value, which
+// would otherwise give away short values such as names to anyone hashing guesses. Callers
+// passing the document in the transaction arguments have disclosed its fields already and
+// may leave the salt out, their details are stored unsalted as before.
+func putPrivateDetails(ctx contractapi.TransactionContextInterface, config *PrivacyConfig, recordKey string, docType string, details map[string]interface{}) (*PrivateDataReference, error) {
+	salt, err := readTransientSalt(ctx)
+	if err != nil {
+		return nil, err
+	}
+	details["recordId"] = recordKey
+	details["docType"] = docType
+	if salt != "" {
+		details["salt"] = salt
+	}
+
+	detailsJSON, err := json.Marshal(details)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ctx.GetStub().PutPrivateData(config.Collection, recordKey, detailsJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put private details to collection %s: %v", config.Collection, err)
+	}
+
+	hash := sha256.Sum256(detailsJSON)
+	return &PrivateDataReference{
+		Collection: config.Collection,
+		Key:        recordKey,
+		Hash:       hex.EncodeToString(hash[:]),
+	}, nil
+}
+
+// deletePrivateDetails removes the private details referenced by a public document, if any
+func deletePrivateDetails(ctx contractapi.TransactionContextInterface, document map[string]interface{}) error {
+	reference, ok := document["privateData"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	collection := fmt.Sprint(reference["collection"])
+	key := fmt.Sprint(reference["key"])
+	err := ctx.GetStub().DelPrivateData(collection, key)
+	if err != nil {
+		return fmt.Errorf("failed to delete private details %s: %v", key, err)
+	}
+	return nil
+}
+
+// canReadPrivateDetails reports whether the caller's organization may read the private details of a
+// document: organizations authorized in the privacy config, the owner organization and its delegates
+func canReadPrivateDetails(ctx contractapi.TransactionContextInterface, config *PrivacyConfig, document map[string]interface{}) (bool, error) {
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return false, fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+
+	for _, authorizedMSP := range config.AuthorizedMSPs {
+		if authorizedMSP == mspID {
+			return true, nil
+		}
+	}
+
+	ownerMSP, _ := document["ownerMSP"].(string)
+	return ownerMSP != "" && canModifyAsAny(document, []string{mspID}), nil
+}
+
+// mergePrivateDetails adds the private fields to the documents of query results the caller may read
+func mergePrivateDetails(ctx contractapi.TransactionContextInterface, results []map[string]interface{}) error {
+	config, err := loadPrivacyConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		document, ok := result["Record"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		err = mergeDocumentPrivateDetails(ctx, config, document)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeDocumentPrivateDetails adds the private fields to a single document the caller may read
+func mergeDocumentPrivateDetails(ctx contractapi.TransactionContextInterface, config *PrivacyConfig, document map[string]interface{}) error {
+	reference, ok := document["privateData"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	authorized, err := canReadPrivateDetails(ctx, config, document)
+	if err != nil || !authorized {
+		return err
+	}
+
+	// Peers outside the collection cannot serve the details, the public record is returned as is
+	detailsAsBytes, err := ctx.GetStub().GetPrivateData(fmt.Sprint(reference["collection"]), fmt.Sprint(reference["key"]))
+	if err != nil || len(detailsAsBytes) == 0 {
+		return nil
+	}
+
+	var details map[string]interface{}
+	err = json.Unmarshal(detailsAsBytes, &details)
+	if err != nil {
+		return fmt.Errorf("invalid private details %s: %v", reference["key"], err)
+	}
+	for field, value := range details {
+		if field == "recordId" || field == "docType" || field == "salt" {
+			continue
+		}
+		document[field] = value
+	}
+	return nil
+}
+
+// queryKeysByPrivateField returns the keys of the documents whose private details have the given value
+func queryKeysByPrivateField(ctx contractapi.TransactionContextInterface, docType string, field string, value interface{}) ([]string, error) {
+	config, err := loadPrivacyConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := map[string]interface{}{
+		"selector": map[string]interface{}{
+			"docType": docType,
+			field:     value,
+		},
+	}
+
+	queryString, err := json.Marshal(query)
+	if err != nil {
+		return nil, err
+	}
+
+	resultsIterator, err := ctx.GetStub().GetPrivateDataQueryResult(config.Collection, string(queryString))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query collection %s: %v", config.Collection, err)
+	}
+	defer resultsIterator.Close()
+
+	recordIDs := []string{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		recordIDs = append(recordIDs, queryResponse.Key)
+	}
+	return recordIDs, nil
+}
+
+// filterPrivateReadable drops the query results whose private details the caller may not read
+func filterPrivateReadable(ctx contractapi.TransactionContextInterface, results []map[string]interface{}) ([]map[string]interface{}, error) {
+	config, err := loadPrivacyConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := []map[string]interface{}{}
+	for _, result := range results {
+		document, ok := result["Record"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		authorized, err := canReadPrivateDetails(ctx, config, document)
+		if err != nil {
+			return nil, err
+		}
+		if authorized {
+			filtered = append(filtered, result)
+		}
+	}
+	return filtered, nil
+}
+
+func isPrivateField(privateFields []string, field string) bool {
+	for _, privateField := range privateFields {
+		if privateField == field {
+			return true
+		}
+	}
+	return false
+}
+
+func isProtectedField(field string) bool {
+	for _, protectedField := range protectedFields {
+		if protectedField == field {
+			return true
+		}
+	}
+	return false
+}
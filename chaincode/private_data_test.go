@@ -0,0 +1,95 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// transientStub is a mock stub passing a transient map, which shimtest does not support
+type transientStub struct {
+	*shimtest.MockStub
+	transient map[string][]byte
+}
+
+func (ts *transientStub) GetTransient() (map[string][]byte, error) {
+	return ts.transient, nil
+}
+
+// setTransient passes the given transient map to the transactions run with ctx
+func setTransient(ctx *contractapi.TransactionContext, transient map[string][]byte) {
+	stub := ctx.GetStub()
+	if wrapped, ok := stub.(*transientStub); ok {
+		stub = wrapped.MockStub
+	}
+	ctx.SetStub(&transientStub{MockStub: stub.(*shimtest.MockStub), transient: transient})
+}
+
+func TestReadTransientPayload(t *testing.T) {
+	ctx := newTestContext(t)
+	setTransient(ctx, map[string][]byte{TransientRecordKey: []byte(`{"sponsor_id":"S1"}`), TransientTicketKey: {}})
+
+	payload, err := readTransientPayload(ctx, TransientRecordKey)
+	if err != nil || payload != `{"sponsor_id":"S1"}` {
+		t.Errorf("record payload = %q, %v", payload, err)
+	}
+	for _, key := range []string{TransientTicketKey, "other"} {
+		if _, err := readTransientPayload(ctx, key); err == nil || !strings.Contains(err.Error(), "is missing") {
+			t.Errorf("payload %s error = %v, want missing", key, err)
+		}
+	}
+}
+
+func TestCreateProofRecordPrivateRequiresThePayload(t *testing.T) {
+	ctx := newTestContext(t)
+	setTransient(ctx, map[string][]byte{})
+
+	responseJSON, err := NewProofRecordManager().CreateProofRecordPrivate(ctx)
+	if err != nil {
+		t.Fatalf("CreateProofRecordPrivate failed: %v", err)
+	}
+	var response CreateProofRecordResponse
+	if err := json.Unmarshal([]byte(responseJSON), &response); err != nil {
+		t.Fatalf("invalid response %s: %v", responseJSON, err)
+	}
+	if response.Success || !strings.Contains(response.Message, "transient map entry record is missing") {
+		t.Errorf("response = %+v", response)
+	}
+}
+
+func TestSplitPrivateFields(t *testing.T) {
+	ticket := map[string]interface{}{"id": "T-1", "receivedWeight": 10.0, "driver": "Bob"}
+	details := splitPrivateFields(ticket, []string{"driver", "plate"})
+
+	if len(details) != 1 || details["driver"] != "Bob" {
+		t.Errorf("private details = %v", details)
+	}
+	if _, exists := ticket["driver"]; exists || ticket["id"] != "T-1" {
+		t.Errorf("public ticket = %v", ticket)
+	}
+}
+
+func TestSetPrivacyConfigKeepsTicketWeightsPublic(t *testing.T) {
+	ctx := newTestContext(t)
+	cm := NewConfigManager()
+
+	for _, field := range []string{"id", "receivedWeight", "incrementId"} {
+		if _, err := cm.SetPrivacyConfig(ctx, `{"ticketPrivateFields":["`+field+`"]}`); err == nil {
+			t.Errorf("private ticket field %s was accepted", field)
+		}
+	}
+	configJSON, err := cm.SetPrivacyConfig(ctx, `{"ticketPrivateFields":["driver"]}`)
+	if err != nil {
+		t.Fatalf("SetPrivacyConfig failed: %v", err)
+	}
+	var config PrivacyConfig
+	if err := json.Unmarshal([]byte(configJSON), &config); err != nil {
+		t.Fatalf("invalid config %s: %v", configJSON, err)
+	}
+	if len(config.TicketPrivateFields) != 1 || config.TicketPrivateFields[0] != "driver" || config.PrivateFields == nil {
+		t.Errorf("config = %+v", config)
+	}
+}
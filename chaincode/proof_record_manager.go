@@ -1,29 +1,35 @@
-package main
+package chaincode
 
 import (
 	"encoding/json"
 	"fmt"
-	"time"
 
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 )
 
 // ProofRecord represents a proof record
 type ProofRecord struct {
-	SponsorID       string   `json:"sponsor_id"`
-	ProofShortID    string   `json:"proof_short_id"`
-	CollectorName   string   `json:"collector_name"`
-	BulkName        string   `json:"bulk_name"`
-	ParentIncrement float64  `json:"parent_increment"`
-	ChainedWeight   float64  `json:"chained_weight"`
-	TraceChainType  string   `json:"traceChainType"`
-	BulkShortID     string   `json:"bulk_short_id"`
-	StoreIncrement  *float64 `json:"store_increment"`
-	PressIncrement  *float64 `json:"press_increment"`
-	RecordID        string   `json:"recordId"`
-	CreatedAt       string   `json:"createdAt"`
-	CreatedBy       string   `json:"createdBy"`
-	DocType         string   `json:"docType"`
+	SponsorID       string                `json:"sponsor_id"`
+	ProofShortID    string                `json:"proof_short_id"`
+	CollectorName   string                `json:"collector_name,omitempty"`
+	CollectorID     string                `json:"collector_id,omitempty"`
+	BulkName        string                `json:"bulk_name"`
+	ParentIncrement float64               `json:"parent_increment"`
+	ChainedWeight   float64               `json:"chained_weight"`
+	TraceChainType  string                `json:"traceChainType"`
+	BulkShortID     string                `json:"bulk_short_id"`
+	StoreIncrement  *float64              `json:"store_increment"`
+	PressIncrement  *float64              `json:"press_increment"`
+	RecordID        string                `json:"recordId"`
+	CreatedAt       string                `json:"createdAt"`
+	CreatedBy       string                `json:"createdBy"`
+	Creator         *CreatorInfo          `json:"creator,omitempty"`
+	OwnerMSP        string                `json:"ownerMSP"`
+	DelegatedMSPs   []string              `json:"delegatedMSPs"`
+	PrivateData     *PrivateDataReference `json:"privateData,omitempty"`
+	Commitment      *Commitment           `json:"commitment,omitempty"`
+	Erasure         *PersonalDataErasure  `json:"personalDataErased,omitempty"`
+	DocType         string                `json:"docType"`
 }
 
 // ProofRecordManager handles proof record operations
@@ -46,7 +52,7 @@ type CreateProofRecordResponse struct {
 
 // CreateProofRecord creates a new proof record
 func (prm *ProofRecordManager) CreateProofRecord(ctx contractapi.TransactionContextInterface, recordData string) (string, error) {
-	fmt.Println("============= START : Create Proof Record ===========")
+	logger.Println("============= START : Create Proof Record ===========")
 
 	var record map[string]interface{}
 	err := json.Unmarshal([]byte(recordData), &record)
@@ -68,6 +74,26 @@ func (prm *ProofRecordManager) CreateProofRecord(ctx contractapi.TransactionCont
 		return string(responseJSON), nil
 	}
 
+	err = checkSponsorAccess(ctx, fmt.Sprint(record["sponsor_id"]))
+	if err != nil {
+		response := CreateProofRecordResponse{
+			Success: false,
+			Message: fmt.Sprintf("Error creating proof record: %v", err),
+		}
+		responseJSON, _ := json.Marshal(response)
+		return string(responseJSON), nil
+	}
+
+	registryLinks, err := validateRegistryEntities(ctx, record)
+	if err != nil {
+		response := CreateProofRecordResponse{
+			Success: false,
+			Message: fmt.Sprintf("Error creating proof record: %v", err),
+		}
+		responseJSON, _ := json.Marshal(response)
+		return string(responseJSON), nil
+	}
+
 	duplicateCheckResult, err := prm.checkForDuplicates(ctx, record)
 	if err != nil {
 		response := CreateProofRecordResponse{
@@ -89,18 +115,18 @@ func (prm *ProofRecordManager) CreateProofRecord(ctx contractapi.TransactionCont
 		return string(responseJSON), nil
 	}
 
-	recordKey := GenerateRecordKey(record)
-	clientID, err := ctx.GetClientIdentity().GetID()
+	privacyConfig, err := loadPrivacyConfig(ctx)
 	if err != nil {
-		clientID = ""
+		response := CreateProofRecordResponse{
+			Success: false,
+			Message: fmt.Sprintf("Error creating proof record: %v", err),
+		}
+		responseJSON, _ := json.Marshal(response)
+		return string(responseJSON), nil
 	}
 
-	record["recordId"] = recordKey
-	record["createdAt"] = time.Now().UTC().Format(time.RFC3339)
-	record["createdBy"] = clientID
-	record["docType"] = "proofRecord"
-
-	recordJSON, err := json.Marshal(record)
+	// The commitment covers the record as submitted, its public fields are committed to separately
+	commitment, err := newCommitment(ctx, record, privacyConfig.PrivateFields)
 	if err != nil {
 		response := CreateProofRecordResponse{
 			Success: false,
@@ -110,7 +136,19 @@ func (prm *ProofRecordManager) CreateProofRecord(ctx contractapi.TransactionCont
 		return string(responseJSON), nil
 	}
 
-	err = ctx.GetStub().PutState(recordKey, recordJSON)
+	// Personal fields go to the private data collection, the public record keeps a hashed reference
+	privateDetails := splitPrivateFields(record, privacyConfig.PrivateFields)
+
+	recordKey, err := GenerateRecordKey(ctx, record)
+	if err != nil {
+		response := CreateProofRecordResponse{
+			Success: false,
+			Message: fmt.Sprintf("Error creating proof record: %v", err),
+		}
+		responseJSON, _ := json.Marshal(response)
+		return string(responseJSON), nil
+	}
+	creator, err := getCreatorInfo(ctx)
 	if err != nil {
 		response := CreateProofRecordResponse{
 			Success: false,
@@ -120,11 +158,66 @@ func (prm *ProofRecordManager) CreateProofRecord(ctx contractapi.TransactionCont
 		return string(responseJSON), nil
 	}
 
-	fmt.Println("============= END : Create Proof Record ===========")
+	record["recordId"] = recordKey
+	record["createdAt"] = getTxTimestamp(ctx)
+	record["createdBy"] = creator.ID
+	record["creator"] = creator
+	record["ownerMSP"] = creator.MSPID
+	record["delegatedMSPs"] = []string{}
+	if commitment != nil {
+		record["commitment"] = commitment
+	}
+	record["docType"] = "proofRecord"
+	for field, value := range registryLinks {
+		record[field] = value
+	}
+
+	// Nothing was written so far, failures from here on must abort the transaction
+	if len(privateDetails) > 0 {
+		reference, err := putPrivateDetails(ctx, privacyConfig, recordKey, "proofRecordPrivate", privateDetails)
+		if err != nil {
+			return "", fmt.Errorf("Error creating proof record: %v", err)
+		}
+		record["privateData"] = reference
+	}
+
+	recordJSON, err := json.Marshal(record)
+	if err != nil {
+		return "", fmt.Errorf("Error creating proof record: %v", err)
+	}
+
+	err = ctx.GetStub().PutState(recordKey, recordJSON)
+	if err != nil {
+		return "", fmt.Errorf("Error creating proof record: %v", err)
+	}
 
 	var proofRecord ProofRecord
 	json.Unmarshal(recordJSON, &proofRecord)
 
+	err = setRecordEndorsementPolicy(ctx, recordKey, proofRecord.SponsorID, creator.MSPID)
+	if err != nil {
+		return "", fmt.Errorf("Error creating proof record: %v", err)
+	}
+
+	err = emitEvents(ctx, []EventEntry{{
+		Type: EventProofRecordCreated,
+		Payload: ProofRecordCreatedPayload{
+			RecordID:       recordKey,
+			SponsorID:      proofRecord.SponsorID,
+			BulkShortID:    proofRecord.BulkShortID,
+			ChainedWeight:  proofRecord.ChainedWeight,
+			StoreIncrement: proofRecord.StoreIncrement,
+			PressIncrement: proofRecord.PressIncrement,
+			OwnerMSP:       proofRecord.OwnerMSP,
+			CreatedAt:      proofRecord.CreatedAt,
+		},
+	}})
+	if err != nil {
+		return "", fmt.Errorf("Error creating proof record: %v", err)
+	}
+
+	logger.Println("============= END : Create Proof Record ===========")
+
 	response := CreateProofRecordResponse{
 		Success:  true,
 		Message:  "Record saved successfully",
@@ -135,6 +228,32 @@ func (prm *ProofRecordManager) CreateProofRecord(ctx contractapi.TransactionCont
 	return string(responseJSON), nil
 }
 
+// CreateProofRecordPrivate creates a new proof record from the transient map, keeping its
+// personal fields out of the transaction arguments
+func (prm *ProofRecordManager) CreateProofRecordPrivate(ctx contractapi.TransactionContextInterface) (string, error) {
+	recordData, err := readTransientPayload(ctx, TransientRecordKey)
+	if err != nil {
+		response := CreateProofRecordResponse{
+			Success: false,
+			Message: fmt.Sprintf("Error creating proof record: %v", err),
+		}
+		responseJSON, _ := json.Marshal(response)
+		return string(responseJSON), nil
+	}
+
+	// The private payload only stays out of the published private data hashes when it is salted
+	_, err = readTransientPayload(ctx, TransientSaltKey)
+	if err != nil {
+		response := CreateProofRecordResponse{
+			Success: false,
+			Message: fmt.Sprintf("Error creating proof record: a secret salt is required with a private payload: %v", err),
+		}
+		responseJSON, _ := json.Marshal(response)
+		return string(responseJSON), nil
+	}
+	return prm.CreateProofRecord(ctx, recordData)
+}
+
 func (prm *ProofRecordManager) validateRecord(record map[string]interface{}) bool {
 	requiredFields := []string{
 		"sponsor_id",
@@ -225,22 +344,30 @@ func (prm *ProofRecordManager) findDuplicatesByFields(ctx contractapi.Transactio
 		}
 	}
 
+	// Duplicates are only detected among the records of sponsors the caller may see,
+	// records of other sponsors must not be revealed by the response
+	access, err := getTenantAccess(ctx)
+	if err != nil {
+		return nil, err
+	}
+	access.restrictSelector(selector)
+
 	queryString, err := json.Marshal(query)
 	if err != nil {
-		fmt.Printf("Error finding duplicates: %v\n", err)
+		logger.Printf("Error finding duplicates: %v\n", err)
 		return &DuplicateCheckResult{IsDuplicate: false, ExistingRecords: []map[string]interface{}{}}, nil
 	}
 
 	resultsIterator, err := ctx.GetStub().GetQueryResult(string(queryString))
 	if err != nil {
-		fmt.Printf("Error finding duplicates: %v\n", err)
+		logger.Printf("Error finding duplicates: %v\n", err)
 		return &DuplicateCheckResult{IsDuplicate: false, ExistingRecords: []map[string]interface{}{}}, nil
 	}
 	defer resultsIterator.Close()
 
 	existingRecords, err := getAllResults(resultsIterator)
 	if err != nil {
-		fmt.Printf("Error finding duplicates: %v\n", err)
+		logger.Printf("Error finding duplicates: %v\n", err)
 		return &DuplicateCheckResult{IsDuplicate: false, ExistingRecords: []map[string]interface{}{}}, nil
 	}
 
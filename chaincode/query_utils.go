@@ -0,0 +1,497 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// QueryUtils handles query operations
+type QueryUtils struct{}
+
+// NewQueryUtils creates a new QueryUtils instance
+func NewQueryUtils() *QueryUtils {
+	return &QueryUtils{}
+}
+
+// QueryProofRecord queries a proof record by ID
+func (qu *QueryUtils) QueryProofRecord(ctx contractapi.TransactionContextInterface, recordId string) (string, error) {
+	recordAsBytes, err := ctx.GetStub().GetState(recordId)
+	if err != nil {
+		return "", fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if recordAsBytes == nil || len(recordAsBytes) == 0 {
+		return "", fmt.Errorf("Proof record %s does not exist", recordId)
+	}
+
+	visible, err := qu.isVisible(ctx, recordAsBytes)
+	if err != nil {
+		return "", err
+	}
+	if !visible {
+		return "", fmt.Errorf("Proof record %s does not exist", recordId)
+	}
+
+	var record map[string]interface{}
+	err = json.Unmarshal(recordAsBytes, &record)
+	if err != nil {
+		return string(recordAsBytes), nil
+	}
+
+	privacyConfig, err := loadPrivacyConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+	err = mergeDocumentPrivateDetails(ctx, privacyConfig, record)
+	if err != nil {
+		return "", err
+	}
+	err = newReferenceResolver(ctx).resolve(record)
+	if err != nil {
+		return "", err
+	}
+
+	view, err := getRedactionView(ctx)
+	if err != nil {
+		return "", err
+	}
+	view.redactDocument(record)
+
+	recordJSON, err := json.Marshal(record)
+	if err != nil {
+		return "", err
+	}
+	return string(recordJSON), nil
+}
+
+// QueryAllProofRecords queries all proof records
+func (qu *QueryUtils) QueryAllProofRecords(ctx contractapi.TransactionContextInterface) (string, error) {
+	access, err := getTenantAccess(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	selector := map[string]interface{}{
+		"docType": "proofRecord",
+	}
+	access.restrictSelector(selector)
+
+	query := map[string]interface{}{
+		"selector": selector,
+	}
+
+	queryString, err := json.Marshal(query)
+	if err != nil {
+		return "", err
+	}
+
+	resultsIterator, err := ctx.GetStub().GetQueryResult(string(queryString))
+	if err != nil {
+		return "", err
+	}
+	defer resultsIterator.Close()
+
+	allResults, err := getAllResults(resultsIterator)
+	if err != nil {
+		return "", err
+	}
+
+	err = mergePrivateDetails(ctx, allResults)
+	if err != nil {
+		return "", err
+	}
+	err = newReferenceResolver(ctx).resolveResults(allResults)
+	if err != nil {
+		return "", err
+	}
+
+	view, err := getRedactionView(ctx)
+	if err != nil {
+		return "", err
+	}
+	view.redactResults(allResults)
+
+	resultsJSON, err := json.Marshal(allResults)
+	if err != nil {
+		return "", err
+	}
+
+	return string(resultsJSON), nil
+}
+
+// QueryRecordsByField queries records by a specific field
+func (qu *QueryUtils) QueryRecordsByField(ctx contractapi.TransactionContextInterface, fieldName string, fieldValue string) (string, error) {
+	logger.Printf("============= START : Query Records By Field %s ===========\n", fieldName)
+
+	var parsedValue interface{} = fieldValue
+
+	numericFields := []string{"parent_increment", "store_increment", "press_increment", "chained_weight"}
+	isNumeric := false
+	for _, nf := range numericFields {
+		if nf == fieldName {
+			isNumeric = true
+			break
+		}
+	}
+
+	if isNumeric {
+		floatVal, err := strconv.ParseFloat(fieldValue, 64)
+		if err != nil {
+			logger.Printf("Error querying records by %s: Invalid %s: %s is not a number\n", fieldName, fieldName, fieldValue)
+			return "[]", nil
+		}
+		parsedValue = floatVal
+	}
+
+	access, err := getTenantAccess(ctx)
+	if err != nil {
+		logger.Printf("Error querying records by %s: %v\n", fieldName, err)
+		return "[]", nil
+	}
+
+	// Filtering on a redacted field would reveal its values
+	view, err := getRedactionView(ctx)
+	if err != nil {
+		logger.Printf("Error querying records by %s: %v\n", fieldName, err)
+		return "[]", nil
+	}
+	err = view.checkQueryField(fieldName)
+	if err != nil {
+		logger.Printf("Error querying records by %s: %v\n", fieldName, err)
+		return "[]", nil
+	}
+
+	privacyConfig, err := loadPrivacyConfig(ctx)
+	if err != nil {
+		logger.Printf("Error querying records by %s: %v\n", fieldName, err)
+		return "[]", nil
+	}
+
+	selector := map[string]interface{}{
+		"docType": "proofRecord",
+		fieldName: parsedValue,
+	}
+
+	// Private fields are looked up in the private data collection
+	isPrivate := isPrivateField(privacyConfig.PrivateFields, fieldName)
+	if isPrivate {
+		recordIDs, err := queryKeysByPrivateField(ctx, "proofRecordPrivate", fieldName, parsedValue)
+		if err != nil {
+			logger.Printf("Error querying records by %s: %v\n", fieldName, err)
+			return "[]", nil
+		}
+		delete(selector, fieldName)
+		selector["recordId"] = map[string]interface{}{
+			"$in": recordIDs,
+		}
+	}
+	access.restrictSelector(selector)
+
+	query := map[string]interface{}{
+		"selector": selector,
+	}
+
+	queryString, err := json.Marshal(query)
+	if err != nil {
+		logger.Printf("Error querying records by %s: %v\n", fieldName, err)
+		return "[]", nil
+	}
+
+	logger.Printf("Query: %s\n", string(queryString))
+
+	resultsIterator, err := ctx.GetStub().GetQueryResult(string(queryString))
+	if err != nil {
+		logger.Printf("Error querying records by %s: %v\n", fieldName, err)
+		return "[]", nil
+	}
+	defer resultsIterator.Close()
+
+	results, err := getAllResults(resultsIterator)
+	if err != nil {
+		logger.Printf("Error querying records by %s: %v\n", fieldName, err)
+		return "[]", nil
+	}
+
+	if isPrivate {
+		results, err = filterPrivateReadable(ctx, results)
+		if err != nil {
+			logger.Printf("Error querying records by %s: %v\n", fieldName, err)
+			return "[]", nil
+		}
+	}
+	err = mergePrivateDetails(ctx, results)
+	if err != nil {
+		logger.Printf("Error querying records by %s: %v\n", fieldName, err)
+		return "[]", nil
+	}
+	err = newReferenceResolver(ctx).resolveResults(results)
+	if err != nil {
+		logger.Printf("Error querying records by %s: %v\n", fieldName, err)
+		return "[]", nil
+	}
+	view.redactResults(results)
+
+	resultsJSON, err := json.Marshal(results)
+	if err != nil {
+		logger.Printf("Error querying records by %s: %v\n", fieldName, err)
+		return "[]", nil
+	}
+
+	logger.Println("============= END : Query Records By Field ===========")
+	return string(resultsJSON), nil
+}
+
+// QueryTicket queries a ticket by key
+func (qu *QueryUtils) QueryTicket(ctx contractapi.TransactionContextInterface, ticketKey string) (string, error) {
+	ticketAsBytes, err := ctx.GetStub().GetState(ticketKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if ticketAsBytes == nil || len(ticketAsBytes) == 0 {
+		return "", fmt.Errorf("Ticket %s does not exist", ticketKey)
+	}
+
+	visible, err := qu.isVisible(ctx, ticketAsBytes)
+	if err != nil {
+		return "", err
+	}
+	if !visible {
+		return "", fmt.Errorf("Ticket %s does not exist", ticketKey)
+	}
+
+	var ticket map[string]interface{}
+	err = json.Unmarshal(ticketAsBytes, &ticket)
+	if err != nil {
+		return string(ticketAsBytes), nil
+	}
+
+	privacyConfig, err := loadPrivacyConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+	err = mergeDocumentPrivateDetails(ctx, privacyConfig, ticket)
+	if err != nil {
+		return "", err
+	}
+
+	view, err := getRedactionView(ctx)
+	if err != nil {
+		return "", err
+	}
+	view.redactDocument(ticket)
+
+	ticketJSON, err := json.Marshal(ticket)
+	if err != nil {
+		return "", err
+	}
+	return string(ticketJSON), nil
+}
+
+// QueryAllTickets queries all tickets
+func (qu *QueryUtils) QueryAllTickets(ctx contractapi.TransactionContextInterface) (string, error) {
+	access, err := getTenantAccess(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	selector := map[string]interface{}{
+		"docType": "ticket",
+	}
+	access.restrictSelector(selector)
+
+	query := map[string]interface{}{
+		"selector": selector,
+	}
+
+	queryString, err := json.Marshal(query)
+	if err != nil {
+		return "", err
+	}
+
+	resultsIterator, err := ctx.GetStub().GetQueryResult(string(queryString))
+	if err != nil {
+		return "", err
+	}
+	defer resultsIterator.Close()
+
+	allResults, err := getAllResults(resultsIterator)
+	if err != nil {
+		return "", err
+	}
+
+	err = mergePrivateDetails(ctx, allResults)
+	if err != nil {
+		return "", err
+	}
+
+	view, err := getRedactionView(ctx)
+	if err != nil {
+		return "", err
+	}
+	view.redactResults(allResults)
+
+	resultsJSON, err := json.Marshal(allResults)
+	if err != nil {
+		return "", err
+	}
+
+	return string(resultsJSON), nil
+}
+
+// QueryTicketsByField queries tickets by a specific field
+func (qu *QueryUtils) QueryTicketsByField(ctx contractapi.TransactionContextInterface, fieldName string, fieldValue string) (string, error) {
+	logger.Printf("============= START : Query Tickets By Field %s ===========\n", fieldName)
+
+	var parsedValue interface{} = fieldValue
+
+	if fieldName == "incrementId" || fieldName == "receivedWeight" {
+		floatVal, err := strconv.ParseFloat(fieldValue, 64)
+		if err != nil {
+			logger.Printf("Error querying tickets by %s: Invalid %s: %s is not a number\n", fieldName, fieldName, fieldValue)
+			return "[]", nil
+		}
+		parsedValue = floatVal
+	}
+
+	access, err := getTenantAccess(ctx)
+	if err != nil {
+		logger.Printf("Error querying tickets by %s: %v\n", fieldName, err)
+		return "[]", nil
+	}
+
+	// Filtering on a redacted field would reveal its values
+	view, err := getRedactionView(ctx)
+	if err != nil {
+		logger.Printf("Error querying tickets by %s: %v\n", fieldName, err)
+		return "[]", nil
+	}
+	err = view.checkQueryField(fieldName)
+	if err != nil {
+		logger.Printf("Error querying tickets by %s: %v\n", fieldName, err)
+		return "[]", nil
+	}
+
+	privacyConfig, err := loadPrivacyConfig(ctx)
+	if err != nil {
+		logger.Printf("Error querying tickets by %s: %v\n", fieldName, err)
+		return "[]", nil
+	}
+
+	selector := map[string]interface{}{
+		"docType": "ticket",
+		fieldName: parsedValue,
+	}
+
+	// Private fields are looked up in the private data collection
+	isPrivate := isPrivateField(privacyConfig.TicketPrivateFields, fieldName)
+	if isPrivate {
+		ticketKeys, err := queryKeysByPrivateField(ctx, "ticketPrivate", fieldName, parsedValue)
+		if err != nil {
+			logger.Printf("Error querying tickets by %s: %v\n", fieldName, err)
+			return "[]", nil
+		}
+		delete(selector, fieldName)
+		selector["_id"] = map[string]interface{}{
+			"$in": ticketKeys,
+		}
+	}
+	access.restrictSelector(selector)
+
+	query := map[string]interface{}{
+		"selector": selector,
+	}
+
+	queryString, err := json.Marshal(query)
+	if err != nil {
+		logger.Printf("Error querying tickets by %s: %v\n", fieldName, err)
+		return "[]", nil
+	}
+
+	logger.Printf("Query: %s\n", string(queryString))
+
+	resultsIterator, err := ctx.GetStub().GetQueryResult(string(queryString))
+	if err != nil {
+		logger.Printf("Error querying tickets by %s: %v\n", fieldName, err)
+		return "[]", nil
+	}
+	defer resultsIterator.Close()
+
+	results, err := getAllResults(resultsIterator)
+	if err != nil {
+		logger.Printf("Error querying tickets by %s: %v\n", fieldName, err)
+		return "[]", nil
+	}
+
+	if isPrivate {
+		results, err = filterPrivateReadable(ctx, results)
+		if err != nil {
+			logger.Printf("Error querying tickets by %s: %v\n", fieldName, err)
+			return "[]", nil
+		}
+	}
+	err = mergePrivateDetails(ctx, results)
+	if err != nil {
+		logger.Printf("Error querying tickets by %s: %v\n", fieldName, err)
+		return "[]", nil
+	}
+	view.redactResults(results)
+
+	resultsJSON, err := json.Marshal(results)
+	if err != nil {
+		logger.Printf("Error querying tickets by %s: %v\n", fieldName, err)
+		return "[]", nil
+	}
+
+	logger.Println("============= END : Query Tickets By Field ===========")
+	return string(resultsJSON), nil
+}
+
+// GetRecordHistory gets the history of a record
+func (qu *QueryUtils) GetRecordHistory(ctx contractapi.TransactionContextInterface, recordId string) (string, error) {
+	resultsIterator, err := ctx.GetStub().GetHistoryForKey(recordId)
+	if err != nil {
+		return "", err
+	}
+	defer resultsIterator.Close()
+
+	results, err := getAllHistoryResults(resultsIterator)
+	if err != nil {
+		return "", err
+	}
+
+	access, err := getTenantAccess(ctx)
+	if err != nil {
+		return "", err
+	}
+	results = access.filterDocuments(results)
+
+	view, err := getRedactionView(ctx)
+	if err != nil {
+		return "", err
+	}
+	view.redactResults(results)
+
+	resultsJSON, err := json.Marshal(results)
+	if err != nil {
+		return "", err
+	}
+
+	return string(resultsJSON), nil
+}
+
+// isVisible reports whether the caller's sponsors allow it to see the document
+func (qu *QueryUtils) isVisible(ctx contractapi.TransactionContextInterface, documentAsBytes []byte) (bool, error) {
+	var document map[string]interface{}
+	err := json.Unmarshal(documentAsBytes, &document)
+	if err != nil {
+		return true, nil
+	}
+
+	access, err := getTenantAccess(ctx)
+	if err != nil {
+		return false, err
+	}
+	return access.CanSeeDocument(document), nil
+}
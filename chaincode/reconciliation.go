@@ -0,0 +1,158 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Reconciliation statuses of an increment group
+const (
+	StatusBalanced      = "balanced"
+	StatusOver          = "over"
+	StatusUnder         = "under"
+	StatusMissingTicket = "missing-ticket"
+	StatusMissingProof  = "missing-proof"
+)
+
+// ReconciliationGroup represents the reconciliation state of a single increment
+type ReconciliationGroup struct {
+	SponsorID      string   `json:"sponsor_id,omitempty"`
+	IncrementID    int      `json:"incrementId"`
+	Status         string   `json:"status"`
+	ChainedWeight  float64  `json:"chainedWeight"`
+	ReceivedWeight *float64 `json:"receivedWeight"`
+	Difference     *float64 `json:"difference"`
+	RecordCount    int      `json:"recordCount"`
+	TicketCount    int      `json:"ticketCount"`
+	RecordIDs      []string `json:"recordIds"`
+	TicketKeys     []string `json:"ticketKeys"`
+}
+
+// ReconciliationResponse represents the detailed reconciliation of every increment group
+type ReconciliationResponse struct {
+	Success     bool                  `json:"success"`
+	Aggregation string                `json:"aggregation,omitempty"`
+	Tolerance   float64               `json:"tolerance"`
+	Scope       *ComparisonScope      `json:"scope,omitempty"`
+	CrossTenant bool                  `json:"crossTenant"`
+	Summary     map[string]int        `json:"summary"`
+	Groups      []ReconciliationGroup `json:"groups"`
+	Message     string                `json:"message,omitempty"`
+}
+
+// ReconcileByPressIncrement classifies every press increment group
+func (wc *WeightComparison) ReconcileByPressIncrement(ctx contractapi.TransactionContextInterface, options string) (string, error) {
+	logger.Println("============= START : Reconcile By Press Increment ===========")
+	response := wc.reconcile(ctx, "press_increment", options)
+	logger.Println("============= END : Reconcile By Press Increment ===========")
+
+	responseJSON, _ := json.Marshal(response)
+	return string(responseJSON), nil
+}
+
+// ReconcileByStoreIncrement classifies every store increment group
+func (wc *WeightComparison) ReconcileByStoreIncrement(ctx contractapi.TransactionContextInterface, options string) (string, error) {
+	logger.Println("============= START : Reconcile By Store Increment ===========")
+	response := wc.reconcile(ctx, "store_increment", options)
+	logger.Println("============= END : Reconcile By Store Increment ===========")
+
+	responseJSON, _ := json.Marshal(response)
+	return string(responseJSON), nil
+}
+
+func (wc *WeightComparison) reconcile(ctx contractapi.TransactionContextInterface, incrementField string, options string) *ReconciliationResponse {
+	comparisonOptions, err := wc.parseOptions(ctx, options)
+	if err != nil {
+		return reconciliationErrorResponse(err)
+	}
+
+	access, err := getTenantAccess(ctx)
+	if err != nil {
+		return reconciliationErrorResponse(err)
+	}
+	groupedResults, err := wc.groupByIncrement(ctx, incrementField, comparisonOptions, access)
+	if err != nil {
+		return reconciliationErrorResponse(err)
+	}
+
+	groups := []ReconciliationGroup{}
+	summary := map[string]int{
+		StatusBalanced:      0,
+		StatusOver:          0,
+		StatusUnder:         0,
+		StatusMissingTicket: 0,
+		StatusMissingProof:  0,
+	}
+
+	for _, groupKey := range sortedGroupKeys(groupedResults) {
+		group := groupedResults[groupKey]
+		receivedWeight := aggregateTickets(group.Tickets, comparisonOptions.Aggregation)
+		status := classifyGroup(group, receivedWeight, comparisonOptions.Tolerance)
+		summary[status]++
+
+		ticketKeys := []string{}
+		for _, ticket := range group.Tickets {
+			ticketKeys = append(ticketKeys, ticket.TicketKey)
+		}
+
+		reconciliationGroup := ReconciliationGroup{
+			SponsorID:     groupKey.SponsorID,
+			IncrementID:   int(groupKey.IncrementID),
+			Status:        status,
+			ChainedWeight: math.Round(group.ChainedWeightSum*100) / 100,
+			RecordCount:   len(group.RecordIDs),
+			TicketCount:   group.TicketCount,
+			RecordIDs:     group.RecordIDs,
+			TicketKeys:    ticketKeys,
+		}
+		if receivedWeight != nil {
+			rounded := math.Round(*receivedWeight*100) / 100
+			difference := math.Round((group.ChainedWeightSum-*receivedWeight)*100) / 100
+			reconciliationGroup.ReceivedWeight = &rounded
+			reconciliationGroup.Difference = &difference
+		}
+
+		groups = append(groups, reconciliationGroup)
+	}
+
+	return &ReconciliationResponse{
+		Success:     true,
+		Aggregation: comparisonOptions.Aggregation,
+		Tolerance:   comparisonOptions.Tolerance,
+		Scope:       comparisonOptions.Scope,
+		CrossTenant: comparisonOptions.CrossTenant,
+		Summary:     summary,
+		Groups:      groups,
+	}
+}
+
+// classifyGroup determines the reconciliation status of a group given its aggregated received weight
+func classifyGroup(group *GroupData, receivedWeight *float64, tolerance float64) string {
+	if len(group.RecordIDs) == 0 {
+		return StatusMissingProof
+	}
+	if group.TicketCount == 0 || receivedWeight == nil {
+		return StatusMissingTicket
+	}
+
+	difference := group.ChainedWeightSum - *receivedWeight
+	if math.Abs(difference) <= tolerance {
+		return StatusBalanced
+	}
+	if difference > 0 {
+		return StatusOver
+	}
+	return StatusUnder
+}
+
+func reconciliationErrorResponse(err error) *ReconciliationResponse {
+	return &ReconciliationResponse{
+		Success: false,
+		Message: fmt.Sprintf("Error reconciling weights: %v", err),
+		Summary: map[string]int{},
+		Groups:  []ReconciliationGroup{},
+	}
+}
@@ -0,0 +1,33 @@
+package chaincode
+
+import "testing"
+
+func TestClassifyGroup(t *testing.T) {
+	weight := func(value float64) *float64 { return &value }
+	tests := []struct {
+		name     string
+		group    GroupData
+		received *float64
+		want     string
+	}{
+		{"balanced", GroupData{ChainedWeightSum: 10, TicketCount: 1, RecordIDs: []string{"R1"}}, weight(10), StatusBalanced},
+		{"within tolerance", GroupData{ChainedWeightSum: 10.5, TicketCount: 1, RecordIDs: []string{"R1"}}, weight(10), StatusBalanced},
+		{"over", GroupData{ChainedWeightSum: 12, TicketCount: 1, RecordIDs: []string{"R1"}}, weight(10), StatusOver},
+		{"under", GroupData{ChainedWeightSum: 8, TicketCount: 1, RecordIDs: []string{"R1"}}, weight(10), StatusUnder},
+		{"orphan record", GroupData{ChainedWeightSum: 8, RecordIDs: []string{"R1"}}, nil, StatusMissingTicket},
+		{"orphan ticket", GroupData{TicketCount: 1}, weight(10), StatusMissingProof},
+	}
+
+	for _, test := range tests {
+		if got := classifyGroup(&test.group, test.received, 1); got != test.want {
+			t.Errorf("%s: status = %s, want %s", test.name, got, test.want)
+		}
+	}
+}
+
+func TestParseOptionsRejectsNegativeTolerance(t *testing.T) {
+	ctx := newTestContext(t)
+	if _, err := NewWeightComparison().parseOptions(ctx, `{"tolerance":-1}`); err == nil {
+		t.Errorf("negative tolerance was accepted")
+	}
+}
@@ -0,0 +1,143 @@
+package chaincode
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// RedactionAllFields in a profile's include list makes every field visible
+const RedactionAllFields = "*"
+
+// RedactionProfile lists the top-level document fields visible to a role
+type RedactionProfile struct {
+	Include []string `json:"include"`
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// RedactionView is the combination of the redaction profiles of the caller's roles
+type RedactionView struct {
+	Profiles []RedactionProfile
+}
+
+// defaultRedactionProfiles maps roles to the fields they see. Roles without a profile
+// use the profile of RoleAny, which shows the public traceability fields.
+func defaultRedactionProfiles() map[string]RedactionProfile {
+	all := RedactionProfile{Include: []string{RedactionAllFields}}
+	public := RedactionProfile{Include: []string{
+		"recordId",
+		"sponsor_id",
+		"proof_short_id",
+		"bulk_short_id",
+		"bulk_name",
+		"parent_increment",
+		"chained_weight",
+		"traceChainType",
+		"store_increment",
+		"press_increment",
+		"id",
+		"incrementId",
+		"receivedWeight",
+		"commitment",
+		"createdAt",
+		"docType",
+	}}
+
+	return map[string]RedactionProfile{
+		RoleAdmin:       all,
+		RoleAuditor:     all,
+		RoleVerifier:    all,
+		RoleCollector:   all,
+		RoleWeighbridge: all,
+		RolePublic:      public,
+		RoleAny:         public,
+	}
+}
+
+// getRedactionView returns the redaction profiles applying to the caller
+func getRedactionView(ctx contractapi.TransactionContextInterface) (*RedactionView, error) {
+	config, err := loadRedactionConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := getCallerRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	view := &RedactionView{Profiles: []RedactionProfile{}}
+	for _, role := range roles {
+		if profile, exists := config.Profiles[role]; exists {
+			view.Profiles = append(view.Profiles, profile)
+		}
+	}
+	if len(view.Profiles) == 0 {
+		if profile, exists := config.Profiles[RoleAny]; exists {
+			view.Profiles = append(view.Profiles, profile)
+		}
+	}
+	return view, nil
+}
+
+// CanSeeField reports whether any of the caller's profiles shows the field. Dotted
+// fields are checked against their top-level field.
+func (rv *RedactionView) CanSeeField(field string) bool {
+	field = strings.SplitN(field, ".", 2)[0]
+	for _, profile := range rv.Profiles {
+		if containsString(profile.Exclude, field) {
+			continue
+		}
+		if containsString(profile.Include, RedactionAllFields) || containsString(profile.Include, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactDocument removes the fields the caller may not see from a document and from the
+// entities under its references field
+func (rv *RedactionView) redactDocument(document map[string]interface{}) {
+	for field := range document {
+		if !rv.CanSeeField(field) {
+			delete(document, field)
+		}
+	}
+
+	references, ok := document["references"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	for _, reference := range references {
+		if entity, ok := reference.(map[string]interface{}); ok {
+			rv.redactDocument(entity)
+		}
+	}
+}
+
+// redactResults removes the fields the caller may not see from the documents of query results
+func (rv *RedactionView) redactResults(results []map[string]interface{}) {
+	for _, result := range results {
+		if document, ok := result["Record"].(map[string]interface{}); ok {
+			rv.redactDocument(document)
+		}
+	}
+}
+
+// checkQueryField returns an error when the caller filters on a field it may not see
+func (rv *RedactionView) checkQueryField(field string) error {
+	if !rv.CanSeeField(field) {
+		return fmt.Errorf("authorization denied: field %s is redacted for the caller", field)
+	}
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,164 @@
+package chaincode
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Rejection strategies selecting which records of an over-weight group are removed
+const (
+	RejectionAll           = "all"
+	RejectionRecentFirst   = "recent_first"
+	RejectionLargestFirst  = "largest_first"
+	RejectionMinimalSubset = "minimal_subset"
+)
+
+// maxMinimalSubsetRecords bounds the exhaustive search of the minimal_subset strategy
+const maxMinimalSubsetRecords = 20
+
+// weightEpsilon absorbs floating point noise when comparing summed weights
+const weightEpsilon = 1e-9
+
+// RecordWeight represents the weight contributed by a single proof record
+type RecordWeight struct {
+	RecordID      string
+	ChainedWeight float64
+	CreatedAt     string
+}
+
+// RecordRejection explains why a record was selected for removal
+type RecordRejection struct {
+	RecordID      string  `json:"recordId"`
+	IncrementID   int     `json:"incrementId"`
+	ChainedWeight float64 `json:"chainedWeight"`
+	Reason        string  `json:"reason"`
+}
+
+func isValidRejection(rejection string) bool {
+	switch rejection {
+	case RejectionAll, RejectionRecentFirst, RejectionLargestFirst, RejectionMinimalSubset:
+		return true
+	}
+	return false
+}
+
+// selectRejections picks the records of an over-weight group to remove so that the
+// remaining chained weight is within tolerance of the received weight
+func selectRejections(incrementID int, group *GroupData, receivedWeight float64, tolerance float64, strategy string) []RecordRejection {
+	excess := group.ChainedWeightSum - (receivedWeight + tolerance)
+
+	switch strategy {
+	case RejectionRecentFirst:
+		records := append([]RecordWeight{}, group.Records...)
+		sort.SliceStable(records, func(i, j int) bool {
+			if records[i].CreatedAt != records[j].CreatedAt {
+				return records[i].CreatedAt > records[j].CreatedAt
+			}
+			return records[i].RecordID > records[j].RecordID
+		})
+		return rejectUntilBalanced(incrementID, group, records, excess, "most recent record (createdAt %s)")
+	case RejectionLargestFirst:
+		return rejectUntilBalanced(incrementID, group, largestFirst(group.Records), excess, "largest remaining record (createdAt %s)")
+	case RejectionMinimalSubset:
+		if len(group.Records) > maxMinimalSubsetRecords {
+			reason := fmt.Sprintf("group has more than %d records, largest record (createdAt %%s) selected instead of a minimal subset", maxMinimalSubsetRecords)
+			return rejectUntilBalanced(incrementID, group, largestFirst(group.Records), excess, reason)
+		}
+		return rejectMinimalSubset(incrementID, group, excess)
+	default:
+		rejections := []RecordRejection{}
+		for _, record := range group.Records {
+			rejections = append(rejections, RecordRejection{
+				RecordID:      record.RecordID,
+				IncrementID:   incrementID,
+				ChainedWeight: record.ChainedWeight,
+				Reason: fmt.Sprintf("all records rejected: group chained weight %.2f exceeds received weight %.2f",
+					group.ChainedWeightSum, receivedWeight),
+			})
+		}
+		return rejections
+	}
+}
+
+// rejectUntilBalanced removes records in the given order until the excess is absorbed
+func rejectUntilBalanced(incrementID int, group *GroupData, records []RecordWeight, excess float64, reason string) []RecordRejection {
+	rejections := []RecordRejection{}
+	remaining := group.ChainedWeightSum
+	removed := 0.0
+
+	for _, record := range records {
+		if removed >= excess-weightEpsilon {
+			break
+		}
+		removed += record.ChainedWeight
+		remaining -= record.ChainedWeight
+		rejections = append(rejections, RecordRejection{
+			RecordID:      record.RecordID,
+			IncrementID:   incrementID,
+			ChainedWeight: record.ChainedWeight,
+			Reason: fmt.Sprintf(reason+"; remaining chained weight %.2f",
+				record.CreatedAt, math.Round(remaining*100)/100),
+		})
+	}
+	return rejections
+}
+
+// rejectMinimalSubset removes the smallest set of records whose removal absorbs the
+// excess, preferring the set that removes the least weight
+func rejectMinimalSubset(incrementID int, group *GroupData, excess float64) []RecordRejection {
+	records := largestFirst(group.Records)
+
+	// Removing the largest records first gives the minimal number of records needed
+	size := 0
+	removed := 0.0
+	for size < len(records) && removed < excess-weightEpsilon {
+		removed += records[size].ChainedWeight
+		size++
+	}
+
+	var best []int
+	bestWeight := math.Inf(1)
+	combination := make([]int, 0, size)
+	var search func(start int, weight float64)
+	search = func(start int, weight float64) {
+		if len(combination) == size {
+			if weight >= excess-weightEpsilon && weight < bestWeight {
+				bestWeight = weight
+				best = append([]int{}, combination...)
+			}
+			return
+		}
+		for i := start; i <= len(records)-(size-len(combination)); i++ {
+			combination = append(combination, i)
+			search(i+1, weight+records[i].ChainedWeight)
+			combination = combination[:len(combination)-1]
+		}
+	}
+	search(0, 0)
+
+	rejections := []RecordRejection{}
+	remaining := math.Round((group.ChainedWeightSum-bestWeight)*100) / 100
+	for _, i := range best {
+		rejections = append(rejections, RecordRejection{
+			RecordID:      records[i].RecordID,
+			IncrementID:   incrementID,
+			ChainedWeight: records[i].ChainedWeight,
+			Reason: fmt.Sprintf("part of the minimal subset of %d record(s) removing %.2f to cover an excess of %.2f; remaining chained weight %.2f",
+				size, bestWeight, excess, remaining),
+		})
+	}
+	return rejections
+}
+
+// largestFirst returns the records ordered by descending chained weight
+func largestFirst(records []RecordWeight) []RecordWeight {
+	sorted := append([]RecordWeight{}, records...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].ChainedWeight != sorted[j].ChainedWeight {
+			return sorted[i].ChainedWeight > sorted[j].ChainedWeight
+		}
+		return sorted[i].RecordID < sorted[j].RecordID
+	})
+	return sorted
+}
@@ -0,0 +1,73 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSelectRejections(t *testing.T) {
+	group := &GroupData{
+		ChainedWeightSum: 16,
+		RecordIDs:        []string{"R1", "R2", "R3"},
+		Records: []RecordWeight{
+			{RecordID: "R1", ChainedWeight: 5, CreatedAt: "2026-10-18T10:00:00Z"},
+			{RecordID: "R2", ChainedWeight: 8, CreatedAt: "2026-10-18T11:00:00Z"},
+			{RecordID: "R3", ChainedWeight: 3, CreatedAt: "2026-10-18T12:00:00Z"},
+		},
+	}
+	// The group exceeds its received weight of 12 by 4
+	tests := map[string][]string{
+		RejectionAll:           {"R1", "R2", "R3"},
+		RejectionRecentFirst:   {"R3", "R2"},
+		RejectionLargestFirst:  {"R2"},
+		RejectionMinimalSubset: {"R1"},
+	}
+
+	for strategy, want := range tests {
+		got := []string{}
+		for _, rejection := range selectRejections(1, group, 12, 0, strategy) {
+			got = append(got, rejection.RecordID)
+			if rejection.IncrementID != 1 || rejection.Reason == "" {
+				t.Errorf("%s: incomplete rejection %+v", strategy, rejection)
+			}
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s rejects %v, want %v", strategy, got, want)
+		}
+	}
+}
+
+func TestSelectRejectionsWithinTolerance(t *testing.T) {
+	group := &GroupData{
+		ChainedWeightSum: 16,
+		Records: []RecordWeight{
+			{RecordID: "R1", ChainedWeight: 5},
+			{RecordID: "R2", ChainedWeight: 8},
+			{RecordID: "R3", ChainedWeight: 3},
+		},
+	}
+	// A tolerance of 1 leaves an excess of 3, which R3 alone covers
+	rejections := selectRejections(1, group, 12, 1, RejectionMinimalSubset)
+	if len(rejections) != 1 || rejections[0].RecordID != "R3" {
+		t.Errorf("minimal subset within tolerance = %+v, want R3", rejections)
+	}
+}
+
+func TestSetComparisonConfigRejectsUnknownRejection(t *testing.T) {
+	ctx := newTestContext(t)
+	if _, err := NewConfigManager().SetComparisonConfig(ctx, `{"rejection":"oldest_first"}`); err == nil {
+		t.Errorf("config with an unknown rejection strategy was accepted")
+	}
+	configJSON, err := NewConfigManager().QueryComparisonConfig(ctx)
+	if err != nil {
+		t.Fatalf("QueryComparisonConfig failed: %v", err)
+	}
+	var config ComparisonConfig
+	if err := json.Unmarshal([]byte(configJSON), &config); err != nil {
+		t.Fatalf("invalid config %s: %v", configJSON, err)
+	}
+	if config.Rejection != RejectionAll {
+		t.Errorf("default rejection = %s, want %s", config.Rejection, RejectionAll)
+	}
+}
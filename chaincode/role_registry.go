@@ -0,0 +1,242 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// RoleAssignment represents the roles and sponsors granted to an identity in the on-ledger registry.
+// The identity is either a client ID as returned by GetID or "<MSP ID>::<certificate subject>".
+type RoleAssignment struct {
+	Identity  string   `json:"identity"`
+	Roles     []string `json:"roles"`
+	Sponsors  []string `json:"sponsors"`
+	UpdatedAt string   `json:"updatedAt"`
+	UpdatedBy string   `json:"updatedBy"`
+	DocType   string   `json:"docType"`
+}
+
+// RoleGrant represents the data of a GrantRole call
+type RoleGrant struct {
+	Identity string   `json:"identity"`
+	Role     string   `json:"role"`
+	Sponsors []string `json:"sponsors"`
+}
+
+// RoleRegistry handles the on-ledger role and membership registry
+type RoleRegistry struct{}
+
+// NewRoleRegistry creates a new RoleRegistry instance
+func NewRoleRegistry() *RoleRegistry {
+	return &RoleRegistry{}
+}
+
+// GrantRole grants a role, and optionally sponsors, to an identity
+func (rr *RoleRegistry) GrantRole(ctx contractapi.TransactionContextInterface, grantData string) (string, error) {
+	var grant RoleGrant
+	err := json.Unmarshal([]byte(grantData), &grant)
+	if err != nil {
+		return "", fmt.Errorf("invalid role grant: %v", err)
+	}
+	if grant.Identity == "" || (grant.Role == "" && len(grant.Sponsors) == 0) {
+		return "", fmt.Errorf("invalid role grant: identity and a role or sponsors are required")
+	}
+
+	assignment, err := getRoleAssignment(ctx, grant.Identity)
+	if err != nil {
+		return "", err
+	}
+	if assignment == nil {
+		assignment = &RoleAssignment{
+			Identity: grant.Identity,
+			Roles:    []string{},
+			Sponsors: []string{},
+		}
+	}
+
+	if grant.Role != "" {
+		assignment.Roles = addUnique(assignment.Roles, grant.Role)
+	}
+	for _, sponsorID := range grant.Sponsors {
+		assignment.Sponsors = addUnique(assignment.Sponsors, sponsorID)
+	}
+
+	return rr.putRoleAssignment(ctx, assignment)
+}
+
+// RevokeRole revokes a role from an identity, removing the assignment once it holds no roles or sponsors
+func (rr *RoleRegistry) RevokeRole(ctx contractapi.TransactionContextInterface, identity string, role string) (string, error) {
+	assignment, err := getRoleAssignment(ctx, identity)
+	if err != nil {
+		return "", err
+	}
+	if assignment == nil {
+		return "", fmt.Errorf("Role assignment for %s does not exist", identity)
+	}
+
+	roles := []string{}
+	for _, assignedRole := range assignment.Roles {
+		if assignedRole != role {
+			roles = append(roles, assignedRole)
+		}
+	}
+	if len(roles) == len(assignment.Roles) {
+		return "", fmt.Errorf("identity %s does not hold role %s", identity, role)
+	}
+	assignment.Roles = roles
+
+	return rr.updateRoleAssignment(ctx, assignment)
+}
+
+// RevokeSponsor revokes a sponsor from an identity, removing the assignment once it holds no roles or sponsors
+func (rr *RoleRegistry) RevokeSponsor(ctx contractapi.TransactionContextInterface, identity string, sponsorID string) (string, error) {
+	assignment, err := getRoleAssignment(ctx, identity)
+	if err != nil {
+		return "", err
+	}
+	if assignment == nil {
+		return "", fmt.Errorf("Role assignment for %s does not exist", identity)
+	}
+
+	sponsors := []string{}
+	for _, assignedSponsor := range assignment.Sponsors {
+		if assignedSponsor != sponsorID {
+			sponsors = append(sponsors, assignedSponsor)
+		}
+	}
+	if len(sponsors) == len(assignment.Sponsors) {
+		return "", fmt.Errorf("identity %s does not hold sponsor %s", identity, sponsorID)
+	}
+	assignment.Sponsors = sponsors
+
+	return rr.updateRoleAssignment(ctx, assignment)
+}
+
+// ListRoleAssignments lists every role assignment in the registry
+func (rr *RoleRegistry) ListRoleAssignments(ctx contractapi.TransactionContextInterface) (string, error) {
+	results, err := queryDocuments(ctx, map[string]interface{}{
+		"docType": "roleAssignment",
+	})
+	if err != nil {
+		return "", err
+	}
+
+	resultsJSON, err := json.Marshal(results)
+	if err != nil {
+		return "", err
+	}
+
+	return string(resultsJSON), nil
+}
+
+// updateRoleAssignment stores an assignment after a revocation, or deletes it once it holds no roles or sponsors
+func (rr *RoleRegistry) updateRoleAssignment(ctx contractapi.TransactionContextInterface, assignment *RoleAssignment) (string, error) {
+	if len(assignment.Roles) == 0 && len(assignment.Sponsors) == 0 {
+		err := ctx.GetStub().DelState(roleAssignmentKey(assignment.Identity))
+		if err != nil {
+			return "", fmt.Errorf("failed to delete role assignment: %v", err)
+		}
+		assignmentJSON, _ := json.Marshal(assignment)
+		return string(assignmentJSON), nil
+	}
+
+	return rr.putRoleAssignment(ctx, assignment)
+}
+
+func (rr *RoleRegistry) putRoleAssignment(ctx contractapi.TransactionContextInterface, assignment *RoleAssignment) (string, error) {
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return "", fmt.Errorf("failed to read client identity: %v", err)
+	}
+
+	sort.Strings(assignment.Roles)
+	sort.Strings(assignment.Sponsors)
+	assignment.UpdatedAt = getTxTimestamp(ctx)
+	assignment.UpdatedBy = clientID
+	assignment.DocType = "roleAssignment"
+
+	assignmentJSON, err := json.Marshal(assignment)
+	if err != nil {
+		return "", err
+	}
+
+	err = ctx.GetStub().PutState(roleAssignmentKey(assignment.Identity), assignmentJSON)
+	if err != nil {
+		return "", fmt.Errorf("failed to put role assignment to world state: %v", err)
+	}
+
+	return string(assignmentJSON), nil
+}
+
+// getCallerAssignments returns the registry assignments matching the caller's client ID or MSP ID and subject
+func getCallerAssignments(ctx contractapi.TransactionContextInterface) ([]*RoleAssignment, error) {
+	identities, err := getCallerIdentities(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	assignments := []*RoleAssignment{}
+	for _, identity := range identities {
+		assignment, err := getRoleAssignment(ctx, identity)
+		if err != nil {
+			return nil, err
+		}
+		if assignment != nil {
+			assignments = append(assignments, assignment)
+		}
+	}
+	return assignments, nil
+}
+
+// getCallerIdentities returns the registry identities under which the caller may be registered
+func getCallerIdentities(ctx contractapi.TransactionContextInterface) ([]string, error) {
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client identity: %v", err)
+	}
+	identities := []string{clientID}
+
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client MSP ID: %v", err)
+	}
+	cert, err := ctx.GetClientIdentity().GetX509Certificate()
+	if err == nil && cert != nil {
+		identities = append(identities, fmt.Sprintf("%s::%s", mspID, cert.Subject.String()))
+	}
+
+	return identities, nil
+}
+
+func getRoleAssignment(ctx contractapi.TransactionContextInterface, identity string) (*RoleAssignment, error) {
+	assignmentAsBytes, err := ctx.GetStub().GetState(roleAssignmentKey(identity))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if len(assignmentAsBytes) == 0 {
+		return nil, nil
+	}
+
+	var assignment RoleAssignment
+	err = json.Unmarshal(assignmentAsBytes, &assignment)
+	if err != nil {
+		return nil, fmt.Errorf("invalid role assignment for %s: %v", identity, err)
+	}
+	return &assignment, nil
+}
+
+func roleAssignmentKey(identity string) string {
+	return fmt.Sprintf("ROLE_%s", identity)
+}
+
+func addUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
@@ -0,0 +1,140 @@
+package chaincode
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// SponsorsAttribute is the X.509 certificate attribute holding the comma separated sponsors of a client
+const SponsorsAttribute = "sponsors"
+
+// TenantAccess describes the sponsors whose data the caller may see
+type TenantAccess struct {
+	All      bool     `json:"all"`
+	Sponsors []string `json:"sponsors"`
+}
+
+// getTenantAccess returns the sponsors visible to the caller. Admins and auditors see
+// every sponsor, as do public callers whose documents are limited by the public redaction
+// profile instead. Other callers see the sponsors from their certificate and registry assignments.
+func getTenantAccess(ctx contractapi.TransactionContextInterface) (*TenantAccess, error) {
+	roles, err := getCallerRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if hasAnyRole(roles, []string{RoleAdmin, RoleAuditor}) {
+		return &TenantAccess{All: true, Sponsors: []string{}}, nil
+	}
+	// A public caller holding another role would see every sponsor with that role's profile
+	if len(roles) == 1 && roles[0] == RolePublic {
+		return &TenantAccess{All: true, Sponsors: []string{}}, nil
+	}
+
+	sponsors := []string{}
+	value, found, err := ctx.GetClientIdentity().GetAttributeValue(SponsorsAttribute)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client identity attributes: %v", err)
+	}
+	if found {
+		for _, sponsorID := range strings.Split(value, ",") {
+			sponsorID = strings.TrimSpace(sponsorID)
+			if sponsorID != "" {
+				sponsors = addUnique(sponsors, sponsorID)
+			}
+		}
+	}
+
+	assignments, err := getCallerAssignments(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, assignment := range assignments {
+		for _, sponsorID := range assignment.Sponsors {
+			sponsors = addUnique(sponsors, sponsorID)
+		}
+	}
+
+	sort.Strings(sponsors)
+	return &TenantAccess{All: false, Sponsors: sponsors}, nil
+}
+
+// CanSee reports whether the caller may see data of the given sponsor
+func (ta *TenantAccess) CanSee(sponsorID string) bool {
+	if ta.All {
+		return true
+	}
+	for _, visible := range ta.Sponsors {
+		if visible == sponsorID {
+			return true
+		}
+	}
+	return false
+}
+
+// CanSeeDocument reports whether the caller may see a document. Documents without
+// a sponsor, such as tickets shared by every sponsor, are visible to everyone.
+func (ta *TenantAccess) CanSeeDocument(document map[string]interface{}) bool {
+	sponsorID, exists := document["sponsor_id"]
+	if !exists || sponsorID == nil {
+		return true
+	}
+	return ta.CanSee(fmt.Sprint(sponsorID))
+}
+
+// restrictSelector limits a CouchDB selector to the documents of visible sponsors
+func (ta *TenantAccess) restrictSelector(selector map[string]interface{}) {
+	if ta.All {
+		return
+	}
+
+	if sponsorID, exists := selector["sponsor_id"]; exists {
+		if _, isCondition := sponsorID.(map[string]interface{}); !isCondition && ta.CanSee(fmt.Sprint(sponsorID)) {
+			return
+		}
+		delete(selector, "sponsor_id")
+		selector["$and"] = []interface{}{
+			map[string]interface{}{"sponsor_id": sponsorID},
+			map[string]interface{}{"sponsor_id": map[string]interface{}{"$in": ta.Sponsors}},
+		}
+		return
+	}
+
+	// Proof records and the collectors and bulks of a sponsor always carry its ID, the
+	// ones stored without it are not shared with every tenant
+	switch selector["docType"] {
+	case "proofRecord", EntityTypeCollector, EntityTypeBulk:
+		selector["sponsor_id"] = map[string]interface{}{"$in": ta.Sponsors}
+		return
+	}
+	selector["$or"] = []interface{}{
+		map[string]interface{}{"sponsor_id": map[string]interface{}{"$exists": false}},
+		map[string]interface{}{"sponsor_id": map[string]interface{}{"$in": ta.Sponsors}},
+	}
+}
+
+// filterDocuments drops the query results of sponsors the caller may not see
+func (ta *TenantAccess) filterDocuments(results []map[string]interface{}) []map[string]interface{} {
+	filtered := []map[string]interface{}{}
+	for _, result := range results {
+		document, ok := result["Record"].(map[string]interface{})
+		if !ok || ta.CanSeeDocument(document) {
+			filtered = append(filtered, result)
+		}
+	}
+	return filtered
+}
+
+// checkSponsorAccess returns an authorization error when the caller may not see the sponsor
+func checkSponsorAccess(ctx contractapi.TransactionContextInterface, sponsorID string) error {
+	access, err := getTenantAccess(ctx)
+	if err != nil {
+		return err
+	}
+	if !access.CanSee(sponsorID) {
+		return fmt.Errorf("authorization denied: caller may not access sponsor %s", sponsorID)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRestrictSelector(t *testing.T) {
+	access := &TenantAccess{Sponsors: []string{"S1"}}
+	tests := []struct {
+		docType string
+		want    string
+	}{
+		{"proofRecord", `{"docType":"proofRecord","sponsor_id":{"$in":["S1"]}}`},
+		{EntityTypeCollector, `{"docType":"collector","sponsor_id":{"$in":["S1"]}}`},
+		{EntityTypeBulk, `{"docType":"bulk","sponsor_id":{"$in":["S1"]}}`},
+		{"ticket", `{"$or":[{"sponsor_id":{"$exists":false}},{"sponsor_id":{"$in":["S1"]}}],"docType":"ticket"}`},
+	}
+
+	for _, test := range tests {
+		selector := map[string]interface{}{"docType": test.docType}
+		access.restrictSelector(selector)
+		got, err := json.Marshal(selector)
+		if err != nil {
+			t.Fatalf("failed to encode selector: %v", err)
+		}
+		if string(got) != test.want {
+			t.Errorf("%s selector = %s, want %s", test.docType, got, test.want)
+		}
+	}
+}
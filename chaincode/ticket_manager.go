@@ -1,21 +1,26 @@
-package main
+package chaincode
 
 import (
 	"encoding/json"
 	"fmt"
-	"time"
 
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 )
 
 // Ticket represents a ticket record
 type Ticket struct {
-	ID             string  `json:"id"`
-	ReceivedWeight float64 `json:"receivedWeight"`
-	IncrementID    float64 `json:"incrementId"`
-	CreatedAt      string  `json:"createdAt"`
-	CreatedBy      string  `json:"createdBy"`
-	DocType        string  `json:"docType"`
+	ID             string                `json:"id"`
+	ReceivedWeight float64               `json:"receivedWeight"`
+	IncrementID    float64               `json:"incrementId"`
+	SponsorID      string                `json:"sponsor_id,omitempty"`
+	CreatedAt      string                `json:"createdAt"`
+	CreatedBy      string                `json:"createdBy"`
+	Creator        *CreatorInfo          `json:"creator,omitempty"`
+	OwnerMSP       string                `json:"ownerMSP"`
+	DelegatedMSPs  []string              `json:"delegatedMSPs"`
+	PrivateData    *PrivateDataReference `json:"privateData,omitempty"`
+	Erasure        *PersonalDataErasure  `json:"personalDataErased,omitempty"`
+	DocType        string                `json:"docType"`
 }
 
 // TicketManager handles ticket operations
@@ -38,7 +43,7 @@ type CreateTicketResponse struct {
 
 // CreateTicket creates a new ticket
 func (tm *TicketManager) CreateTicket(ctx contractapi.TransactionContextInterface, ticketData string) (string, error) {
-	fmt.Println("============= START : Create Ticket ===========")
+	logger.Println("============= START : Create Ticket ===========")
 
 	var ticket map[string]interface{}
 	err := json.Unmarshal([]byte(ticketData), &ticket)
@@ -60,6 +65,18 @@ func (tm *TicketManager) CreateTicket(ctx contractapi.TransactionContextInterfac
 		return string(responseJSON), nil
 	}
 
+	if sponsorID, exists := ticket["sponsor_id"]; exists && sponsorID != nil {
+		err = checkSponsorAccess(ctx, fmt.Sprint(sponsorID))
+		if err != nil {
+			response := CreateTicketResponse{
+				Success: false,
+				Message: fmt.Sprintf("Error creating ticket: %v", err),
+			}
+			responseJSON, _ := json.Marshal(response)
+			return string(responseJSON), nil
+		}
+	}
+
 	duplicateCheckResult, err := tm.checkTicketForDuplicates(ctx, ticket)
 	if err != nil {
 		response := CreateTicketResponse{
@@ -81,19 +98,22 @@ func (tm *TicketManager) CreateTicket(ctx contractapi.TransactionContextInterfac
 		return string(responseJSON), nil
 	}
 
-	ticketID := ticket["id"].(string)
-	ticketKey := fmt.Sprintf("TICKET_%s", ticketID)
-
-	clientID, err := ctx.GetClientIdentity().GetID()
+	privacyConfig, err := loadPrivacyConfig(ctx)
 	if err != nil {
-		clientID = ""
+		response := CreateTicketResponse{
+			Success: false,
+			Message: fmt.Sprintf("Error creating ticket: %v", err),
+		}
+		responseJSON, _ := json.Marshal(response)
+		return string(responseJSON), nil
 	}
+	privateDetails := splitPrivateFields(ticket, privacyConfig.TicketPrivateFields)
 
-	ticket["createdAt"] = time.Now().UTC().Format(time.RFC3339)
-	ticket["createdBy"] = clientID
-	ticket["docType"] = "ticket"
+	ticketID := ticket["id"].(string)
+	ticketKey := fmt.Sprintf("TICKET_%s", ticketID)
 
-	ticketJSON, err := json.Marshal(ticket)
+	// The key only holds the ticket ID, a ticket of another increment must not be overwritten
+	existingTicket, err := ctx.GetStub().GetState(ticketKey)
 	if err != nil {
 		response := CreateTicketResponse{
 			Success: false,
@@ -102,8 +122,16 @@ func (tm *TicketManager) CreateTicket(ctx contractapi.TransactionContextInterfac
 		responseJSON, _ := json.Marshal(response)
 		return string(responseJSON), nil
 	}
+	if existingTicket != nil {
+		response := CreateTicketResponse{
+			Success: false,
+			Message: fmt.Sprintf("Error creating ticket: ticket %s already exists", ticketID),
+		}
+		responseJSON, _ := json.Marshal(response)
+		return string(responseJSON), nil
+	}
 
-	err = ctx.GetStub().PutState(ticketKey, ticketJSON)
+	creator, err := getCreatorInfo(ctx)
 	if err != nil {
 		response := CreateTicketResponse{
 			Success: false,
@@ -113,11 +141,53 @@ func (tm *TicketManager) CreateTicket(ctx contractapi.TransactionContextInterfac
 		return string(responseJSON), nil
 	}
 
-	fmt.Println("============= END : Create Ticket ===========")
+	ticket["createdAt"] = getTxTimestamp(ctx)
+	ticket["createdBy"] = creator.ID
+	ticket["creator"] = creator
+	ticket["ownerMSP"] = creator.MSPID
+	ticket["delegatedMSPs"] = []string{}
+	ticket["docType"] = "ticket"
+
+	// Nothing was written so far, failures from here on must abort the transaction
+	if len(privateDetails) > 0 {
+		reference, err := putPrivateDetails(ctx, privacyConfig, ticketKey, "ticketPrivate", privateDetails)
+		if err != nil {
+			return "", fmt.Errorf("Error creating ticket: %v", err)
+		}
+		ticket["privateData"] = reference
+	}
+
+	ticketJSON, err := json.Marshal(ticket)
+	if err != nil {
+		return "", fmt.Errorf("Error creating ticket: %v", err)
+	}
+
+	err = ctx.GetStub().PutState(ticketKey, ticketJSON)
+	if err != nil {
+		return "", fmt.Errorf("Error creating ticket: %v", err)
+	}
 
 	var ticketRecord Ticket
 	json.Unmarshal(ticketJSON, &ticketRecord)
 
+	err = emitEvents(ctx, []EventEntry{{
+		Type: EventTicketCreated,
+		Payload: TicketCreatedPayload{
+			TicketKey:      ticketKey,
+			TicketID:       ticketRecord.ID,
+			SponsorID:      ticketRecord.SponsorID,
+			IncrementID:    ticketRecord.IncrementID,
+			ReceivedWeight: ticketRecord.ReceivedWeight,
+			OwnerMSP:       ticketRecord.OwnerMSP,
+			CreatedAt:      ticketRecord.CreatedAt,
+		},
+	}})
+	if err != nil {
+		return "", fmt.Errorf("Error creating ticket: %v", err)
+	}
+
+	logger.Println("============= END : Create Ticket ===========")
+
 	response := CreateTicketResponse{
 		Success:   true,
 		Message:   "Ticket saved successfully",
@@ -128,6 +198,32 @@ func (tm *TicketManager) CreateTicket(ctx contractapi.TransactionContextInterfac
 	return string(responseJSON), nil
 }
 
+// CreateTicketPrivate creates a new ticket from the transient map, keeping its
+// payload out of the transaction arguments
+func (tm *TicketManager) CreateTicketPrivate(ctx contractapi.TransactionContextInterface) (string, error) {
+	ticketData, err := readTransientPayload(ctx, TransientTicketKey)
+	if err != nil {
+		response := CreateTicketResponse{
+			Success: false,
+			Message: fmt.Sprintf("Error creating ticket: %v", err),
+		}
+		responseJSON, _ := json.Marshal(response)
+		return string(responseJSON), nil
+	}
+
+	// The private payload only stays out of the published private data hashes when it is salted
+	_, err = readTransientPayload(ctx, TransientSaltKey)
+	if err != nil {
+		response := CreateTicketResponse{
+			Success: false,
+			Message: fmt.Sprintf("Error creating ticket: a secret salt is required with a private payload: %v", err),
+		}
+		responseJSON, _ := json.Marshal(response)
+		return string(responseJSON), nil
+	}
+	return tm.CreateTicket(ctx, ticketData)
+}
+
 func (tm *TicketManager) validateTicket(ticket map[string]interface{}) bool {
 	requiredFields := []string{
 		"id",
@@ -183,22 +279,29 @@ func (tm *TicketManager) findTicketDuplicatesByFields(ctx contractapi.Transactio
 		}
 	}
 
+	// Tickets of sponsors the caller may not see must not be revealed by the response
+	access, err := getTenantAccess(ctx)
+	if err != nil {
+		return nil, err
+	}
+	access.restrictSelector(selector)
+
 	queryString, err := json.Marshal(query)
 	if err != nil {
-		fmt.Printf("Error finding ticket duplicates: %v\n", err)
+		logger.Printf("Error finding ticket duplicates: %v\n", err)
 		return &TicketDuplicateCheckResult{IsDuplicate: false, ExistingRecords: []map[string]interface{}{}}, nil
 	}
 
 	resultsIterator, err := ctx.GetStub().GetQueryResult(string(queryString))
 	if err != nil {
-		fmt.Printf("Error finding ticket duplicates: %v\n", err)
+		logger.Printf("Error finding ticket duplicates: %v\n", err)
 		return &TicketDuplicateCheckResult{IsDuplicate: false, ExistingRecords: []map[string]interface{}{}}, nil
 	}
 	defer resultsIterator.Close()
 
 	existingTickets, err := getAllResults(resultsIterator)
 	if err != nil {
-		fmt.Printf("Error finding ticket duplicates: %v\n", err)
+		logger.Printf("Error finding ticket duplicates: %v\n", err)
 		return &TicketDuplicateCheckResult{IsDuplicate: false, ExistingRecords: []map[string]interface{}{}}, nil
 	}
 
@@ -1,4 +1,4 @@
-package main
+package chaincode
 
 import (
 	"encoding/json"
@@ -8,14 +8,22 @@ import (
 	"time"
 
 	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 )
 
-// GenerateRecordKey generates a unique key for a record
-func GenerateRecordKey(record map[string]interface{}) string {
-	timestamp := time.Now().UnixNano() / int64(time.Millisecond)
+// RecordKeyObjectType is the object type of the composite keys of proof records
+const RecordKeyObjectType = "PROOF"
+
+// GenerateRecordKey generates a unique composite key for a record, namespaced by its sponsor
+func GenerateRecordKey(ctx contractapi.TransactionContextInterface, record map[string]interface{}) (string, error) {
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return "", fmt.Errorf("failed to read transaction timestamp: %v", err)
+	}
+	timestamp := txTimestamp.Seconds*1000 + int64(txTimestamp.Nanos)/int64(time.Millisecond)
 	recordJSON, _ := json.Marshal(record)
 	hash := simpleHash(string(recordJSON))
-	return fmt.Sprintf("PROOF_%d_%s", timestamp, hash)
+	return ctx.GetStub().CreateCompositeKey(RecordKeyObjectType, []string{fmt.Sprint(record["sponsor_id"]), strconv.FormatInt(timestamp, 10), hash})
 }
 
 // simpleHash generates a simple hash from a string
@@ -39,7 +47,7 @@ func getAllResults(iterator shim.StateQueryIteratorInterface) ([]map[string]inte
 		var record interface{}
 		err = json.Unmarshal(queryResponse.Value, &record)
 		if err != nil {
-			fmt.Printf("Error unmarshaling: %v\n", err)
+			logger.Printf("Error unmarshaling: %v\n", err)
 			record = string(queryResponse.Value)
 		}
 
@@ -66,7 +74,7 @@ func getAllHistoryResults(iterator shim.HistoryQueryIteratorInterface) ([]map[st
 		var record interface{}
 		err = json.Unmarshal(queryResponse.Value, &record)
 		if err != nil {
-			fmt.Printf("Error unmarshaling: %v\n", err)
+			logger.Printf("Error unmarshaling: %v\n", err)
 			record = string(queryResponse.Value)
 		}
 
@@ -79,3 +87,24 @@ func getAllHistoryResults(iterator shim.HistoryQueryIteratorInterface) ([]map[st
 
 	return allResults, nil
 }
+
+// toFloat64 converts a JSON number to float64
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// getTxTimestamp returns the transaction timestamp formatted as RFC3339, which is identical on every endorser
+func getTxTimestamp(ctx contractapi.TransactionContextInterface) string {
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil || txTimestamp == nil {
+		return ""
+	}
+	return time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos)).UTC().Format(time.RFC3339)
+}
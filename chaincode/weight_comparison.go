@@ -0,0 +1,578 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Ticket aggregation rules used when several tickets share an incrementId
+const (
+	AggregationMax     = "max"
+	AggregationSum     = "sum"
+	AggregationLatest  = "latest"
+	AggregationAverage = "average"
+)
+
+// WeightComparison handles weight comparison operations
+type WeightComparison struct{}
+
+// NewWeightComparison creates a new WeightComparison instance
+func NewWeightComparison() *WeightComparison {
+	return &WeightComparison{}
+}
+
+// ComparisonOptions represents the optional settings of a comparison call
+type ComparisonOptions struct {
+	Aggregation string           `json:"aggregation,omitempty"`
+	Tolerance   float64          `json:"tolerance,omitempty"`
+	Rejection   string           `json:"rejection,omitempty"`
+	Scope       *ComparisonScope `json:"scope,omitempty"`
+	CrossTenant bool             `json:"crossTenant,omitempty"`
+}
+
+// ComparisonResult represents a single comparison result
+type ComparisonResult struct {
+	SponsorID      string  `json:"sponsor_id,omitempty"`
+	IncrementID    int     `json:"incrementId"`
+	ChainedWeight  float64 `json:"chainedWeight"`
+	ReceivedWeight float64 `json:"receivedWeight"`
+}
+
+// ComparisonResponse represents the response from weight comparison
+type ComparisonResponse struct {
+	Success        bool               `json:"success"`
+	Aggregation    string             `json:"aggregation,omitempty"`
+	Rejection      string             `json:"rejection,omitempty"`
+	Scope          *ComparisonScope   `json:"scope,omitempty"`
+	CrossTenant    bool               `json:"crossTenant"`
+	Results        []ComparisonResult `json:"results"`
+	Rejections     []RecordRejection  `json:"rejections"`
+	DeletedRecords []string           `json:"deletedRecords"`
+	SkippedRecords []SkippedRecord    `json:"skippedRecords,omitempty"`
+	Message        string             `json:"message,omitempty"`
+}
+
+// SkippedRecord represents a rejected record that could not be deleted
+type SkippedRecord struct {
+	RecordID string `json:"recordId"`
+	Reason   string `json:"reason"`
+}
+
+// TicketWeight represents the weight reported by a single ticket
+type TicketWeight struct {
+	TicketKey      string
+	ReceivedWeight float64
+	CreatedAt      string
+}
+
+// GroupKey identifies a comparison group. Groups are kept per sponsor unless
+// a cross-tenant comparison was requested, in which case SponsorID is empty.
+type GroupKey struct {
+	SponsorID   string
+	IncrementID float64
+}
+
+// GroupData represents grouped data for comparison
+type GroupData struct {
+	ChainedWeightSum float64
+	Tickets          []TicketWeight
+	TicketCount      int
+	RecordIDs        []string
+	Records          []RecordWeight
+}
+
+// CompareWeightsByPressIncrement compares weights by press increment
+func (wc *WeightComparison) CompareWeightsByPressIncrement(ctx contractapi.TransactionContextInterface, deleteViolations string, options string) (string, error) {
+	logger.Println("============= START : Compare Weights By Press Increment ===========")
+	response, err := wc.compareWeights(ctx, "press_increment", deleteViolations == "true", options)
+	if err != nil {
+		return "", err
+	}
+	logger.Println("============= END : Compare Weights By Press Increment ===========")
+
+	responseJSON, _ := json.Marshal(response)
+	return string(responseJSON), nil
+}
+
+// CompareWeightsByStoreIncrement compares weights by store increment
+func (wc *WeightComparison) CompareWeightsByStoreIncrement(ctx contractapi.TransactionContextInterface, deleteViolations string, options string) (string, error) {
+	logger.Println("============= START : Compare Weights By Store Increment ===========")
+	response, err := wc.compareWeights(ctx, "store_increment", deleteViolations == "true", options)
+	if err != nil {
+		return "", err
+	}
+	logger.Println("============= END : Compare Weights By Store Increment ===========")
+
+	responseJSON, _ := json.Marshal(response)
+	return string(responseJSON), nil
+}
+
+// compareWeights reports failures in the response until documents are deleted, later failures abort the transaction
+func (wc *WeightComparison) compareWeights(ctx contractapi.TransactionContextInterface, incrementField string, shouldDelete bool, options string) (*ComparisonResponse, error) {
+	comparisonOptions, err := wc.parseOptions(ctx, options)
+	if err != nil {
+		return comparisonErrorResponse(err), nil
+	}
+
+	if shouldDelete {
+		governance, err := loadGovernanceConfig(ctx)
+		if err != nil {
+			return comparisonErrorResponse(err), nil
+		}
+		if governance.Quorum > 1 {
+			return comparisonErrorResponse(fmt.Errorf("deleting violations requires %d approving organizations, use ProposeViolationAction", governance.Quorum)), nil
+		}
+	}
+
+	access, err := getTenantAccess(ctx)
+	if err != nil {
+		return comparisonErrorResponse(err), nil
+	}
+	violations, err := wc.findViolations(ctx, incrementField, comparisonOptions, access)
+	if err != nil {
+		return comparisonErrorResponse(err), nil
+	}
+
+	results := []ComparisonResult{}
+	rejections := []RecordRejection{}
+	deletedRecords := []string{}
+	skippedRecords := []SkippedRecord{}
+	events := []EventEntry{}
+
+	for _, violation := range violations {
+		results = append(results, violation.Result)
+		rejections = append(rejections, violation.Rejections...)
+
+		events = append(events, EventEntry{
+			Type: EventWeightViolationDetected,
+			Payload: WeightViolationDetectedPayload{
+				SponsorID:      violation.Result.SponsorID,
+				IncrementField: incrementField,
+				IncrementID:    violation.Result.IncrementID,
+				ChainedWeight:  violation.Result.ChainedWeight,
+				ReceivedWeight: violation.Result.ReceivedWeight,
+				Aggregation:    comparisonOptions.Aggregation,
+				RecordIDs:      violation.Group.RecordIDs,
+			},
+		})
+
+		if shouldDelete {
+			for _, rejection := range violation.Rejections {
+				err := deleteOwnedDocument(ctx, rejection.RecordID)
+				if err != nil {
+					skippedRecords = append(skippedRecords, SkippedRecord{
+						RecordID: rejection.RecordID,
+						Reason:   err.Error(),
+					})
+					continue
+				}
+				deletedRecords = append(deletedRecords, rejection.RecordID)
+			}
+		}
+	}
+
+	if len(deletedRecords) > 0 {
+		events = append(events, EventEntry{
+			Type: EventRecordsDeleted,
+			Payload: RecordsDeletedPayload{
+				Reason:    "weight violation",
+				RecordIDs: deletedRecords,
+			},
+		})
+	}
+
+	err = emitEvents(ctx, events)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ComparisonResponse{
+		Success:        true,
+		Aggregation:    comparisonOptions.Aggregation,
+		Rejection:      comparisonOptions.Rejection,
+		Scope:          comparisonOptions.Scope,
+		CrossTenant:    comparisonOptions.CrossTenant,
+		Results:        results,
+		Rejections:     rejections,
+		DeletedRecords: deletedRecords,
+		SkippedRecords: skippedRecords,
+	}, nil
+}
+
+// Violation represents an over-weight group and the records selected for rejection
+type Violation struct {
+	Key        GroupKey
+	Group      *GroupData
+	Result     ComparisonResult
+	Rejections []RecordRejection
+}
+
+// findViolations returns the over-weight groups in increment order without modifying the ledger
+func (wc *WeightComparison) findViolations(ctx contractapi.TransactionContextInterface, incrementField string, comparisonOptions *ComparisonOptions, access *TenantAccess) ([]Violation, error) {
+	groupedResults, err := wc.groupByIncrement(ctx, incrementField, comparisonOptions, access)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := []Violation{}
+	for _, groupKey := range sortedGroupKeys(groupedResults) {
+		group := groupedResults[groupKey]
+		receivedWeight := aggregateTickets(group.Tickets, comparisonOptions.Aggregation)
+
+		if classifyGroup(group, receivedWeight, comparisonOptions.Tolerance) != StatusOver {
+			continue
+		}
+
+		result := ComparisonResult{
+			SponsorID:      groupKey.SponsorID,
+			IncrementID:    int(groupKey.IncrementID),
+			ChainedWeight:  math.Round(group.ChainedWeightSum*100) / 100,
+			ReceivedWeight: math.Round(*receivedWeight*100) / 100,
+		}
+		violations = append(violations, Violation{
+			Key:        groupKey,
+			Group:      group,
+			Result:     result,
+			Rejections: selectRejections(result.IncrementID, group, *receivedWeight, comparisonOptions.Tolerance, comparisonOptions.Rejection),
+		})
+	}
+
+	return violations, nil
+}
+
+// sortedGroupKeys returns the group keys ordered by increment and sponsor
+func sortedGroupKeys(groupedResults map[GroupKey]*GroupData) []GroupKey {
+	groupKeys := make([]GroupKey, 0, len(groupedResults))
+	for groupKey := range groupedResults {
+		groupKeys = append(groupKeys, groupKey)
+	}
+	sort.Slice(groupKeys, func(i, j int) bool {
+		if groupKeys[i].IncrementID != groupKeys[j].IncrementID {
+			return groupKeys[i].IncrementID < groupKeys[j].IncrementID
+		}
+		return groupKeys[i].SponsorID < groupKeys[j].SponsorID
+	})
+	return groupKeys
+}
+
+// parseOptions parses the comparison options, filling unset values from the ledger configuration
+func (wc *WeightComparison) parseOptions(ctx contractapi.TransactionContextInterface, options string) (*ComparisonOptions, error) {
+	comparisonOptions := &ComparisonOptions{}
+	if options != "" {
+		err := json.Unmarshal([]byte(options), comparisonOptions)
+		if err != nil {
+			return nil, fmt.Errorf("invalid comparison options: %v", err)
+		}
+	}
+
+	config, err := loadComparisonConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if comparisonOptions.Aggregation == "" {
+		comparisonOptions.Aggregation = config.Aggregation
+	}
+	if comparisonOptions.Rejection == "" {
+		comparisonOptions.Rejection = config.Rejection
+	}
+	if comparisonOptions.Tolerance == 0 {
+		comparisonOptions.Tolerance = config.Tolerance
+	}
+
+	if !isValidAggregation(comparisonOptions.Aggregation) {
+		return nil, fmt.Errorf("invalid comparison options: unknown aggregation %s", comparisonOptions.Aggregation)
+	}
+	if !isValidRejection(comparisonOptions.Rejection) {
+		return nil, fmt.Errorf("invalid comparison options: unknown rejection strategy %s", comparisonOptions.Rejection)
+	}
+	if comparisonOptions.Tolerance < 0 {
+		return nil, fmt.Errorf("invalid comparison options: tolerance must not be negative")
+	}
+	if comparisonOptions.Scope != nil {
+		err := comparisonOptions.Scope.validate()
+		if err != nil {
+			return nil, fmt.Errorf("invalid comparison options: %v", err)
+		}
+		if comparisonOptions.Scope.SponsorID != "" {
+			err = checkSponsorAccess(ctx, comparisonOptions.Scope.SponsorID)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if comparisonOptions.CrossTenant {
+		isAuditor, err := callerHasRole(ctx, RoleAuditor)
+		if err != nil {
+			return nil, err
+		}
+		if !isAuditor {
+			return nil, fmt.Errorf("authorization denied: cross-tenant comparisons require the %s role", RoleAuditor)
+		}
+	}
+
+	return comparisonOptions, nil
+}
+
+// groupByIncrement groups the proof records and tickets in scope by sponsor and the given increment field.
+// Tickets naming a sponsor only count for that sponsor's group. Other tickets are shared by every sponsor
+// of their increment and apportioned by each sponsor's share of the increment's chained weight, so the
+// sponsors together never account for more than the ticket reported. Only the sponsors visible with
+// the given access are grouped, unless the comparison is cross-tenant.
+func (wc *WeightComparison) groupByIncrement(ctx contractapi.TransactionContextInterface, incrementField string, options *ComparisonOptions, access *TenantAccess) (map[GroupKey]*GroupData, error) {
+	scope := options.Scope
+	proofSelector := scope.proofRecordSelector(incrementField)
+	ticketSelector := scope.ticketSelector()
+	if scope != nil && scope.CollectorName != "" {
+		recordIDs, err := queryKeysByPrivateField(ctx, "proofRecordPrivate", "collector_name", scope.CollectorName)
+		if err != nil {
+			return nil, err
+		}
+		proofSelector["recordId"] = map[string]interface{}{
+			"$in": recordIDs,
+		}
+	}
+	if !options.CrossTenant {
+		access.restrictSelector(proofSelector)
+		access.restrictSelector(ticketSelector)
+	}
+
+	proofRecords, err := queryDocuments(ctx, proofSelector)
+	if err != nil {
+		return nil, err
+	}
+	if scope != nil && scope.CollectorName != "" {
+		proofRecords, err = filterPrivateReadable(ctx, proofRecords)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tickets, err := queryDocuments(ctx, ticketSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	groupedResults := make(map[GroupKey]*GroupData)
+	sponsorsByIncrement := make(map[float64][]string)
+	sponsorWeights := make(map[float64]*incrementWeights)
+	getGroup := func(groupKey GroupKey) *GroupData {
+		if _, exists := groupedResults[groupKey]; !exists {
+			groupedResults[groupKey] = &GroupData{
+				ChainedWeightSum: 0,
+				Tickets:          []TicketWeight{},
+				TicketCount:      0,
+				RecordIDs:        []string{},
+				Records:          []RecordWeight{},
+			}
+			sponsorsByIncrement[groupKey.IncrementID] = append(sponsorsByIncrement[groupKey.IncrementID], groupKey.SponsorID)
+		}
+		return groupedResults[groupKey]
+	}
+
+	for _, item := range proofRecords {
+		proof := item["Record"].(map[string]interface{})
+		recordID := item["Key"].(string)
+
+		if proof["status"] == RecordStatusVoided {
+			continue
+		}
+
+		incrementID, ok := toFloat64(proof[incrementField])
+		if !ok {
+			continue
+		}
+		weight, ok := toFloat64(proof["chained_weight"])
+		if !ok {
+			continue
+		}
+
+		createdAt, _ := proof["createdAt"].(string)
+
+		groupKey := GroupKey{IncrementID: incrementID}
+		if !options.CrossTenant {
+			groupKey.SponsorID = fmt.Sprint(proof["sponsor_id"])
+		}
+
+		group := getGroup(groupKey)
+		group.ChainedWeightSum += weight
+		group.RecordIDs = append(group.RecordIDs, recordID)
+		group.Records = append(group.Records, RecordWeight{
+			RecordID:      recordID,
+			ChainedWeight: weight,
+			CreatedAt:     createdAt,
+		})
+	}
+
+	for _, item := range tickets {
+		ticket := item["Record"].(map[string]interface{})
+		ticketKey := item["Key"].(string)
+
+		incrementID, ok := toFloat64(ticket["incrementId"])
+		if !ok {
+			continue
+		}
+		receivedWeight, ok := toFloat64(ticket["receivedWeight"])
+		if !ok {
+			continue
+		}
+		createdAt, _ := ticket["createdAt"].(string)
+		ticketSponsor, _ := ticket["sponsor_id"].(string)
+
+		shares := map[GroupKey]float64{}
+		switch {
+		case options.CrossTenant:
+			shares[GroupKey{IncrementID: incrementID}] = 1
+		case ticketSponsor != "":
+			shares[GroupKey{SponsorID: ticketSponsor, IncrementID: incrementID}] = 1
+		case len(sponsorsByIncrement[incrementID]) == 0:
+			shares[GroupKey{IncrementID: incrementID}] = 1
+		default:
+			if _, exists := sponsorWeights[incrementID]; !exists {
+				sponsorWeights[incrementID], err = wc.sponsorWeights(ctx, incrementField, incrementID)
+				if err != nil {
+					return nil, err
+				}
+			}
+			for _, sponsorID := range sponsorsByIncrement[incrementID] {
+				shares[GroupKey{SponsorID: sponsorID, IncrementID: incrementID}] = sponsorWeights[incrementID].share(sponsorID)
+			}
+		}
+
+		for groupKey, share := range shares {
+			// Tickets carry no collector, so only keep those matching a group in scope
+			if _, exists := groupedResults[groupKey]; !exists && scope.restrictsRecords() {
+				continue
+			}
+
+			group := getGroup(groupKey)
+			group.Tickets = append(group.Tickets, TicketWeight{
+				TicketKey:      ticketKey,
+				ReceivedWeight: receivedWeight * share,
+				CreatedAt:      createdAt,
+			})
+			group.TicketCount++
+		}
+	}
+
+	return groupedResults, nil
+}
+
+// incrementWeights holds the chained weight of every sponsor of an increment
+type incrementWeights struct {
+	total     float64
+	bySponsor map[string]float64
+}
+
+// share returns the fraction of the increment's chained weight held by a sponsor
+func (iw *incrementWeights) share(sponsorID string) float64 {
+	if iw.total <= 0 {
+		return 0
+	}
+	return iw.bySponsor[sponsorID] / iw.total
+}
+
+// sponsorWeights sums the chained weight of the active proof records of an increment per sponsor. Every
+// sponsor counts, whatever the caller may see and the scope selects, as a shared ticket covers them all.
+func (wc *WeightComparison) sponsorWeights(ctx contractapi.TransactionContextInterface, incrementField string, incrementID float64) (*incrementWeights, error) {
+	proofRecords, err := queryDocuments(ctx, map[string]interface{}{
+		"docType":      "proofRecord",
+		incrementField: incrementID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	weights := &incrementWeights{bySponsor: map[string]float64{}}
+	for _, item := range proofRecords {
+		proof := item["Record"].(map[string]interface{})
+		if proof["status"] == RecordStatusVoided {
+			continue
+		}
+		weight, ok := toFloat64(proof["chained_weight"])
+		if !ok {
+			continue
+		}
+		weights.total += weight
+		weights.bySponsor[fmt.Sprint(proof["sponsor_id"])] += weight
+	}
+	return weights, nil
+}
+
+// aggregateTickets reduces the ticket weights of a group to a single received weight
+func aggregateTickets(tickets []TicketWeight, aggregation string) *float64 {
+	if len(tickets) == 0 {
+		return nil
+	}
+
+	var result float64
+	switch aggregation {
+	case AggregationSum, AggregationAverage:
+		for _, ticket := range tickets {
+			result += ticket.ReceivedWeight
+		}
+		if aggregation == AggregationAverage {
+			result = result / float64(len(tickets))
+		}
+	case AggregationLatest:
+		var latest time.Time
+		for _, ticket := range tickets {
+			createdAt, err := time.Parse(time.RFC3339, ticket.CreatedAt)
+			if err != nil {
+				continue
+			}
+			if !createdAt.Before(latest) {
+				latest = createdAt
+				result = ticket.ReceivedWeight
+			}
+		}
+		if latest.IsZero() {
+			result = tickets[len(tickets)-1].ReceivedWeight
+		}
+	default:
+		result = tickets[0].ReceivedWeight
+		for _, ticket := range tickets[1:] {
+			result = math.Max(result, ticket.ReceivedWeight)
+		}
+	}
+
+	return &result
+}
+
+func isValidAggregation(aggregation string) bool {
+	switch aggregation {
+	case AggregationMax, AggregationSum, AggregationLatest, AggregationAverage:
+		return true
+	}
+	return false
+}
+
+// queryDocuments returns every document matching the given selector
+func queryDocuments(ctx contractapi.TransactionContextInterface, selector map[string]interface{}) ([]map[string]interface{}, error) {
+	query := map[string]interface{}{
+		"selector": selector,
+	}
+	queryString, _ := json.Marshal(query)
+
+	iterator, err := ctx.GetStub().GetQueryResult(string(queryString))
+	if err != nil {
+		return nil, err
+	}
+	defer iterator.Close()
+
+	return getAllResults(iterator)
+}
+
+func comparisonErrorResponse(err error) *ComparisonResponse {
+	return &ComparisonResponse{
+		Success:        false,
+		Message:        fmt.Sprintf("Error comparing weights: %v", err),
+		Results:        []ComparisonResult{},
+		Rejections:     []RecordRejection{},
+		DeletedRecords: []string{},
+	}
+}
@@ -0,0 +1,83 @@
+package chaincode
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// newTestContext returns a transaction context over a mock stub in an open transaction
+func newTestContext(t *testing.T) *contractapi.TransactionContext {
+	t.Helper()
+	stub := shimtest.NewMockStub("proof-records", nil)
+	stub.MockTransactionStart("tx1")
+	t.Cleanup(func() { stub.MockTransactionEnd("tx1") })
+
+	ctx := &contractapi.TransactionContext{}
+	ctx.SetStub(stub)
+	return ctx
+}
+
+func TestAggregateTickets(t *testing.T) {
+	tickets := []TicketWeight{
+		{ReceivedWeight: 10, CreatedAt: "2026-10-18T12:00:00Z"},
+		{ReceivedWeight: 30, CreatedAt: "2026-10-18T10:00:00Z"},
+		{ReceivedWeight: 20, CreatedAt: "2026-10-18T11:00:00Z"},
+	}
+	tests := map[string]float64{
+		AggregationMax:     30,
+		AggregationSum:     60,
+		AggregationAverage: 20,
+		AggregationLatest:  10,
+	}
+
+	for aggregation, want := range tests {
+		got := aggregateTickets(tickets, aggregation)
+		if got == nil || *got != want {
+			t.Errorf("%s aggregation = %v, want %v", aggregation, got, want)
+		}
+	}
+	if got := aggregateTickets(nil, AggregationMax); got != nil {
+		t.Errorf("aggregation of no tickets = %v, want nil", *got)
+	}
+}
+
+func TestParseOptionsDefaultsToTheLedgerConfig(t *testing.T) {
+	ctx := newTestContext(t)
+	wc := NewWeightComparison()
+
+	options, err := wc.parseOptions(ctx, "")
+	if err != nil {
+		t.Fatalf("parseOptions failed: %v", err)
+	}
+	if options.Aggregation != AggregationMax {
+		t.Errorf("default aggregation = %s, want %s", options.Aggregation, AggregationMax)
+	}
+
+	if _, err := NewConfigManager().SetComparisonConfig(ctx, `{"aggregation":"sum"}`); err != nil {
+		t.Fatalf("SetComparisonConfig failed: %v", err)
+	}
+	options, err = wc.parseOptions(ctx, "")
+	if err != nil {
+		t.Fatalf("parseOptions failed: %v", err)
+	}
+	if options.Aggregation != AggregationSum {
+		t.Errorf("configured aggregation = %s, want %s", options.Aggregation, AggregationSum)
+	}
+
+	options, err = wc.parseOptions(ctx, `{"aggregation":"latest"}`)
+	if err != nil {
+		t.Fatalf("parseOptions failed: %v", err)
+	}
+	if options.Aggregation != AggregationLatest {
+		t.Errorf("requested aggregation = %s, want %s", options.Aggregation, AggregationLatest)
+	}
+
+	if _, err := wc.parseOptions(ctx, `{"aggregation":"median"}`); err == nil {
+		t.Errorf("unknown aggregation was accepted")
+	}
+	if _, err := NewConfigManager().SetComparisonConfig(ctx, `{"aggregation":"median"}`); err == nil {
+		t.Errorf("config with an unknown aggregation was accepted")
+	}
+}
@@ -0,0 +1,24 @@
+// Package backend defines how clients of the proof records contract reach a ledger.
+package backend
+
+import "fmt"
+
+// Backend submits and evaluates transactions of the proof records contract
+type Backend interface {
+	// Submit endorses and commits a transaction and returns its result
+	Submit(transaction string, args []string, transient map[string][]byte) ([]byte, error)
+	// Evaluate runs a transaction without committing it and returns its result
+	Evaluate(transaction string, args []string, transient map[string][]byte) ([]byte, error)
+	// Close releases the resources of the backend
+	Close() error
+}
+
+// ChaincodeError is returned when the contract rejects a transaction
+type ChaincodeError struct {
+	Transaction string
+	Message     string
+}
+
+func (e *ChaincodeError) Error() string {
+	return fmt.Sprintf("%s failed: %s", e.Transaction, e.Message)
+}
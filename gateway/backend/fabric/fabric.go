@@ -0,0 +1,256 @@
+// Package fabric reaches the proof records contract through a Fabric Gateway peer.
+package fabric
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/hyperledger/fabric-gateway/pkg/identity"
+	gatewaypb "github.com/hyperledger/fabric-protos-go-apiv2/gateway"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+
+	"github.com/yourusername/proof-records-chaincode/gateway/backend"
+)
+
+// Config describes the peer, client identity and chaincode of a gateway connection
+type Config struct {
+	PeerEndpoint  string
+	PeerHostAlias string
+	TLSCertPath   string
+	MSPID         string
+	CertPath      string
+	KeyPath       string
+	Channel       string
+	Chaincode     string
+}
+
+// ConfigFromEnv reads the connection configuration from FABRIC_* environment variables
+func ConfigFromEnv() Config {
+	return Config{
+		PeerEndpoint:  getEnv("FABRIC_PEER_ENDPOINT", "localhost:7051"),
+		PeerHostAlias: getEnv("FABRIC_PEER_HOST_ALIAS", ""),
+		TLSCertPath:   getEnv("FABRIC_TLS_CERT", ""),
+		MSPID:         getEnv("FABRIC_MSP_ID", "Org1MSP"),
+		CertPath:      getEnv("FABRIC_CERT_PATH", ""),
+		KeyPath:       getEnv("FABRIC_KEY_PATH", ""),
+		Channel:       getEnv("FABRIC_CHANNEL", "mychannel"),
+		Chaincode:     getEnv("FABRIC_CHAINCODE", "proof-records"),
+	}
+}
+
+// Gateway submits and evaluates transactions through a Fabric Gateway peer
+type Gateway struct {
+	connection *grpc.ClientConn
+	gateway    *client.Gateway
+	network    *client.Network
+	contract   *client.Contract
+	chaincode  string
+}
+
+var _ backend.Backend = (*Gateway)(nil)
+
+// Connect opens a gateway connection with the given configuration
+func Connect(config Config) (*Gateway, error) {
+	if config.TLSCertPath == "" || config.CertPath == "" || config.KeyPath == "" {
+		return nil, fmt.Errorf("TLS certificate, client certificate and client key paths are required")
+	}
+
+	connection, err := newConnection(config)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := newIdentity(config)
+	if err != nil {
+		connection.Close()
+		return nil, err
+	}
+	sign, err := newSign(config)
+	if err != nil {
+		connection.Close()
+		return nil, err
+	}
+
+	gw, err := client.Connect(
+		id,
+		client.WithSign(sign),
+		client.WithClientConnection(connection),
+		client.WithEvaluateTimeout(5*time.Second),
+		client.WithEndorseTimeout(15*time.Second),
+		client.WithSubmitTimeout(5*time.Second),
+		client.WithCommitStatusTimeout(time.Minute),
+	)
+	if err != nil {
+		connection.Close()
+		return nil, fmt.Errorf("failed to connect to gateway: %v", err)
+	}
+
+	network := gw.GetNetwork(config.Channel)
+	return &Gateway{
+		connection: connection,
+		gateway:    gw,
+		network:    network,
+		contract:   network.GetContract(config.Chaincode),
+		chaincode:  config.Chaincode,
+	}, nil
+}
+
+// Submit endorses a transaction, submits it for ordering and waits for its commit
+func (g *Gateway) Submit(transaction string, args []string, transient map[string][]byte) ([]byte, error) {
+	result, err := g.contract.Submit(transaction, client.WithArguments(args...), client.WithTransient(transient))
+	if err != nil {
+		return nil, transactionError(transaction, err)
+	}
+	return result, nil
+}
+
+// Evaluate runs a transaction on a peer without submitting it
+func (g *Gateway) Evaluate(transaction string, args []string, transient map[string][]byte) ([]byte, error) {
+	result, err := g.contract.Evaluate(transaction, client.WithArguments(args...), client.WithTransient(transient))
+	if err != nil {
+		return nil, transactionError(transaction, err)
+	}
+	return result, nil
+}
+
+// ChaincodeEvents delivers the chaincode events of the contract committed from startBlock
+// on. The channel is closed when ctx is done or the event stream fails.
+func (g *Gateway) ChaincodeEvents(ctx context.Context, startBlock uint64) (<-chan *client.ChaincodeEvent, error) {
+	events, err := g.network.ChaincodeEvents(ctx, g.chaincode, client.WithStartBlock(startBlock))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chaincode events: %v", err)
+	}
+	return events, nil
+}
+
+// Close closes the gateway and its gRPC connection
+func (g *Gateway) Close() error {
+	g.gateway.Close()
+	return g.connection.Close()
+}
+
+// transactionError turns a rejection by the contract into a ChaincodeError carrying the
+// chaincode message. Connection, ordering and commit failures are returned unchanged.
+func transactionError(transaction string, err error) error {
+	var endorseErr *client.EndorseError
+	var commitErr *client.CommitError
+	if errors.As(err, &commitErr) {
+		return fmt.Errorf("%s failed to commit with status %s", transaction, commitErr.Code)
+	}
+	if !errors.As(err, &endorseErr) {
+		return err
+	}
+
+	messages := []string{}
+	for _, detail := range status.Convert(err).Details() {
+		if errorDetail, ok := detail.(*gatewaypb.ErrorDetail); ok {
+			messages = append(messages, chaincodeMessage(errorDetail.Message))
+		}
+	}
+	if len(messages) == 0 {
+		return err
+	}
+	return &backend.ChaincodeError{Transaction: transaction, Message: messages[0]}
+}
+
+// chaincodeMessage strips the prefix peers add to chaincode errors
+func chaincodeMessage(message string) string {
+	const prefix = "chaincode response 500, "
+	if index := strings.Index(message, prefix); index >= 0 {
+		return message[index+len(prefix):]
+	}
+	return message
+}
+
+func newConnection(config Config) (*grpc.ClientConn, error) {
+	certificate, err := loadCertificate(config.TLSCertPath)
+	if err != nil {
+		return nil, err
+	}
+	certPool := x509.NewCertPool()
+	certPool.AddCert(certificate)
+
+	connection, err := grpc.Dial(
+		config.PeerEndpoint,
+		grpc.WithTransportCredentials(credentials.NewClientTLSFromCert(certPool, config.PeerHostAlias)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gRPC connection to %s: %v", config.PeerEndpoint, err)
+	}
+	return connection, nil
+}
+
+func newIdentity(config Config) (*identity.X509Identity, error) {
+	certificate, err := loadCertificate(config.CertPath)
+	if err != nil {
+		return nil, err
+	}
+	id, err := identity.NewX509Identity(config.MSPID, certificate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client identity: %v", err)
+	}
+	return id, nil
+}
+
+func newSign(config Config) (identity.Sign, error) {
+	keyPEM, err := readFile(config.KeyPath)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, err := identity.PrivateKeyFromPEM(keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse client key: %v", err)
+	}
+	sign, err := identity.NewPrivateKeySign(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client signer: %v", err)
+	}
+	return sign, nil
+}
+
+func loadCertificate(path string) (*x509.Certificate, error) {
+	certificatePEM, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	certificate, err := identity.CertificateFromPEM(certificatePEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate %s: %v", path, err)
+	}
+	return certificate, nil
+}
+
+// readFile reads a file, or the only file of a directory as laid out by Fabric MSP folders
+func readFile(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", path, err)
+		}
+		if len(entries) != 1 {
+			return nil, fmt.Errorf("expected a single file in %s, found %d", path, len(entries))
+		}
+		path = filepath.Join(path, entries[0].Name())
+	}
+	return os.ReadFile(path)
+}
+
+func getEnv(name string, fallback string) string {
+	if value, exists := os.LookupEnv(name); exists {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,61 @@
+package memory_test
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCreateProofRecordWithoutSaltHasNoCommitment(t *testing.T) {
+	c := newContractTest(t)
+	c.register("S1", "Alice")
+	record := `{"sponsor_id":"S1","proof_short_id":"P-1","collector_name":"Alice","bulk_name":"Bulk S1","bulk_short_id":"B-S1","traceChainType":"standard","parent_increment":1,"press_increment":1,"store_increment":1,"chained_weight":10}`
+
+	response := c.submit("CreateProofRecord", record)
+	if response["success"] != true {
+		t.Fatalf("CreateProofRecord without salt failed: %v", response["message"])
+	}
+	recordID := response["recordId"].(string)
+	if _, committed := decode(t, c.evaluate("QueryProofRecord", recordID))["commitment"]; committed {
+		t.Errorf("record created without salt has a commitment")
+	}
+	_, err := c.ledger.Evaluate("VerifyProofRecordCommitment", []string{recordID, record, "salt-1"}, nil)
+	if err == nil || !strings.Contains(err.Error(), "has no commitment") {
+		t.Errorf("verification of a record without commitment error = %v", err)
+	}
+
+	response = c.submitTransient(map[string][]byte{"record": []byte(record)}, "CreateProofRecordPrivate")
+	if message, _ := response["message"].(string); response["success"] != false || !strings.Contains(message, "secret salt is required") {
+		t.Errorf("CreateProofRecordPrivate without salt = %v", response)
+	}
+}
+
+func TestVerifyProofRecordCommitment(t *testing.T) {
+	c := newContractTest(t)
+	recordID := c.createRecord("S1", "Alice", 1, 1, 10)
+	claimed := encode(t, map[string]interface{}{
+		"sponsor_id":       "S1",
+		"proof_short_id":   "P-S1-1",
+		"collector_name":   "Alice",
+		"bulk_name":        "Bulk S1",
+		"bulk_short_id":    "B-S1",
+		"traceChainType":   "standard",
+		"parent_increment": 1,
+		"press_increment":  1,
+		"store_increment":  1,
+		"chained_weight":   10,
+	})
+
+	verification := decode(t, c.evaluate("VerifyProofRecordCommitment", recordID, claimed, "salt-1"))
+	if verification["matches"] != true || verification["publicMatches"] != true {
+		t.Errorf("commitments do not match the submitted record and salt: %v", verification)
+	}
+	verification = decode(t, c.evaluate("VerifyProofRecordCommitment", recordID, claimed, "salt-2"))
+	if verification["matches"] != false || verification["publicMatches"] != false {
+		t.Errorf("commitments match a wrong salt: %v", verification)
+	}
+
+	_, err := c.ledger.Evaluate("VerifyProofRecordCommitment", []string{recordID, claimed, ""}, nil)
+	if err == nil || !strings.Contains(err.Error(), "salt is required") {
+		t.Errorf("verification without salt error = %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package memory_test
+
+import (
+	"strings"
+	"testing"
+)
+
+// compare compares the weights of every press increment without deleting violations. Only
+// over-weight groups are reported, so the tests keep their tickets under the chained weight.
+func (c *contractTest) compare(options string) map[string]interface{} {
+	c.t.Helper()
+	return decode(c.t, c.evaluate("CompareWeightsByPressIncrementWithOptions", "false", options))
+}
+
+// receivedWeights returns the received weight of every over-weight group by sponsor
+func receivedWeights(t *testing.T, response map[string]interface{}) map[string]float64 {
+	t.Helper()
+	if response["success"] != true {
+		t.Fatalf("comparison failed: %v", response["message"])
+	}
+	weights := map[string]float64{}
+	for _, item := range response["results"].([]interface{}) {
+		result := item.(map[string]interface{})
+		sponsorID, _ := result["sponsor_id"].(string)
+		weights[sponsorID] = result["receivedWeight"].(float64)
+	}
+	return weights
+}
+
+func TestComparisonApportionsSharedTickets(t *testing.T) {
+	c := newContractTest(t)
+	c.createRecord("S1", "Alice", 1, 1, 30)
+	c.createRecord("S2", "Bob", 2, 1, 70)
+	c.createTicket("T-shared", "", 1, 50)
+	c.createTicket("T-S2", "S2", 1, 5)
+
+	weights := receivedWeights(t, c.compare(`{"aggregation":"sum"}`))
+	if weights["S1"] != 15 || weights["S2"] != 40 {
+		t.Errorf("received weights = %v, want S1 15 and S2 35 plus its own ticket", weights)
+	}
+
+	// Cross-tenant comparisons count the shared ticket once
+	weights = receivedWeights(t, c.compare(`{"aggregation":"sum","crossTenant":true}`))
+	if weights[""] != 55 {
+		t.Errorf("cross-tenant received weight = %v, want 55", weights[""])
+	}
+}
+
+func TestComparisonAggregation(t *testing.T) {
+	c := newContractTest(t)
+	c.createRecord("S1", "Alice", 1, 1, 40)
+	c.createTicket("T-1", "S1", 1, 10)
+	c.createTicket("T-2", "S1", 1, 20)
+
+	tests := map[string]float64{"max": 20, "sum": 30, "average": 15, "latest": 20}
+	for aggregation, want := range tests {
+		weights := receivedWeights(t, c.compare(encode(t, map[string]string{"aggregation": aggregation})))
+		if weights["S1"] != want {
+			t.Errorf("%s received weight = %v, want %v", aggregation, weights["S1"], want)
+		}
+	}
+
+	response := c.compare(`{"aggregation":"median"}`)
+	if message, _ := response["message"].(string); response["success"] != false || !strings.Contains(message, "unknown aggregation") {
+		t.Errorf("unknown aggregation: %v", response)
+	}
+}
+
+func TestComparisonIsScopedToTheCallersSponsors(t *testing.T) {
+	c := newContractTest(t)
+	c.createRecord("S1", "Alice", 1, 1, 30)
+	c.createRecord("S2", "Bob", 2, 1, 70)
+	c.createTicket("T-shared", "", 1, 50)
+
+	c.as(identityWith("verifier", "S1"))
+	weights := receivedWeights(t, c.compare(""))
+	if len(weights) != 1 || weights["S1"] != 15 {
+		t.Errorf("verifier of S1 compares %v, want the share of S1 only", weights)
+	}
+
+	for _, options := range []string{`{"scope":{"sponsor_id":"S2"}}`, `{"crossTenant":true}`} {
+		response, err := c.ledger.Evaluate("CompareWeightsByPressIncrementWithOptions", []string{"false", options}, nil)
+		if err == nil && !strings.Contains(string(response), "authorization denied") {
+			t.Errorf("verifier of S1 compared with %s: %s", options, response)
+		}
+	}
+}
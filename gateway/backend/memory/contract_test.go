@@ -0,0 +1,166 @@
+package memory_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/yourusername/proof-records-chaincode/gateway/backend/memory"
+)
+
+// org2Admin is an administrator of the second organization of the test ledger
+var org2Admin = memory.Identity{
+	MSPID:      "Org2MSP",
+	Name:       "admin",
+	Attributes: map[string]string{"role": "admin,collector,weighbridge,verifier,auditor"},
+}
+
+// contractTest runs contract transactions against a fresh in-process ledger
+type contractTest struct {
+	t      *testing.T
+	ledger *memory.Ledger
+	salts  int
+	// registered holds the keys of the sponsors, collectors and bulks registered on the ledger
+	registered map[string]bool
+}
+
+func newContractTest(t *testing.T) *contractTest {
+	t.Helper()
+	ledger, err := memory.New(memory.DefaultIdentity())
+	if err != nil {
+		t.Fatalf("failed to create ledger: %v", err)
+	}
+	return &contractTest{t: t, ledger: ledger, registered: map[string]bool{}}
+}
+
+// as invokes the following transactions with the given identity
+func (c *contractTest) as(identity memory.Identity) {
+	c.t.Helper()
+	if err := c.ledger.SetIdentity(identity); err != nil {
+		c.t.Fatalf("failed to set identity: %v", err)
+	}
+}
+
+// submit commits a transaction and decodes its JSON result, failing the test on errors
+func (c *contractTest) submit(transaction string, args ...string) map[string]interface{} {
+	c.t.Helper()
+	return c.submitTransient(nil, transaction, args...)
+}
+
+// submitTransient commits a transaction passing the given transient map
+func (c *contractTest) submitTransient(transient map[string][]byte, transaction string, args ...string) map[string]interface{} {
+	c.t.Helper()
+	result, err := c.ledger.Submit(transaction, args, transient)
+	if err != nil {
+		c.t.Fatalf("%s failed: %v", transaction, err)
+	}
+	return decode(c.t, result)
+}
+
+// submitError commits a transaction expected to fail and returns its error
+func (c *contractTest) submitError(transaction string, args ...string) error {
+	c.t.Helper()
+	_, err := c.ledger.Submit(transaction, args, nil)
+	if err == nil {
+		c.t.Fatalf("%s succeeded, expected an error", transaction)
+	}
+	return err
+}
+
+// evaluate runs a query transaction and returns its raw result
+func (c *contractTest) evaluate(transaction string, args ...string) []byte {
+	c.t.Helper()
+	result, err := c.ledger.Evaluate(transaction, args, nil)
+	if err != nil {
+		c.t.Fatalf("%s failed: %v", transaction, err)
+	}
+	return result
+}
+
+// createRecord creates a proof record of a sponsor and returns its ID
+func (c *contractTest) createRecord(sponsorID string, collectorName string, parentIncrement int, pressIncrement int, chainedWeight float64) string {
+	c.t.Helper()
+	record := map[string]interface{}{
+		"sponsor_id":       sponsorID,
+		"proof_short_id":   fmt.Sprintf("P-%s-%d", sponsorID, parentIncrement),
+		"collector_name":   collectorName,
+		"bulk_name":        "Bulk " + sponsorID,
+		"bulk_short_id":    "B-" + sponsorID,
+		"traceChainType":   "standard",
+		"parent_increment": parentIncrement,
+		"press_increment":  pressIncrement,
+		"store_increment":  pressIncrement,
+		"chained_weight":   chainedWeight,
+	}
+	c.register(sponsorID, collectorName)
+	response := c.submitTransient(c.salt(), "CreateProofRecord", encode(c.t, record))
+	if response["success"] != true {
+		c.t.Fatalf("CreateProofRecord failed: %v", response["message"])
+	}
+	return response["recordId"].(string)
+}
+
+// register registers the sponsor, collector and bulk of the records of createRecord on the
+// ledger, which proof records are validated against
+func (c *contractTest) register(sponsorID string, collectorName string) {
+	c.t.Helper()
+	if !c.registered["sponsor:"+sponsorID] {
+		c.submit("CreateSponsor", encode(c.t, map[string]string{"sponsor_id": sponsorID, "name": "Sponsor " + sponsorID}))
+		bulk := map[string]string{"bulk_short_id": "B-" + sponsorID, "bulk_name": "Bulk " + sponsorID, "sponsor_id": sponsorID}
+		c.submit("CreateBulk", encode(c.t, bulk))
+		c.registered["sponsor:"+sponsorID] = true
+	}
+	if !c.registered["collector:"+sponsorID+":"+collectorName] {
+		collector := map[string]string{
+			"collectorId":    fmt.Sprintf("C-%s-%s", sponsorID, collectorName),
+			"collector_name": collectorName,
+			"sponsor_id":     sponsorID,
+		}
+		transient := map[string][]byte{"salt": []byte("salt-" + collector["collectorId"])}
+		c.submitTransient(transient, "CreateCollector", encode(c.t, collector))
+		c.registered["collector:"+sponsorID+":"+collectorName] = true
+	}
+}
+
+// salt returns a transient map holding a new commitment salt, salt-1 for the first record
+func (c *contractTest) salt() map[string][]byte {
+	c.salts++
+	return map[string][]byte{"salt": []byte(fmt.Sprintf("salt-%d", c.salts))}
+}
+
+// createTicket creates a ticket, shared by every sponsor of its increment when sponsorID is empty
+func (c *contractTest) createTicket(id string, sponsorID string, incrementID int, receivedWeight float64) string {
+	c.t.Helper()
+	ticket := map[string]interface{}{
+		"id":             id,
+		"incrementId":    incrementID,
+		"receivedWeight": receivedWeight,
+	}
+	if sponsorID != "" {
+		ticket["sponsor_id"] = sponsorID
+	}
+
+	response := c.submit("CreateTicket", encode(c.t, ticket))
+	if response["success"] != true {
+		c.t.Fatalf("CreateTicket failed: %v", response["message"])
+	}
+	return response["ticketKey"].(string)
+}
+
+func encode(t *testing.T, value interface{}) string {
+	t.Helper()
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		t.Fatalf("failed to encode %v: %v", value, err)
+	}
+	return string(encoded)
+}
+
+func decode(t *testing.T, result []byte) map[string]interface{} {
+	t.Helper()
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(result, &decoded); err != nil {
+		t.Fatalf("invalid result %s: %v", result, err)
+	}
+	return decoded
+}
@@ -0,0 +1,203 @@
+package memory_test
+
+import (
+	"strings"
+	"testing"
+)
+
+// issueCredits issues credits against a press increment of a sponsor and returns the response
+func (c *contractTest) issueCredits(sponsorID string, incrementID int) map[string]interface{} {
+	c.t.Helper()
+	return c.submit("IssueCredits", encode(c.t, map[string]interface{}{
+		"sponsor_id":     sponsorID,
+		"incrementField": "press_increment",
+		"incrementId":    incrementID,
+	}))
+}
+
+func TestIssueCreditsForBalancedAndUnderIncrements(t *testing.T) {
+	c := newContractTest(t)
+	balanced := c.createRecord("S1", "Alice", 1, 1, 10)
+	c.createTicket("T-1", "S1", 1, 10)
+	c.createRecord("S1", "Alice", 2, 2, 8)
+	c.createTicket("T-2", "S1", 2, 10)
+
+	response := c.issueCredits("S1", 1)
+	if response["success"] != true {
+		t.Fatalf("balanced increment issued no credits: %v", response["message"])
+	}
+	credit := response["credit"].(map[string]interface{})
+	if credit["amount"] != 10.0 {
+		t.Errorf("amount = %v, want 10", credit["amount"])
+	}
+	sources := credit["sourceRecords"].([]interface{})
+	if len(sources) != 1 || sources[0] != balanced {
+		t.Errorf("source records = %v, want %s", sources, balanced)
+	}
+
+	response = c.issueCredits("S1", 2)
+	if response["success"] != true {
+		t.Fatalf("under increment issued no credits: %v", response["message"])
+	}
+	if amount := response["credit"].(map[string]interface{})["amount"]; amount != 8.0 {
+		t.Errorf("amount = %v, want the chained weight 8", amount)
+	}
+
+	response = c.issueCredits("S1", 1)
+	if response["success"] == true || !strings.Contains(response["message"].(string), "already backs a credit") {
+		t.Errorf("second issuance = %v", response)
+	}
+}
+
+func TestIssueCreditsUsesTheLedgerTolerance(t *testing.T) {
+	c := newContractTest(t)
+	c.createRecord("S1", "Alice", 1, 1, 12)
+	c.createTicket("T-1", "S1", 1, 10)
+
+	// Caller supplied options cannot loosen the comparison
+	response := c.submit("IssueCredits", `{"sponsor_id":"S1","incrementField":"press_increment","incrementId":1,"options":{"tolerance":1e9}}`)
+	if response["success"] == true || !strings.Contains(response["message"].(string), "is over") {
+		t.Fatalf("over increment issuance = %v", response)
+	}
+
+	c.submit("SetComparisonConfig", `{"tolerance":2}`)
+	response = c.issueCredits("S1", 1)
+	if response["success"] != true {
+		t.Errorf("increment within the configured tolerance issued no credits: %v", response["message"])
+	}
+}
+
+func TestIssueCreditsApportionsSharedTickets(t *testing.T) {
+	c := newContractTest(t)
+	c.createRecord("S1", "Alice", 1, 1, 100)
+	c.createRecord("S2", "Bob", 2, 1, 100)
+	c.createTicket("T-1", "", 1, 100)
+
+	for _, sponsorID := range []string{"S1", "S2"} {
+		response := c.issueCredits(sponsorID, 1)
+		if response["success"] == true {
+			t.Errorf("sponsor %s was credited against its half of a shared ticket", sponsorID)
+		}
+	}
+}
+
+// balanceOf returns the active and retired credits of an organization
+func (c *contractTest) balanceOf(mspID string) (float64, float64) {
+	c.t.Helper()
+	balance := decode(c.t, c.evaluate("BalanceOf", "msp", mspID))
+	return balance["balance"].(float64), balance["retired"].(float64)
+}
+
+func TestTransferAndRetireCredits(t *testing.T) {
+	c := newContractTest(t)
+	recordID := c.createRecord("S1", "Alice", 1, 1, 10)
+	c.createTicket("T-1", "S1", 1, 10)
+	creditID := c.issueCredits("S1", 1)["credit"].(map[string]interface{})["creditId"].(string)
+
+	response := c.submit("Transfer", encode(t, map[string]interface{}{
+		"creditId": creditID, "amount": 4, "toOwnerType": "msp", "toOwner": "Org2MSP",
+	}))
+	if response["success"] != true {
+		t.Fatalf("Transfer failed: %v", response["message"])
+	}
+	lot := response["credit"].(map[string]interface{})
+	lotID := lot["creditId"].(string)
+	if lot["amount"] != 4.0 || lot["parentCreditId"] != creditID || lot["owner"] != "Org2MSP" {
+		t.Errorf("transferred lot = %v", lot)
+	}
+	if balance, _ := c.balanceOf("Org1MSP"); balance != 6 {
+		t.Errorf("Org1MSP balance = %v, want 6", balance)
+	}
+
+	// Credits move only with their owner
+	response = c.submit("Transfer", encode(t, map[string]interface{}{
+		"creditId": lotID, "toOwnerType": "msp", "toOwner": "Org1MSP",
+	}))
+	if message, _ := response["message"].(string); response["success"] != false || !strings.Contains(message, "not owned by the caller") {
+		t.Errorf("transfer of another owner's credit = %v", response)
+	}
+	response = c.submit("Transfer", encode(t, map[string]interface{}{
+		"creditId": creditID, "amount": 7, "toOwnerType": "msp", "toOwner": "Org2MSP",
+	}))
+	if message, _ := response["message"].(string); response["success"] != false || !strings.Contains(message, "invalid amount") {
+		t.Errorf("transfer of more than the credit holds = %v", response)
+	}
+
+	c.as(org2Admin)
+	response = c.submit("Retire", encode(t, map[string]interface{}{"creditId": lotID, "beneficiary": "Acme"}))
+	if message, _ := response["message"].(string); response["success"] != false || !strings.Contains(message, "claim is required") {
+		t.Errorf("retirement without claim = %v", response)
+	}
+	response = c.submit("Retire", encode(t, map[string]interface{}{"creditId": lotID, "beneficiary": "Acme", "claim": "2026 packaging"}))
+	if response["success"] != true {
+		t.Fatalf("Retire failed: %v", response["message"])
+	}
+	certificateID := response["credit"].(map[string]interface{})["retirementCertificateId"].(string)
+	if balance, retired := c.balanceOf("Org2MSP"); balance != 0 || retired != 4 {
+		t.Errorf("Org2MSP balance = %v retired = %v, want 0 and 4", balance, retired)
+	}
+
+	certificate := decode(t, c.evaluate("QueryRetirementCertificate", certificateID))
+	sources, _ := certificate["sourceRecords"].([]interface{})
+	if certificate["amount"] != 4.0 || certificate["claim"] != "2026 packaging" || len(sources) != 1 || sources[0] != recordID {
+		t.Errorf("certificate = %v", certificate)
+	}
+
+	// Retired credits are never counted twice
+	response = c.submit("Retire", encode(t, map[string]interface{}{"creditId": lotID, "claim": "again"}))
+	if message, _ := response["message"].(string); response["success"] != false || !strings.Contains(message, "is retired") {
+		t.Errorf("second retirement = %v", response)
+	}
+}
+
+// createBackedViolation issues a credit against a balanced group of S1, then adds a record that puts
+// the group over its received weight, and returns the backing and the added record
+func (c *contractTest) createBackedViolation() (string, string) {
+	c.t.Helper()
+	backing := c.createRecord("S1", "Alice", 1, 1, 10)
+	c.createTicket("T-1", "S1", 1, 10)
+	if response := c.issueCredits("S1", 1); response["success"] != true {
+		c.t.Fatalf("IssueCredits failed: %v", response["message"])
+	}
+	added := c.createRecord("S1", "Alice", 2, 1, 5)
+	return backing, added
+}
+
+func TestDeleteViolationsKeepsCreditBackedRecords(t *testing.T) {
+	c := newContractTest(t)
+	backing, added := c.createBackedViolation()
+	c.submit("SetGovernanceConfig", `{"quorum":1}`)
+
+	response := c.submit("CompareWeightsByPressIncrement", "true")
+	deleted, _ := response["deletedRecords"].([]interface{})
+	if len(deleted) != 1 || deleted[0] != added {
+		t.Errorf("deleted records = %v, want %s only", deleted, added)
+	}
+	skipped, _ := response["skippedRecords"].([]interface{})
+	if len(skipped) != 1 || skipped[0].(map[string]interface{})["recordId"] != backing {
+		t.Fatalf("skipped records = %v, want %s", skipped, backing)
+	}
+	if reason := skipped[0].(map[string]interface{})["reason"].(string); !strings.Contains(reason, "backs credit") {
+		t.Errorf("skip reason = %q", reason)
+	}
+	c.evaluate("QueryProofRecord", backing)
+}
+
+func TestProposalsKeepCreditBackedRecords(t *testing.T) {
+	c := newContractTest(t)
+	backing, added := c.createBackedViolation()
+	proposalID := proposeVoid(c)
+
+	c.as(org2Admin)
+	proposal := c.submit("ApproveProposal", proposalID)["proposal"].(map[string]interface{})
+	if proposal["status"] != "executed" {
+		t.Fatalf("proposal status = %v, want executed", proposal["status"])
+	}
+	affected, _ := proposal["affectedKeys"].([]interface{})
+	if len(affected) != 1 || affected[0] != added {
+		t.Errorf("affected keys = %v, want %s only", affected, added)
+	}
+	if record := decode(t, c.evaluate("QueryProofRecord", backing)); record["status"] == "voided" {
+		t.Errorf("record backing a credit was voided")
+	}
+}
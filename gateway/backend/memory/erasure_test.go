@@ -0,0 +1,111 @@
+package memory_test
+
+import (
+	"strings"
+	"testing"
+)
+
+const pseudonymKey = "0123456789abcdef0123456789abcdef"
+
+func erasureTransient(key string) map[string][]byte {
+	return map[string][]byte{"pseudonymKey": []byte(key)}
+}
+
+func TestErasePersonalDataClearsCommitmentAndKeepsPseudonymStable(t *testing.T) {
+	c := newContractTest(t)
+	first := c.createRecord("S1", "Alice", 1, 1, 10)
+	second := c.createRecord("S1", "Alice", 2, 2, 10)
+
+	erased := c.submitTransient(erasureTransient(pseudonymKey), "ErasePersonalData", first, "request of the collector")
+	if _, exposed := erased["collector_name"]; exposed {
+		t.Errorf("erased record still holds the collector name")
+	}
+	commitment := erased["commitment"].(map[string]interface{})
+	if _, committed := commitment["value"]; committed {
+		t.Errorf("erased record still holds the commitment over its personal data")
+	}
+	if commitment["publicValue"] == nil {
+		t.Errorf("erased record lost the commitment over its public fields")
+	}
+	erasure := erased["personalDataErased"].(map[string]interface{})
+	if erasure["commitmentCleared"] != true {
+		t.Errorf("commitmentCleared = %v, want true", erasure["commitmentCleared"])
+	}
+
+	// The weights stay verifiable against the public commitment
+	claimed := encode(t, map[string]interface{}{
+		"sponsor_id":       "S1",
+		"proof_short_id":   "P-S1-1",
+		"collector_name":   "Alice",
+		"bulk_name":        "Bulk S1",
+		"bulk_short_id":    "B-S1",
+		"traceChainType":   "standard",
+		"parent_increment": 1,
+		"press_increment":  1,
+		"store_increment":  1,
+		"chained_weight":   10,
+	})
+	verification := decode(t, c.evaluate("VerifyProofRecordCommitment", first, claimed, "salt-1"))
+	if verification["publicMatches"] != true || verification["matches"] != false {
+		t.Errorf("verification of an erased record = %v, want only the public fields to match", verification)
+	}
+	tampered := strings.Replace(claimed, `"chained_weight":10`, `"chained_weight":12`, 1)
+	verification = decode(t, c.evaluate("VerifyProofRecordCommitment", first, tampered, "salt-1"))
+	if verification["publicMatches"] != false {
+		t.Errorf("changed weight matches the public commitment")
+	}
+
+	other := c.submitTransient(erasureTransient(pseudonymKey), "ErasePersonalData", second, "request of the collector")
+	otherErasure := other["personalDataErased"].(map[string]interface{})
+	if erasure["pseudonym"] != otherErasure["pseudonym"] {
+		t.Errorf("pseudonyms of one collector differ: %v and %v", erasure["pseudonym"], otherErasure["pseudonym"])
+	}
+}
+
+func TestErasePersonalDataRequiresTheSamePseudonymKey(t *testing.T) {
+	c := newContractTest(t)
+	first := c.createRecord("S1", "Alice", 1, 1, 10)
+	second := c.createRecord("S1", "Bob", 2, 2, 10)
+
+	_, err := c.ledger.Submit("ErasePersonalData", []string{first, "request"}, erasureTransient("short"))
+	if err == nil || !strings.Contains(err.Error(), "at least 32 bytes") {
+		t.Fatalf("erasure with a short key error = %v", err)
+	}
+
+	c.submitTransient(erasureTransient(pseudonymKey), "ErasePersonalData", first, "request")
+	_, err = c.ledger.Submit("ErasePersonalData", []string{second, "request"}, erasureTransient(strings.Repeat("x", 32)))
+	if err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Fatalf("erasure with another key error = %v", err)
+	}
+}
+
+func TestEraseCollectorPersonalData(t *testing.T) {
+	c := newContractTest(t)
+	first := c.createRecord("S1", "Alice", 1, 1, 10)
+	second := c.createRecord("S1", "Alice", 2, 2, 10)
+	kept := c.createRecord("S1", "Bob", 3, 3, 10)
+
+	transient := erasureTransient(pseudonymKey)
+	transient["collector_name"] = []byte("Alice")
+	response := c.submitTransient(transient, "EraseCollectorPersonalData", "request of the collector")
+
+	erasedKeys := response["erasedKeys"].([]interface{})
+	if len(erasedKeys) != 3 || erasedKeys[2] != "ENTITY_COLLECTOR_C-S1-Alice" {
+		t.Fatalf("erased keys = %v, want the two records and the collector entity of Alice", erasedKeys)
+	}
+	for _, key := range []string{first, second} {
+		record := decode(t, c.evaluate("QueryProofRecord", key))
+		if _, exposed := record["collector_name"]; exposed {
+			t.Errorf("record %s still holds the collector name", key)
+		}
+	}
+	record := decode(t, c.evaluate("QueryProofRecord", kept))
+	if record["collector_name"] != "Bob" {
+		t.Errorf("collector_name of another collector = %v, want Bob", record["collector_name"])
+	}
+
+	_, err := c.ledger.Submit("EraseCollectorPersonalData", []string{"again"}, transient)
+	if err == nil || strings.Contains(err.Error(), "Alice") {
+		t.Errorf("repeated erasure error = %v, want an error without the collector name", err)
+	}
+}
@@ -0,0 +1,120 @@
+package memory_test
+
+import (
+	"strings"
+	"testing"
+)
+
+func proposeVoid(c *contractTest) string {
+	c.t.Helper()
+	c.submit("SetGovernanceConfig", `{"quorum":2}`)
+	response := c.submit("ProposeViolationAction", `{"incrementField":"press_increment","action":"void","reason":"over weight"}`)
+	proposal := response["proposal"].(map[string]interface{})
+	if proposal["status"] != "pending" {
+		c.t.Fatalf("proposal status = %v, want pending", proposal["status"])
+	}
+	return proposal["proposalId"].(string)
+}
+
+func TestApproveProposalExecutesUnchangedViolations(t *testing.T) {
+	c := newContractTest(t)
+	recordID := c.createRecord("S1", "Alice", 1, 1, 10)
+	c.createTicket("T-1", "S1", 1, 5)
+	proposalID := proposeVoid(c)
+
+	c.as(org2Admin)
+	proposal := c.submit("ApproveProposal", proposalID)["proposal"].(map[string]interface{})
+	if proposal["status"] != "executed" {
+		t.Fatalf("proposal status = %v, want executed", proposal["status"])
+	}
+
+	record := decode(t, c.evaluate("QueryProofRecord", recordID))
+	if record["status"] != "voided" {
+		t.Errorf("record status = %v, want voided", record["status"])
+	}
+}
+
+func TestApproveProposalDetectsRecordsAddedToViolatingGroup(t *testing.T) {
+	c := newContractTest(t)
+	recordID := c.createRecord("S1", "Alice", 1, 1, 10)
+	c.createTicket("T-1", "S1", 1, 5)
+	proposalID := proposeVoid(c)
+
+	// The new record is not fingerprinted but changes the violation of its group
+	c.createRecord("S1", "Alice", 2, 1, 3)
+
+	c.as(org2Admin)
+	proposal := c.submit("ApproveProposal", proposalID)["proposal"].(map[string]interface{})
+	if proposal["status"] != "stale" {
+		t.Fatalf("proposal status = %v, want stale", proposal["status"])
+	}
+	if reason, _ := proposal["staleReason"].(string); !strings.Contains(reason, "violations changed") {
+		t.Errorf("stale reason = %q", reason)
+	}
+
+	record := decode(t, c.evaluate("QueryProofRecord", recordID))
+	if record["status"] == "voided" {
+		t.Errorf("record of a stale proposal was voided")
+	}
+}
+
+func TestProposalRejectsCollectorScope(t *testing.T) {
+	c := newContractTest(t)
+	c.createRecord("S1", "Alice", 1, 1, 10)
+	c.createTicket("T-1", "S1", 1, 5)
+
+	err := c.submitError("ProposeViolationAction",
+		`{"incrementField":"press_increment","action":"void","options":{"scope":{"collector_name":"Alice"}}}`)
+	if !strings.Contains(err.Error(), "cannot be scoped to a collector") {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestApproveProposalComparesWithTheProposerSponsors(t *testing.T) {
+	c := newContractTest(t)
+	recordID := c.createRecord("S1", "Alice", 1, 1, 10)
+	c.createTicket("T-1", "S1", 1, 5)
+	other := c.createRecord("S2", "Bob", 2, 2, 10)
+	c.createTicket("T-2", "S2", 2, 5)
+	c.submit("SetGovernanceConfig", `{"quorum":2}`)
+
+	c.as(identityWith("verifier", "S1"))
+	response := c.submit("ProposeViolationAction", `{"incrementField":"press_increment","action":"void","reason":"over weight"}`)
+	proposalID := response["proposal"].(map[string]interface{})["proposalId"].(string)
+
+	// The approver sees the violation of S2 as well, which the proposal does not cover
+	c.as(org2Admin)
+	proposal := c.submit("ApproveProposal", proposalID)["proposal"].(map[string]interface{})
+	if proposal["status"] != "executed" {
+		t.Fatalf("proposal status = %v (%v), want executed", proposal["status"], proposal["staleReason"])
+	}
+
+	if record := decode(t, c.evaluate("QueryProofRecord", recordID)); record["status"] != "voided" {
+		t.Errorf("record of S1 status = %v, want voided", record["status"])
+	}
+	if record := decode(t, c.evaluate("QueryProofRecord", other)); record["status"] == "voided" {
+		t.Errorf("record of S2 was voided by a proposal of S1")
+	}
+}
+
+func TestQueryProposalsHidesOtherSponsors(t *testing.T) {
+	c := newContractTest(t)
+	c.createRecord("S1", "Alice", 1, 1, 10)
+	c.createTicket("T-1", "S1", 1, 5)
+	c.createRecord("S2", "Bob", 2, 2, 10)
+	c.createTicket("T-2", "S2", 2, 5)
+	proposalID := proposeVoid(c)
+
+	c.as(identityWith("verifier", "S1"))
+	if _, err := c.ledger.Evaluate("QueryProposal", []string{proposalID}, nil); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("QueryProposal of a proposal holding S2 results error = %v, want does not exist", err)
+	}
+	if proposals := c.queryAll("QueryProposalsByStatus", "pending"); len(proposals) != 0 {
+		t.Errorf("verifier of S1 sees %d proposals, want 0", len(proposals))
+	}
+
+	c.as(identityWith("verifier", "S1,S2"))
+	if proposals := c.queryAll("QueryProposalsByStatus", "pending"); len(proposals) != 1 {
+		t.Errorf("verifier of S1 and S2 sees %d proposals, want 1", len(proposals))
+	}
+}
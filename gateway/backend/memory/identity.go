@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-chaincode-go/pkg/attrmgr"
+	"github.com/hyperledger/fabric-protos-go/msp"
+)
+
+// Identity is the client identity transactions are invoked with
+type Identity struct {
+	MSPID      string            `json:"mspId"`
+	Name       string            `json:"name"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// DefaultIdentity returns an Org1MSP administrator holding every contract role
+func DefaultIdentity() Identity {
+	return Identity{
+		MSPID: "Org1MSP",
+		Name:  "admin",
+		Attributes: map[string]string{
+			"role": "admin,collector,weighbridge,verifier,auditor",
+		},
+	}
+}
+
+// serialize returns the creator of transactions invoked by the identity: a serialized
+// identity holding a self-signed certificate that carries the attributes the way the
+// Fabric CA does
+func (id Identity) serialize() ([]byte, error) {
+	if id.MSPID == "" {
+		return nil, fmt.Errorf("identity MSP ID is required")
+	}
+	if id.Name == "" {
+		return nil, fmt.Errorf("identity name is required")
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate identity key: %v", err)
+	}
+
+	attributes := id.Attributes
+	if attributes == nil {
+		attributes = map[string]string{}
+	}
+	attributesJSON, err := json.Marshal(&attrmgr.Attributes{Attrs: attributes})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal identity attributes: %v", err)
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(now.UnixNano()),
+		Subject:      pkix.Name{CommonName: id.Name, Organization: []string{id.MSPID}},
+		Issuer:       pkix.Name{CommonName: "ca." + id.MSPID},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(365 * 24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtraExtensions: []pkix.Extension{{
+			Id:    attrmgr.AttrOID,
+			Value: attributesJSON,
+		}},
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create identity certificate: %v", err)
+	}
+
+	serialized := &msp.SerializedIdentity{
+		Mspid:   id.MSPID,
+		IdBytes: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate}),
+	}
+	creator, err := proto.Marshal(serialized)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal identity: %v", err)
+	}
+	return creator, nil
+}
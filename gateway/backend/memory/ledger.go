@@ -0,0 +1,263 @@
+// Package memory runs the proof records contract against an in-process ledger. It stands
+// in for a Fabric network in tests and local tooling: transactions are executed by the
+// real contract, committed one at a time, and their chaincode events are kept in order.
+package memory
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/yourusername/proof-records-chaincode/chaincode"
+	"github.com/yourusername/proof-records-chaincode/gateway/backend"
+)
+
+// ChaincodeEvent is a chaincode event committed to the in-process ledger
+type ChaincodeEvent struct {
+	BlockNumber   uint64 `json:"blockNumber"`
+	TransactionID string `json:"transactionId"`
+	ChaincodeName string `json:"chaincodeName"`
+	EventName     string `json:"eventName"`
+	Payload       []byte `json:"payload"`
+}
+
+// Ledger is an in-process ledger running the proof records contract. Every committed
+// transaction is its own block.
+type Ledger struct {
+	mu        sync.Mutex
+	name      string
+	channel   string
+	chaincode *contractapi.ContractChaincode
+	identity  Identity
+	creator   []byte
+	path      string
+
+	height     uint64
+	state      map[string][]byte
+	private    map[string]map[string][]byte
+	validation map[string][]byte
+	history    map[string][]*queryresult.KeyModification
+	events     []ChaincodeEvent
+}
+
+var _ backend.Backend = (*Ledger)(nil)
+
+// New creates an empty in-process ledger invoking transactions as the given identity
+func New(identity Identity) (*Ledger, error) {
+	proofRecordsChaincode, err := contractapi.NewChaincode(new(chaincode.ProofRecordsContract))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create proof records chaincode: %v", err)
+	}
+
+	ledger := &Ledger{
+		name:       "proof-records",
+		channel:    "mychannel",
+		chaincode:  proofRecordsChaincode,
+		state:      map[string][]byte{},
+		private:    map[string]map[string][]byte{},
+		validation: map[string][]byte{},
+		history:    map[string][]*queryresult.KeyModification{},
+	}
+	if err := ledger.SetIdentity(identity); err != nil {
+		return nil, err
+	}
+	return ledger, nil
+}
+
+// SetIdentity changes the identity subsequent transactions are invoked with
+func (l *Ledger) SetIdentity(identity Identity) error {
+	creator, err := identity.serialize()
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.identity = identity
+	l.creator = creator
+	return nil
+}
+
+// Identity returns the identity transactions are invoked with
+func (l *Ledger) Identity() Identity {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.identity
+}
+
+// Submit runs a transaction and commits its writes and event when the contract succeeds
+func (l *Ledger) Submit(transaction string, args []string, transient map[string][]byte) ([]byte, error) {
+	return l.invoke(nil, transaction, args, transient, true)
+}
+
+// Evaluate runs a transaction and discards its writes
+func (l *Ledger) Evaluate(transaction string, args []string, transient map[string][]byte) ([]byte, error) {
+	return l.invoke(nil, transaction, args, transient, false)
+}
+
+// Client invokes transactions on a shared ledger with its own identity
+type Client struct {
+	ledger   *Ledger
+	identity Identity
+	creator  []byte
+}
+
+var _ backend.Backend = (*Client)(nil)
+
+// As returns a client invoking transactions on the ledger with the given identity. The
+// identity of the ledger itself is left unchanged.
+func (l *Ledger) As(identity Identity) (*Client, error) {
+	creator, err := identity.serialize()
+	if err != nil {
+		return nil, err
+	}
+	return &Client{ledger: l, identity: identity, creator: creator}, nil
+}
+
+// Identity returns the identity the client invokes transactions with
+func (c *Client) Identity() Identity {
+	return c.identity
+}
+
+// Submit runs a transaction as the client and commits it when the contract succeeds
+func (c *Client) Submit(transaction string, args []string, transient map[string][]byte) ([]byte, error) {
+	return c.ledger.invoke(c.creator, transaction, args, transient, true)
+}
+
+// Evaluate runs a transaction as the client and discards its writes
+func (c *Client) Evaluate(transaction string, args []string, transient map[string][]byte) ([]byte, error) {
+	return c.ledger.invoke(c.creator, transaction, args, transient, false)
+}
+
+// Close releases the client, the ledger stays open for its other clients
+func (c *Client) Close() error {
+	return nil
+}
+
+// Close releases the ledger
+func (l *Ledger) Close() error {
+	return nil
+}
+
+// Height returns the number of committed blocks
+func (l *Ledger) Height() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.height
+}
+
+// Events returns the chaincode events committed at or after the given block
+func (l *Ledger) Events(fromBlock uint64) []ChaincodeEvent {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	events := []ChaincodeEvent{}
+	for _, event := range l.events {
+		if event.BlockNumber >= fromBlock {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// invoke runs a transaction with the given creator, or the ledger's identity when it is nil
+func (l *Ledger) invoke(creator []byte, transaction string, args []string, transient map[string][]byte, commit bool) ([]byte, error) {
+	txID, err := newTxID()
+	if err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if creator == nil {
+		creator = l.creator
+	}
+	stub := newTxStub(l, txID, creator, transaction, args, transient)
+	response := l.chaincode.Invoke(stub)
+	if response.Status >= shim.ERRORTHRESHOLD {
+		return nil, &backend.ChaincodeError{Transaction: transaction, Message: response.Message}
+	}
+
+	if commit {
+		l.commit(stub)
+		if l.path != "" {
+			if err := l.save(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return response.Payload, nil
+}
+
+// commit applies the writes and event of a transaction as a new block
+func (l *Ledger) commit(stub *txStub) {
+	l.height++
+	txTimestamp, _ := stub.GetTxTimestamp()
+
+	for _, key := range sortedKeys(stub.writes) {
+		value := stub.writes[key]
+		l.state[key] = value
+		l.addHistory(key, stub.txID, txTimestamp, value, false)
+	}
+	for key := range stub.deletes {
+		if _, exists := l.state[key]; !exists {
+			continue
+		}
+		delete(l.state, key)
+		l.addHistory(key, stub.txID, txTimestamp, nil, true)
+	}
+
+	for collection, writes := range stub.privateWrites {
+		if l.private[collection] == nil {
+			l.private[collection] = map[string][]byte{}
+		}
+		for key, value := range writes {
+			l.private[collection][key] = value
+		}
+	}
+	for collection, deletes := range stub.privateDeletes {
+		for key := range deletes {
+			delete(l.private[collection], key)
+		}
+	}
+
+	for key, policy := range stub.validationWrites {
+		l.validation[key] = policy
+	}
+
+	if stub.eventName != "" {
+		l.events = append(l.events, ChaincodeEvent{
+			BlockNumber:   l.height,
+			TransactionID: stub.txID,
+			ChaincodeName: l.name,
+			EventName:     stub.eventName,
+			Payload:       stub.eventPayload,
+		})
+	}
+}
+
+func (l *Ledger) addHistory(key string, txID string, txTimestamp *timestamp.Timestamp, value []byte, isDelete bool) {
+	l.history[key] = append(l.history[key], &queryresult.KeyModification{
+		TxId:      txID,
+		Value:     value,
+		Timestamp: txTimestamp,
+		IsDelete:  isDelete,
+	})
+}
+
+// newTxID returns a random transaction ID in the hex encoded SHA-256 form used by Fabric
+func newTxID() (string, error) {
+	nonce := make([]byte, 24)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate transaction ID: %v", err)
+	}
+	hash := sha256.Sum256(nonce)
+	return hex.EncodeToString(hash[:]), nil
+}
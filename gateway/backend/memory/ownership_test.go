@@ -0,0 +1,37 @@
+package memory_test
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCreateTicketKeepsAnExistingTicket(t *testing.T) {
+	c := newContractTest(t)
+	ticketKey := c.createTicket("T1", "", 1, 10)
+
+	// The ticket of another increment with the same ID would be stored under the same key
+	c.as(org2Admin)
+	ticket := map[string]interface{}{"id": "T1", "incrementId": 2, "receivedWeight": 99}
+	response := c.submit("CreateTicket", encode(t, ticket))
+	if response["success"] != false || !strings.Contains(response["message"].(string), "already exists") {
+		t.Fatalf("CreateTicket of an existing ID = %v, want already exists", response)
+	}
+
+	stored := decode(t, c.evaluate("QueryTicket", ticketKey))
+	if stored["ownerMSP"] != "Org1MSP" || stored["incrementId"] != 1.0 || stored["receivedWeight"] != 10.0 {
+		t.Errorf("ticket = %v, want the ticket of Org1MSP", stored)
+	}
+}
+
+func TestTransferOwnershipMovesTheEndorsementPolicy(t *testing.T) {
+	c := newContractTest(t)
+	recordID := c.createRecord("S1", "Alice", 1, 1, 10)
+
+	c.submit("TransferOwnership", recordID, "Org2MSP")
+
+	policy := decode(t, c.evaluate("QueryRecordEndorsementPolicy", recordID))
+	orgs, _ := policy["orgs"].([]interface{})
+	if len(orgs) != 1 || orgs[0] != "Org2MSP" {
+		t.Errorf("endorsing organizations = %v, want the new owner Org2MSP", policy["orgs"])
+	}
+}
@@ -0,0 +1,139 @@
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+)
+
+// snapshot is the file representation of a ledger. Values are kept as strings so
+// that the JSON documents of the contract stay readable.
+type snapshot struct {
+	Height     uint64                       `json:"height"`
+	State      map[string]string            `json:"state"`
+	Private    map[string]map[string]string `json:"private"`
+	Validation map[string][]byte            `json:"validation"`
+	History    map[string][]historyEntry    `json:"history"`
+	Events     []ChaincodeEvent             `json:"events"`
+}
+
+type historyEntry struct {
+	TxID     string `json:"txId"`
+	Value    string `json:"value,omitempty"`
+	Seconds  int64  `json:"seconds"`
+	Nanos    int32  `json:"nanos"`
+	IsDelete bool   `json:"isDelete,omitempty"`
+}
+
+// Open creates a ledger persisted to the given file. The file is read when it exists
+// and rewritten after every committed transaction.
+func Open(path string, identity Identity) (*Ledger, error) {
+	ledger, err := New(identity)
+	if err != nil {
+		return nil, err
+	}
+	ledger.path = path
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return ledger, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ledger file %s: %v", path, err)
+	}
+
+	var saved snapshot
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("failed to parse ledger file %s: %v", path, err)
+	}
+	ledger.restore(saved)
+	return ledger, nil
+}
+
+func (l *Ledger) restore(saved snapshot) {
+	l.height = saved.Height
+	for key, value := range saved.State {
+		l.state[key] = []byte(value)
+	}
+	for collection, values := range saved.Private {
+		l.private[collection] = map[string][]byte{}
+		for key, value := range values {
+			l.private[collection][key] = []byte(value)
+		}
+	}
+	for key, policy := range saved.Validation {
+		l.validation[key] = policy
+	}
+	for key, entries := range saved.History {
+		for _, entry := range entries {
+			var value []byte
+			if !entry.IsDelete {
+				value = []byte(entry.Value)
+			}
+			l.history[key] = append(l.history[key], &queryresult.KeyModification{
+				TxId:      entry.TxID,
+				Value:     value,
+				Timestamp: &timestamp.Timestamp{Seconds: entry.Seconds, Nanos: entry.Nanos},
+				IsDelete:  entry.IsDelete,
+			})
+		}
+	}
+	l.events = append(l.events, saved.Events...)
+}
+
+// save writes the ledger to its file, replacing the previous content atomically
+func (l *Ledger) save() error {
+	saved := snapshot{
+		Height:     l.height,
+		State:      map[string]string{},
+		Private:    map[string]map[string]string{},
+		Validation: l.validation,
+		History:    map[string][]historyEntry{},
+		Events:     l.events,
+	}
+	for key, value := range l.state {
+		saved.State[key] = string(value)
+	}
+	for collection, values := range l.private {
+		saved.Private[collection] = map[string]string{}
+		for key, value := range values {
+			saved.Private[collection][key] = string(value)
+		}
+	}
+	for key, modifications := range l.history {
+		for _, modification := range modifications {
+			saved.History[key] = append(saved.History[key], historyEntry{
+				TxID:     modification.TxId,
+				Value:    string(modification.Value),
+				Seconds:  modification.Timestamp.GetSeconds(),
+				Nanos:    modification.Timestamp.GetNanos(),
+				IsDelete: modification.IsDelete,
+			})
+		}
+	}
+
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal ledger: %v", err)
+	}
+	temporary, err := os.CreateTemp(filepath.Dir(l.path), filepath.Base(l.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write ledger file %s: %v", l.path, err)
+	}
+	defer os.Remove(temporary.Name())
+	if _, err := temporary.Write(data); err != nil {
+		temporary.Close()
+		return fmt.Errorf("failed to write ledger file %s: %v", l.path, err)
+	}
+	if err := temporary.Close(); err != nil {
+		return fmt.Errorf("failed to write ledger file %s: %v", l.path, err)
+	}
+	if err := os.Rename(temporary.Name(), l.path); err != nil {
+		return fmt.Errorf("failed to write ledger file %s: %v", l.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package memory_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func hashDetails(t *testing.T, details map[string]interface{}) string {
+	t.Helper()
+	detailsJSON, err := json.Marshal(details)
+	if err != nil {
+		t.Fatalf("failed to encode details: %v", err)
+	}
+	hash := sha256.Sum256(detailsJSON)
+	return hex.EncodeToString(hash[:])
+}
+
+func TestPrivateDetailsHashIsSalted(t *testing.T) {
+	c := newContractTest(t)
+	recordID := c.createRecord("S1", "Alice", 1, 1, 10)
+
+	record := decode(t, c.evaluate("QueryProofRecord", recordID))
+	if _, exposed := record["salt"]; exposed {
+		t.Errorf("query result exposes the salt of the private details")
+	}
+	if record["collector_name"] != "Alice" {
+		t.Errorf("collector_name = %v, want Alice", record["collector_name"])
+	}
+
+	reference := record["privateData"].(map[string]interface{})
+	details := map[string]interface{}{
+		"collector_name": "Alice",
+		"docType":        "proofRecordPrivate",
+		"recordId":       recordID,
+	}
+	if reference["hash"] == hashDetails(t, details) {
+		t.Errorf("private data hash can be recomputed from the collector name alone")
+	}
+	details["salt"] = "salt-1"
+	if reference["hash"] != hashDetails(t, details) {
+		t.Errorf("private data hash does not cover the salted details")
+	}
+}
+
+func TestPrivateTicketFieldsSaltIsOptionalInTheArguments(t *testing.T) {
+	c := newContractTest(t)
+	c.submit("SetPrivacyConfig", `{"privateFields":["collector_name"],"ticketPrivateFields":["driver"]}`)
+
+	// Tickets passed in the transaction arguments keep working without a salt
+	response := c.submit("CreateTicket", `{"id":"T-1","incrementId":1,"receivedWeight":5,"driver":"Bob"}`)
+	if response["success"] != true {
+		t.Fatalf("CreateTicket without salt failed: %v", response["message"])
+	}
+	ticket := decode(t, c.evaluate("QueryTicket", response["ticketKey"].(string)))
+	details := map[string]interface{}{"driver": "Bob", "docType": "ticketPrivate", "recordId": "TICKET_T-1"}
+	if ticket["privateData"].(map[string]interface{})["hash"] != hashDetails(t, details) {
+		t.Errorf("private data hash does not cover the unsalted details")
+	}
+
+	payload := map[string][]byte{"ticket": []byte(`{"id":"T-2","incrementId":1,"receivedWeight":5,"driver":"Bob"}`)}
+	response = c.submitTransient(payload, "CreateTicketPrivate")
+	if message, _ := response["message"].(string); response["success"] != false || !strings.Contains(message, "secret salt is required") {
+		t.Fatalf("CreateTicketPrivate without salt = %v", response)
+	}
+
+	payload["salt"] = []byte("ticket-salt")
+	response = c.submitTransient(payload, "CreateTicketPrivate")
+	if response["success"] != true {
+		t.Fatalf("CreateTicketPrivate with salt failed: %v", response["message"])
+	}
+}
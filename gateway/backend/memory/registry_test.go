@@ -0,0 +1,103 @@
+package memory_test
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRecordsAreValidatedAgainstTheLedgerByDefault(t *testing.T) {
+	c := newContractTest(t)
+	record := encode(t, map[string]interface{}{
+		"sponsor_id":       "S9",
+		"proof_short_id":   "P-S9-1",
+		"collector_name":   "Alice",
+		"bulk_name":        "Bulk S9",
+		"bulk_short_id":    "B-S9",
+		"traceChainType":   "standard",
+		"parent_increment": 1,
+		"press_increment":  1,
+		"store_increment":  1,
+		"chained_weight":   10,
+	})
+
+	response := c.submitTransient(c.salt(), "CreateProofRecord", record)
+	if message, _ := response["message"].(string); response["success"] != false || !strings.Contains(message, "sponsor S9 is not registered") {
+		t.Errorf("record of an unregistered sponsor: %v", response)
+	}
+
+	// A collector registered for another sponsor does not validate records of S9
+	c.register("S1", "Alice")
+	c.submit("CreateSponsor", `{"sponsor_id":"S9","name":"Sponsor S9"}`)
+	c.submit("CreateBulk", `{"bulk_short_id":"B-S9","bulk_name":"Bulk S9","sponsor_id":"S9"}`)
+	response = c.submitTransient(c.salt(), "CreateProofRecord", record)
+	if message, _ := response["message"].(string); response["success"] != false || !strings.Contains(message, "collector is not registered") {
+		t.Errorf("record of a collector of another sponsor: %v", response)
+	}
+}
+
+func TestCollectorNamesAreUniquePerSponsor(t *testing.T) {
+	c := newContractTest(t)
+	c.register("S1", "Alice")
+	c.register("S2", "Alice")
+
+	c.createRecord("S1", "Alice", 1, 1, 10)
+	c.createRecord("S2", "Alice", 2, 2, 10)
+
+	err := c.submitError("CreateCollector", `{"collectorId":"C-other","collector_name":"Alice","sponsor_id":"S1"}`)
+	if !strings.Contains(err.Error(), "already registered for sponsor S1") {
+		t.Errorf("namesake collector error = %v", err)
+	}
+	err = c.submitError("CreateCollector", `{"collectorId":"C-none","collector_name":"Carol"}`)
+	if !strings.Contains(err.Error(), "sponsor_id are required") {
+		t.Errorf("collector without sponsor error = %v", err)
+	}
+}
+
+func TestCollectorQueriesAreRedacted(t *testing.T) {
+	c := newContractTest(t)
+	c.createRecord("S1", "Alice", 1, 1, 10)
+	c.submit("SetRedactionConfig", `{"profiles":{"auditor":{"include":["*"],"exclude":["collector_name","name"]}}}`)
+
+	c.as(identityWith("auditor", "S1"))
+	collectors := c.queryAll("QueryAllCollectors")
+	if len(collectors) != 1 {
+		t.Fatalf("auditor sees %d collectors, want 1", len(collectors))
+	}
+	if _, exposed := collectors[0]["Record"].(map[string]interface{})["collector_name"]; exposed {
+		t.Errorf("QueryAllCollectors exposes the collector name")
+	}
+	collector := decode(t, c.evaluate("QueryCollector", "C-S1-Alice"))
+	if _, exposed := collector["collector_name"]; exposed {
+		t.Errorf("QueryCollector exposes the collector name")
+	}
+	if collector["collectorId"] != "C-S1-Alice" {
+		t.Errorf("collectorId = %v, want C-S1-Alice", collector["collectorId"])
+	}
+}
+
+func TestReferencesRespectFieldExclusions(t *testing.T) {
+	c := newContractTest(t)
+	recordID := c.createRecord("S1", "Alice", 1, 1, 10)
+	c.submit("SetRedactionConfig", `{"profiles":{"auditor":{"include":["*"],"exclude":["collector_name","name"]}}}`)
+
+	c.as(identityWith("auditor", "S1"))
+	record := decode(t, c.evaluate("QueryProofRecord", recordID))
+	references := record["references"].(map[string]interface{})
+	sponsor := references["sponsor"].(map[string]interface{})
+	if _, exposed := sponsor["name"]; exposed {
+		t.Errorf("referenced sponsor exposes its name")
+	}
+	if sponsor["sponsor_id"] != "S1" {
+		t.Errorf("referenced sponsor = %v", sponsor)
+	}
+	if _, exposed := references["collector"].(map[string]interface{})["collector_name"]; exposed {
+		t.Errorf("referenced collector exposes its name")
+	}
+
+	// References are dropped with their field
+	c.as(identityWith("public", ""))
+	record = decode(t, c.evaluate("QueryProofRecord", recordID))
+	if _, exposed := record["references"]; exposed {
+		t.Errorf("public caller sees the references of the record")
+	}
+}
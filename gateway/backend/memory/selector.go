@@ -0,0 +1,328 @@
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+)
+
+// couchQuery is the subset of a CouchDB Mango query evaluated by the in-process ledger
+type couchQuery struct {
+	Selector map[string]interface{} `json:"selector"`
+	Sort     []interface{}          `json:"sort"`
+	Limit    int                    `json:"limit"`
+	Skip     int                    `json:"skip"`
+}
+
+type sortField struct {
+	path       string
+	descending bool
+}
+
+// runQuery evaluates a rich query over the JSON documents of a key space
+func runQuery(values map[string][]byte, query string) ([]*queryresult.KV, error) {
+	var parsed couchQuery
+	if err := json.Unmarshal([]byte(query), &parsed); err != nil {
+		return nil, fmt.Errorf("invalid query: %v", err)
+	}
+	if parsed.Selector == nil {
+		return nil, fmt.Errorf("invalid query: selector is required")
+	}
+	sortFields, err := parseSort(parsed.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	type match struct {
+		kv       *queryresult.KV
+		document map[string]interface{}
+	}
+	matches := []match{}
+	for _, key := range sortedKeys(values) {
+		var document map[string]interface{}
+		if err := json.Unmarshal(values[key], &document); err != nil {
+			continue
+		}
+		document["_id"] = key
+		ok, err := matchSelector(document, parsed.Selector)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matches = append(matches, match{kv: &queryresult.KV{Key: key, Value: values[key]}, document: document})
+		}
+	}
+
+	if len(sortFields) > 0 {
+		sort.SliceStable(matches, func(i, j int) bool {
+			for _, field := range sortFields {
+				left, _ := lookupField(matches[i].document, field.path)
+				right, _ := lookupField(matches[j].document, field.path)
+				order := compareValues(left, right)
+				if order == 0 {
+					continue
+				}
+				if field.descending {
+					return order > 0
+				}
+				return order < 0
+			}
+			return false
+		})
+	}
+
+	results := []*queryresult.KV{}
+	for i, m := range matches {
+		if i < parsed.Skip {
+			continue
+		}
+		if parsed.Limit > 0 && len(results) == parsed.Limit {
+			break
+		}
+		results = append(results, m.kv)
+	}
+	return results, nil
+}
+
+func parseSort(entries []interface{}) ([]sortField, error) {
+	fields := []sortField{}
+	for _, entry := range entries {
+		switch value := entry.(type) {
+		case string:
+			fields = append(fields, sortField{path: value})
+		case map[string]interface{}:
+			for path, direction := range value {
+				fields = append(fields, sortField{path: path, descending: direction == "desc"})
+			}
+		default:
+			return nil, fmt.Errorf("invalid query: unsupported sort entry %v", entry)
+		}
+	}
+	return fields, nil
+}
+
+// matchSelector reports whether a document satisfies a selector
+func matchSelector(document map[string]interface{}, selector map[string]interface{}) (bool, error) {
+	for field, condition := range selector {
+		var ok bool
+		var err error
+		switch field {
+		case "$and", "$or", "$nor":
+			ok, err = matchCombination(document, field, condition)
+		case "$not":
+			sub, isSelector := condition.(map[string]interface{})
+			if !isSelector {
+				return false, fmt.Errorf("invalid query: $not requires a selector")
+			}
+			ok, err = matchSelector(document, sub)
+			ok = !ok
+		default:
+			value, exists := lookupField(document, field)
+			ok, err = matchCondition(value, exists, condition)
+		}
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func matchCombination(document map[string]interface{}, operator string, condition interface{}) (bool, error) {
+	selectors, isList := condition.([]interface{})
+	if !isList {
+		return false, fmt.Errorf("invalid query: %s requires a list of selectors", operator)
+	}
+	matched := 0
+	for _, entry := range selectors {
+		sub, isSelector := entry.(map[string]interface{})
+		if !isSelector {
+			return false, fmt.Errorf("invalid query: %s requires a list of selectors", operator)
+		}
+		ok, err := matchSelector(document, sub)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			matched++
+		}
+	}
+	switch operator {
+	case "$and":
+		return matched == len(selectors), nil
+	case "$or":
+		return matched > 0, nil
+	default:
+		return matched == 0, nil
+	}
+}
+
+// matchCondition evaluates the condition of a single field. A condition is either a
+// value the field must equal, an object of operators, or an object of sub-field selectors.
+func matchCondition(value interface{}, exists bool, condition interface{}) (bool, error) {
+	operators, isObject := condition.(map[string]interface{})
+	if !isObject || len(operators) == 0 {
+		return exists && compareValues(value, condition) == 0, nil
+	}
+
+	for operator, argument := range operators {
+		var ok bool
+		switch operator {
+		case "$eq":
+			ok = exists && compareValues(value, argument) == 0
+		case "$ne":
+			ok = !exists || compareValues(value, argument) != 0
+		case "$gt":
+			ok = exists && sameKind(value, argument) && compareValues(value, argument) > 0
+		case "$gte":
+			ok = exists && sameKind(value, argument) && compareValues(value, argument) >= 0
+		case "$lt":
+			ok = exists && sameKind(value, argument) && compareValues(value, argument) < 0
+		case "$lte":
+			ok = exists && sameKind(value, argument) && compareValues(value, argument) <= 0
+		case "$exists":
+			want, isBool := argument.(bool)
+			if !isBool {
+				return false, fmt.Errorf("invalid query: $exists requires a boolean")
+			}
+			ok = exists == want
+		case "$in", "$nin":
+			candidates, isList := argument.([]interface{})
+			if !isList {
+				return false, fmt.Errorf("invalid query: %s requires a list", operator)
+			}
+			found := false
+			for _, candidate := range candidates {
+				if exists && compareValues(value, candidate) == 0 {
+					found = true
+					break
+				}
+			}
+			ok = found == (operator == "$in")
+		case "$regex":
+			pattern, isString := argument.(string)
+			if !isString {
+				return false, fmt.Errorf("invalid query: $regex requires a string")
+			}
+			expression, err := regexp.Compile(pattern)
+			if err != nil {
+				return false, fmt.Errorf("invalid query: %v", err)
+			}
+			text, isText := value.(string)
+			ok = isText && expression.MatchString(text)
+		case "$not":
+			matched, err := matchCondition(value, exists, argument)
+			if err != nil {
+				return false, err
+			}
+			ok = !matched
+		default:
+			if strings.HasPrefix(operator, "$") {
+				return false, fmt.Errorf("invalid query: unsupported operator %s", operator)
+			}
+			sub, isDocument := value.(map[string]interface{})
+			subValue, subExists := lookupField(sub, operator)
+			matched, err := matchCondition(subValue, isDocument && subExists, argument)
+			if err != nil {
+				return false, err
+			}
+			ok = matched
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// lookupField resolves a dotted field path in a document
+func lookupField(document map[string]interface{}, path string) (interface{}, bool) {
+	var current interface{} = document
+	for _, part := range strings.Split(path, ".") {
+		object, isObject := current.(map[string]interface{})
+		if !isObject {
+			return nil, false
+		}
+		value, exists := object[part]
+		if !exists {
+			return nil, false
+		}
+		current = value
+	}
+	return current, true
+}
+
+// kindRank orders JSON types the way CouchDB collates them
+func kindRank(value interface{}) int {
+	switch value.(type) {
+	case nil:
+		return 0
+	case bool:
+		return 1
+	case float64:
+		return 2
+	case string:
+		return 3
+	case []interface{}:
+		return 4
+	default:
+		return 5
+	}
+}
+
+func sameKind(a, b interface{}) bool {
+	return kindRank(a) == kindRank(b)
+}
+
+// compareValues compares two decoded JSON values using CouchDB collation
+func compareValues(a, b interface{}) int {
+	rankA, rankB := kindRank(a), kindRank(b)
+	if rankA != rankB {
+		if rankA < rankB {
+			return -1
+		}
+		return 1
+	}
+
+	switch left := a.(type) {
+	case nil:
+		return 0
+	case bool:
+		right := b.(bool)
+		if left == right {
+			return 0
+		}
+		if !left {
+			return -1
+		}
+		return 1
+	case float64:
+		right := b.(float64)
+		if left < right {
+			return -1
+		}
+		if left > right {
+			return 1
+		}
+		return 0
+	case string:
+		return strings.Compare(left, b.(string))
+	case []interface{}:
+		right := b.([]interface{})
+		for i := 0; i < len(left) && i < len(right); i++ {
+			if order := compareValues(left[i], right[i]); order != 0 {
+				return order
+			}
+		}
+		return len(left) - len(right)
+	default:
+		if reflect.DeepEqual(a, b) {
+			return 0
+		}
+		return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+	}
+}
@@ -0,0 +1,261 @@
+package memory
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// txStub is the chaincode stub of a single transaction. Reads see the committed
+// ledger, as on a peer; writes are collected and only applied when the
+// transaction is committed.
+type txStub struct {
+	*shimtest.MockStub
+	ledger    *Ledger
+	txID      string
+	timestamp time.Time
+	creator   []byte
+	args      [][]byte
+	transient map[string][]byte
+
+	writes           map[string][]byte
+	deletes          map[string]bool
+	privateWrites    map[string]map[string][]byte
+	privateDeletes   map[string]map[string]bool
+	validationWrites map[string][]byte
+	eventName        string
+	eventPayload     []byte
+}
+
+func newTxStub(ledger *Ledger, txID string, creator []byte, transaction string, args []string, transient map[string][]byte) *txStub {
+	stubArgs := [][]byte{[]byte(transaction)}
+	for _, arg := range args {
+		stubArgs = append(stubArgs, []byte(arg))
+	}
+	if transient == nil {
+		transient = map[string][]byte{}
+	}
+	return &txStub{
+		MockStub:         shimtest.NewMockStub(ledger.name, nil),
+		ledger:           ledger,
+		txID:             txID,
+		timestamp:        time.Now(),
+		creator:          creator,
+		args:             stubArgs,
+		transient:        transient,
+		writes:           map[string][]byte{},
+		deletes:          map[string]bool{},
+		privateWrites:    map[string]map[string][]byte{},
+		privateDeletes:   map[string]map[string]bool{},
+		validationWrites: map[string][]byte{},
+	}
+}
+
+func (s *txStub) GetArgs() [][]byte {
+	return s.args
+}
+
+func (s *txStub) GetStringArgs() []string {
+	strargs := make([]string, 0, len(s.args))
+	for _, arg := range s.args {
+		strargs = append(strargs, string(arg))
+	}
+	return strargs
+}
+
+func (s *txStub) GetFunctionAndParameters() (string, []string) {
+	allargs := s.GetStringArgs()
+	if len(allargs) == 0 {
+		return "", []string{}
+	}
+	return allargs[0], allargs[1:]
+}
+
+func (s *txStub) GetTxID() string {
+	return s.txID
+}
+
+func (s *txStub) GetChannelID() string {
+	return s.ledger.channel
+}
+
+func (s *txStub) GetCreator() ([]byte, error) {
+	return s.creator, nil
+}
+
+func (s *txStub) GetTransient() (map[string][]byte, error) {
+	return s.transient, nil
+}
+
+func (s *txStub) GetTxTimestamp() (*timestamp.Timestamp, error) {
+	return &timestamp.Timestamp{Seconds: s.timestamp.Unix(), Nanos: int32(s.timestamp.Nanosecond())}, nil
+}
+
+func (s *txStub) GetState(key string) ([]byte, error) {
+	return s.ledger.state[key], nil
+}
+
+func (s *txStub) PutState(key string, value []byte) error {
+	if key == "" {
+		return fmt.Errorf("key must not be an empty string")
+	}
+	delete(s.deletes, key)
+	s.writes[key] = value
+	return nil
+}
+
+func (s *txStub) DelState(key string) error {
+	delete(s.writes, key)
+	s.deletes[key] = true
+	return nil
+}
+
+func (s *txStub) SetStateValidationParameter(key string, ep []byte) error {
+	s.validationWrites[key] = ep
+	return nil
+}
+
+func (s *txStub) GetStateValidationParameter(key string) ([]byte, error) {
+	return s.ledger.validation[key], nil
+}
+
+func (s *txStub) GetStateByRange(startKey, endKey string) (shim.StateQueryIteratorInterface, error) {
+	keys := sortedKeys(s.ledger.state)
+	results := []*queryresult.KV{}
+	for _, key := range keys {
+		if key >= startKey && (endKey == "" || key < endKey) {
+			results = append(results, &queryresult.KV{Key: key, Value: s.ledger.state[key]})
+		}
+	}
+	return &stateIterator{results: results}, nil
+}
+
+func (s *txStub) GetQueryResult(query string) (shim.StateQueryIteratorInterface, error) {
+	results, err := runQuery(s.ledger.state, query)
+	if err != nil {
+		return nil, err
+	}
+	return &stateIterator{results: results}, nil
+}
+
+func (s *txStub) GetHistoryForKey(key string) (shim.HistoryQueryIteratorInterface, error) {
+	modifications := s.ledger.history[key]
+	results := make([]*queryresult.KeyModification, 0, len(modifications))
+	for i := len(modifications) - 1; i >= 0; i-- {
+		results = append(results, modifications[i])
+	}
+	return &historyIterator{results: results}, nil
+}
+
+func (s *txStub) GetPrivateData(collection, key string) ([]byte, error) {
+	return s.ledger.private[collection][key], nil
+}
+
+func (s *txStub) GetPrivateDataHash(collection, key string) ([]byte, error) {
+	value, exists := s.ledger.private[collection][key]
+	if !exists {
+		return nil, nil
+	}
+	hash := sha256.Sum256(value)
+	return hash[:], nil
+}
+
+func (s *txStub) PutPrivateData(collection string, key string, value []byte) error {
+	if key == "" {
+		return fmt.Errorf("key must not be an empty string")
+	}
+	if s.privateWrites[collection] == nil {
+		s.privateWrites[collection] = map[string][]byte{}
+	}
+	delete(s.privateDeletes[collection], key)
+	s.privateWrites[collection][key] = value
+	return nil
+}
+
+func (s *txStub) DelPrivateData(collection, key string) error {
+	if s.privateDeletes[collection] == nil {
+		s.privateDeletes[collection] = map[string]bool{}
+	}
+	delete(s.privateWrites[collection], key)
+	s.privateDeletes[collection][key] = true
+	return nil
+}
+
+func (s *txStub) GetPrivateDataQueryResult(collection, query string) (shim.StateQueryIteratorInterface, error) {
+	results, err := runQuery(s.ledger.private[collection], query)
+	if err != nil {
+		return nil, err
+	}
+	return &stateIterator{results: results}, nil
+}
+
+func (s *txStub) SetEvent(name string, payload []byte) error {
+	if name == "" {
+		return fmt.Errorf("event name can not be empty string")
+	}
+	s.eventName = name
+	s.eventPayload = payload
+	return nil
+}
+
+func (s *txStub) InvokeChaincode(chaincodeName string, args [][]byte, channel string) pb.Response {
+	return shim.Error(fmt.Sprintf("chaincode %s is not available in the in-process ledger", chaincodeName))
+}
+
+type stateIterator struct {
+	results []*queryresult.KV
+	next    int
+}
+
+func (it *stateIterator) HasNext() bool {
+	return it.next < len(it.results)
+}
+
+func (it *stateIterator) Next() (*queryresult.KV, error) {
+	if !it.HasNext() {
+		return nil, fmt.Errorf("no more results")
+	}
+	it.next++
+	return it.results[it.next-1], nil
+}
+
+func (it *stateIterator) Close() error {
+	return nil
+}
+
+type historyIterator struct {
+	results []*queryresult.KeyModification
+	next    int
+}
+
+func (it *historyIterator) HasNext() bool {
+	return it.next < len(it.results)
+}
+
+func (it *historyIterator) Next() (*queryresult.KeyModification, error) {
+	if !it.HasNext() {
+		return nil, fmt.Errorf("no more results")
+	}
+	it.next++
+	return it.results[it.next-1], nil
+}
+
+func (it *historyIterator) Close() error {
+	return nil
+}
+
+func sortedKeys(values map[string][]byte) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,127 @@
+package memory_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/yourusername/proof-records-chaincode/gateway/backend/memory"
+)
+
+// identityWith returns an Org1MSP identity holding the given roles and sponsors
+func identityWith(roles string, sponsors string) memory.Identity {
+	attributes := map[string]string{"role": roles}
+	if sponsors != "" {
+		attributes["sponsors"] = sponsors
+	}
+	return memory.Identity{MSPID: "Org1MSP", Name: "user", Attributes: attributes}
+}
+
+// queryAll evaluates a query returning a list of documents
+func (c *contractTest) queryAll(transaction string, args ...string) []map[string]interface{} {
+	c.t.Helper()
+	var results []map[string]interface{}
+	result := c.evaluate(transaction, args...)
+	if err := json.Unmarshal(result, &results); err != nil {
+		c.t.Fatalf("invalid result %s: %v", result, err)
+	}
+	return results
+}
+
+func TestPublicCallerSeesEverySponsorRedacted(t *testing.T) {
+	c := newContractTest(t)
+	c.createRecord("S1", "Alice", 1, 1, 10)
+	c.createRecord("S2", "Bob", 2, 2, 10)
+
+	c.as(identityWith("public", ""))
+	records := c.queryAll("QueryAllProofRecords")
+	if len(records) != 2 {
+		t.Fatalf("public caller sees %d records, want 2", len(records))
+	}
+	for _, result := range records {
+		record := result["Record"].(map[string]interface{})
+		if _, exposed := record["collector_name"]; exposed {
+			t.Errorf("public caller sees the collector name of %v", result["Key"])
+		}
+		if _, exposed := record["ownerMSP"]; exposed {
+			t.Errorf("public caller sees the owner of %v", result["Key"])
+		}
+	}
+}
+
+func TestSponsorCallerSeesItsSponsorOnly(t *testing.T) {
+	c := newContractTest(t)
+	c.createRecord("S1", "Alice", 1, 1, 10)
+	c.createRecord("S2", "Bob", 2, 2, 10)
+
+	// The public role adds no visibility to a collector of one sponsor
+	c.as(identityWith("collector,public", "S1"))
+	records := c.queryAll("QueryAllProofRecords")
+	if len(records) != 1 {
+		t.Fatalf("collector of S1 sees %d records, want 1", len(records))
+	}
+	record := records[0]["Record"].(map[string]interface{})
+	if record["sponsor_id"] != "S1" || record["collector_name"] != "Alice" {
+		t.Errorf("collector of S1 sees %v", record)
+	}
+}
+
+func TestClientsInvokeWithTheirOwnIdentity(t *testing.T) {
+	c := newContractTest(t)
+	c.createRecord("S1", "Alice", 1, 1, 10)
+	c.createRecord("S2", "Bob", 2, 2, 10)
+
+	client, err := c.ledger.As(identityWith("collector", "S2"))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	result, err := client.Evaluate("QueryAllProofRecords", nil, nil)
+	if err != nil {
+		t.Fatalf("QueryAllProofRecords failed: %v", err)
+	}
+	var records []map[string]interface{}
+	if err := json.Unmarshal(result, &records); err != nil {
+		t.Fatalf("invalid result %s: %v", result, err)
+	}
+	if len(records) != 1 || records[0]["Record"].(map[string]interface{})["sponsor_id"] != "S2" {
+		t.Errorf("client of S2 sees %v", records)
+	}
+
+	// The ledger keeps invoking with its own identity
+	if all := c.queryAll("QueryAllProofRecords"); len(all) != 2 {
+		t.Errorf("ledger identity sees %d records, want 2", len(all))
+	}
+}
+
+func TestDuplicatesAreDetectedAmongVisibleSponsors(t *testing.T) {
+	c := newContractTest(t)
+	c.createRecord("S1", "Alice", 1, 1, 10)
+	c.register("S2", "Bob")
+
+	// A record of S1 with the same increments must not be revealed to a collector of S2
+	c.as(identityWith("collector", "S2"))
+	c.createRecord("S2", "Bob", 1, 1, 10)
+
+	record := map[string]interface{}{
+		"sponsor_id":       "S2",
+		"proof_short_id":   "P-S2-1",
+		"collector_name":   "Bob",
+		"bulk_name":        "Bulk S2",
+		"bulk_short_id":    "B-S2",
+		"traceChainType":   "standard",
+		"parent_increment": 1,
+		"press_increment":  1,
+		"store_increment":  1,
+		"chained_weight":   10,
+	}
+	response := c.submitTransient(c.salt(), "CreateProofRecord", encode(t, record))
+	if response["success"] != false || response["message"] != "Duplicate record found" {
+		t.Fatalf("CreateProofRecord of a duplicate = %v", response)
+	}
+	existing, _ := response["existingRecords"].([]interface{})
+	if len(existing) != 1 {
+		t.Fatalf("existing records = %v, want the record of S2 only", existing)
+	}
+	if sponsorID := existing[0].(map[string]interface{})["Record"].(map[string]interface{})["sponsor_id"]; sponsorID != "S2" {
+		t.Errorf("existing record of sponsor %v revealed to a collector of S2", sponsorID)
+	}
+}
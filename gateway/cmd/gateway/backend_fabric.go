@@ -0,0 +1,55 @@
+//go:build fabric
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/yourusername/proof-records-chaincode/gateway/backend/fabric"
+	"github.com/yourusername/proof-records-chaincode/gateway/server"
+)
+
+const backendName = "fabric"
+
+// fabricIdentity is the Fabric identity of a client in the clients file
+type fabricIdentity struct {
+	MSPID    string `json:"mspId"`
+	CertPath string `json:"certPath"`
+	KeyPath  string `json:"keyPath"`
+}
+
+// newClients connects every client to the Fabric Gateway peer configured by the FABRIC_*
+// environment variables, with the identity of its configuration
+func newClients(configs []clientConfig) ([]server.Client, error) {
+	clients := []server.Client{}
+	for _, config := range configs {
+		var identity fabricIdentity
+		if err := json.Unmarshal(config.Identity, &identity); err != nil {
+			closeClients(clients)
+			return nil, fmt.Errorf("invalid identity of client %s: %v", config.Name, err)
+		}
+		if identity.MSPID == "" || identity.CertPath == "" || identity.KeyPath == "" {
+			closeClients(clients)
+			return nil, fmt.Errorf("invalid identity of client %s: mspId, certPath and keyPath are required", config.Name)
+		}
+
+		connection := fabric.ConfigFromEnv()
+		connection.MSPID = identity.MSPID
+		connection.CertPath = identity.CertPath
+		connection.KeyPath = identity.KeyPath
+		gateway, err := fabric.Connect(connection)
+		if err != nil {
+			closeClients(clients)
+			return nil, fmt.Errorf("failed to connect client %s: %v", config.Name, err)
+		}
+		clients = append(clients, server.Client{Name: config.Name, TokenSHA256: config.TokenSHA256, Backend: gateway})
+	}
+	return clients, nil
+}
+
+func closeClients(clients []server.Client) {
+	for _, client := range clients {
+		client.Backend.Close()
+	}
+}
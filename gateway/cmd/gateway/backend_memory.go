@@ -0,0 +1,43 @@
+//go:build !fabric
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+
+	"github.com/yourusername/proof-records-chaincode/gateway/backend/memory"
+	"github.com/yourusername/proof-records-chaincode/gateway/server"
+)
+
+const backendName = "in-process"
+
+var inMemory = flag.Bool("in-memory", false, "serve an in-process ledger, for tests and local development only")
+
+// newClients creates an in-process ledger shared by the clients, each invoking transactions
+// with the memory.Identity of its configuration
+func newClients(configs []clientConfig) ([]server.Client, error) {
+	if !*inMemory {
+		return nil, fmt.Errorf("the in-process ledger keeps its data in memory and trusts the configured identities, pass -in-memory to serve it or build with -tags fabric")
+	}
+
+	ledger, err := memory.New(memory.DefaultIdentity())
+	if err != nil {
+		return nil, err
+	}
+
+	clients := []server.Client{}
+	for _, config := range configs {
+		var identity memory.Identity
+		if err := json.Unmarshal(config.Identity, &identity); err != nil {
+			return nil, fmt.Errorf("invalid identity of client %s: %v", config.Name, err)
+		}
+		client, err := ledger.As(identity)
+		if err != nil {
+			return nil, fmt.Errorf("invalid identity of client %s: %v", config.Name, err)
+		}
+		clients = append(clients, server.Client{Name: config.Name, TokenSHA256: config.TokenSHA256, Backend: client})
+	}
+	return clients, nil
+}
@@ -0,0 +1,108 @@
+// Command gateway exposes every ProofRecordsContract transaction as a REST endpoint.
+//
+// Callers authenticate with a bearer token. The clients file named by -clients (or
+// GATEWAY_CLIENTS) lists the SHA-256 hash of the token of every client and the identity its
+// transactions run with, so the role, tenancy and redaction checks of the contract apply to
+// each caller:
+//
+//	[{"name":"alice","tokenSha256":"<hex sha256 of the token>","identity":{...}}]
+//
+// Built with the fabric tag, the gateway connects to a Fabric Gateway peer configured through
+// the FABRIC_* environment variables, and a client identity names its MSP, certificate and
+// key: {"mspId":"Org1MSP","certPath":"...","keyPath":"..."}. The default build runs the
+// contract against an in-process ledger, which suits tests and local development only. It
+// keeps its data in memory and the client identities carry any attributes they declare, so
+// it starts only when -in-memory is given. Its identities look like
+// {"mspId":"Org1MSP","name":"alice","attributes":{"role":"collector"}}.
+//
+//	go build -tags fabric ./cmd/gateway
+//	go build ./cmd/gateway
+//
+// The OpenAPI description is served at /openapi.json and printed by the -openapi flag.
+// It and /healthz are the only endpoints served without a token.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/yourusername/proof-records-chaincode/gateway/server"
+)
+
+// clientConfig is an entry of the clients file
+type clientConfig struct {
+	Name        string          `json:"name"`
+	TokenSHA256 string          `json:"tokenSha256"`
+	Identity    json.RawMessage `json:"identity"`
+}
+
+func main() {
+	address := flag.String("address", getEnv("GATEWAY_ADDRESS", ":8080"), "address to listen on")
+	clientsPath := flag.String("clients", getEnv("GATEWAY_CLIENTS", ""), "JSON file of the clients allowed to call the gateway")
+	printOpenAPI := flag.Bool("openapi", false, "print the OpenAPI description and exit")
+	flag.Parse()
+
+	if *printOpenAPI {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(server.OpenAPI()); err != nil {
+			log.Fatalf("Error writing OpenAPI description: %v", err)
+		}
+		return
+	}
+
+	configs, err := readClients(*clientsPath)
+	if err != nil {
+		log.Fatalf("Error reading clients: %v", err)
+	}
+	clients, err := newClients(configs)
+	if err != nil {
+		log.Fatalf("Error creating backend: %v", err)
+	}
+	defer func() {
+		for _, client := range clients {
+			client.Backend.Close()
+		}
+	}()
+
+	log.Printf("Proof records gateway listening on %s (%s backend, %d clients)", *address, backendName, len(clients))
+	if err := http.ListenAndServe(*address, server.New(clients)); err != nil {
+		log.Fatalf("Error serving gateway: %v", err)
+	}
+}
+
+// readClients reads and checks the clients file
+func readClients(path string) ([]clientConfig, error) {
+	if path == "" {
+		return nil, fmt.Errorf("a clients file is required, set -clients or GATEWAY_CLIENTS")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var configs []clientConfig
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, fmt.Errorf("invalid clients file %s: %v", path, err)
+	}
+	if len(configs) == 0 {
+		return nil, fmt.Errorf("clients file %s lists no clients", path)
+	}
+	for _, config := range configs {
+		if config.Name == "" || len(config.TokenSHA256) != 64 || len(config.Identity) == 0 {
+			return nil, fmt.Errorf("invalid clients file %s: every client needs a name, a tokenSha256 and an identity", path)
+		}
+	}
+	return configs, nil
+}
+
+func getEnv(name string, fallback string) string {
+	if value, exists := os.LookupEnv(name); exists {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,39 @@
+//go:build fabric
+
+package main
+
+import (
+	"context"
+
+	"github.com/yourusername/proof-records-chaincode/gateway/backend/fabric"
+	"github.com/yourusername/proof-records-chaincode/gateway/projector"
+)
+
+const sourceName = "fabric"
+
+// project follows the chaincode events of the peer configured by the FABRIC_*
+// environment variables until ctx is done
+func project(ctx context.Context, p *projector.Projector) error {
+	gateway, err := fabric.Connect(fabric.ConfigFromEnv())
+	if err != nil {
+		return err
+	}
+	defer gateway.Close()
+
+	events, err := gateway.ChaincodeEvents(ctx, p.StartBlock())
+	if err != nil {
+		return err
+	}
+	for event := range events {
+		err := apply(ctx, p, projector.Event{
+			BlockNumber:   event.BlockNumber,
+			TransactionID: event.TransactionID,
+			EventName:     event.EventName,
+			Payload:       event.Payload,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
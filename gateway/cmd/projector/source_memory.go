@@ -0,0 +1,39 @@
+//go:build !fabric
+
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/yourusername/proof-records-chaincode/chaincode"
+	"github.com/yourusername/proof-records-chaincode/gateway/backend/memory"
+	"github.com/yourusername/proof-records-chaincode/gateway/projector"
+)
+
+const sourceName = "in-process"
+
+// project applies the events of the in-process ledger kept in PROJECTOR_LEDGER, by
+// default the proofctl-ledger.json written by proofctl, and returns
+func project(ctx context.Context, p *projector.Projector) error {
+	// Standard output is reserved for the projector's own output
+	chaincode.SetLogOutput(os.Stderr)
+	ledger, err := memory.Open(getEnv("PROJECTOR_LEDGER", "proofctl-ledger.json"), memory.DefaultIdentity())
+	if err != nil {
+		return err
+	}
+	defer ledger.Close()
+
+	for _, event := range ledger.Events(p.StartBlock()) {
+		err := apply(ctx, p, projector.Event{
+			BlockNumber:   event.BlockNumber,
+			TransactionID: event.TransactionID,
+			EventName:     event.EventName,
+			Payload:       event.Payload,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+//go:build fabric
+
+package main
+
+import (
+	"github.com/yourusername/proof-records-chaincode/gateway/backend"
+	"github.com/yourusername/proof-records-chaincode/gateway/backend/fabric"
+)
+
+// newBackend connects to the Fabric Gateway peer configured by the FABRIC_* environment variables
+func newBackend() (backend.Backend, error) {
+	return fabric.Connect(fabric.ConfigFromEnv())
+}
@@ -0,0 +1,30 @@
+//go:build !fabric
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/yourusername/proof-records-chaincode/chaincode"
+	"github.com/yourusername/proof-records-chaincode/gateway/backend"
+	"github.com/yourusername/proof-records-chaincode/gateway/backend/memory"
+)
+
+// newBackend opens the simulated ledger kept in PROOFCTL_LEDGER, by default
+// proofctl-ledger.json in the working directory. PROOFCTL_IDENTITY optionally holds
+// the JSON identity transactions are invoked with, for example
+// {"mspId":"Org1MSP","name":"alice","attributes":{"role":"verifier"}}.
+func newBackend() (backend.Backend, error) {
+	identity := memory.DefaultIdentity()
+	if value := os.Getenv("PROOFCTL_IDENTITY"); value != "" {
+		if err := json.Unmarshal([]byte(value), &identity); err != nil {
+			return nil, fmt.Errorf("invalid PROOFCTL_IDENTITY: %v", err)
+		}
+	}
+
+	// Standard output is reserved for results
+	chaincode.SetLogOutput(os.Stderr)
+	return memory.Open(getEnv("PROOFCTL_LEDGER", "proofctl-ledger.json"), identity)
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Transient map keys read by the contract
+const (
+	transientRecordKey = "record"
+	transientTicketKey = "ticket"
+	transientSaltKey   = "salt"
+)
+
+// submit commits a transaction through the configured backend
+func submit(transaction string, args []string, transient map[string][]byte) ([]byte, error) {
+	b, err := newBackend()
+	if err != nil {
+		return nil, err
+	}
+	defer b.Close()
+	return b.Submit(transaction, args, transient)
+}
+
+// evaluate runs a transaction through the configured backend without committing it
+func evaluate(transaction string, args ...string) ([]byte, error) {
+	b, err := newBackend()
+	if err != nil {
+		return nil, err
+	}
+	defer b.Close()
+	return b.Evaluate(transaction, args, nil)
+}
+
+// recordKey turns a record ID as printed by proofctl back into its composite key. The parts of
+// composite keys are separated by NUL, which proofctl prints as \u0000.
+func recordKey(recordID string) string {
+	return strings.ReplaceAll(recordID, `\u0000`, "\x00")
+}
+
+// readDocument returns the JSON document given by -data, -file or, without either, standard input
+func readDocument(data string, file string) (string, error) {
+	if data != "" && file != "" {
+		return "", fmt.Errorf("-data and -file are mutually exclusive")
+	}
+	if data == "" {
+		var content []byte
+		var err error
+		if file == "" || file == "-" {
+			content, err = io.ReadAll(os.Stdin)
+		} else {
+			content, err = os.ReadFile(file)
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read document: %v", err)
+		}
+		data = string(content)
+	}
+	if !json.Valid([]byte(data)) {
+		return "", fmt.Errorf("document is not valid JSON")
+	}
+	return data, nil
+}
+
+func recordCreate(args []string) error {
+	flags, out := newFlagSet("record create")
+	file := flags.String("file", "", "file holding the proof record, - for standard input")
+	data := flags.String("data", "", "proof record as inline JSON")
+	private := flags.Bool("private", false, "pass the record in the transient map")
+	salt := flags.String("salt", "", "secret salt of the record commitment, required with -private")
+	if _, err := parseFlags(flags, out, args); err != nil {
+		return err
+	}
+	if *private && *salt == "" {
+		return fmt.Errorf("-salt is required with -private, keep it to verify the record against its commitment")
+	}
+
+	record, err := readDocument(*data, *file)
+	if err != nil {
+		return err
+	}
+
+	transaction := "CreateProofRecord"
+	transactionArgs := []string{record}
+	transient := map[string][]byte{}
+	if *private {
+		transaction = "CreateProofRecordPrivate"
+		transactionArgs = []string{}
+		transient[transientRecordKey] = []byte(record)
+	}
+	if *salt != "" {
+		transient[transientSaltKey] = []byte(*salt)
+	}
+
+	result, err := submit(transaction, transactionArgs, transient)
+	if err != nil {
+		return err
+	}
+	return out.created(result, "recordId", "record")
+}
+
+func recordGet(args []string) error {
+	flags, out := newFlagSet("record get")
+	positional, err := parseFlags(flags, out, args, "<recordId>")
+	if err != nil {
+		return err
+	}
+
+	result, err := evaluate("QueryProofRecord", recordKey(positional[0]))
+	if err != nil {
+		return err
+	}
+	return out.document(result)
+}
+
+func recordList(args []string) error {
+	flags, out := newFlagSet("record list")
+	field := flags.String("field", "", "field to match")
+	value := flags.String("value", "", "value the field must have")
+	if _, err := parseFlags(flags, out, args); err != nil {
+		return err
+	}
+
+	var result []byte
+	var err error
+	if *field != "" {
+		result, err = evaluate("QueryRecordsByField", *field, *value)
+	} else {
+		result, err = evaluate("QueryAllProofRecords")
+	}
+	if err != nil {
+		return err
+	}
+	return out.list(result, recordColumns)
+}
+
+func recordHistory(args []string) error {
+	flags, out := newFlagSet("record history")
+	positional, err := parseFlags(flags, out, args, "<recordId>")
+	if err != nil {
+		return err
+	}
+
+	result, err := evaluate("GetRecordHistory", recordKey(positional[0]))
+	if err != nil {
+		return err
+	}
+	return out.history(result)
+}
+
+func ticketCreate(args []string) error {
+	flags, out := newFlagSet("ticket create")
+	file := flags.String("file", "", "file holding the ticket, - for standard input")
+	data := flags.String("data", "", "ticket as inline JSON")
+	private := flags.Bool("private", false, "pass the ticket in the transient map")
+	salt := flags.String("salt", "", "secret salt of the private ticket fields, required with -private")
+	if _, err := parseFlags(flags, out, args); err != nil {
+		return err
+	}
+	if *private && *salt == "" {
+		return fmt.Errorf("-salt is required with -private")
+	}
+
+	ticket, err := readDocument(*data, *file)
+	if err != nil {
+		return err
+	}
+
+	transaction := "CreateTicket"
+	transactionArgs := []string{ticket}
+	transient := map[string][]byte{}
+	if *private {
+		transaction = "CreateTicketPrivate"
+		transactionArgs = []string{}
+		transient[transientTicketKey] = []byte(ticket)
+	}
+	if *salt != "" {
+		transient[transientSaltKey] = []byte(*salt)
+	}
+
+	result, err := submit(transaction, transactionArgs, transient)
+	if err != nil {
+		return err
+	}
+	return out.created(result, "ticketKey", "ticket")
+}
+
+func ticketGet(args []string) error {
+	flags, out := newFlagSet("ticket get")
+	positional, err := parseFlags(flags, out, args, "<ticketKey>")
+	if err != nil {
+		return err
+	}
+
+	result, err := evaluate("QueryTicket", positional[0])
+	if err != nil {
+		return err
+	}
+	return out.document(result)
+}
+
+func ticketList(args []string) error {
+	flags, out := newFlagSet("ticket list")
+	field := flags.String("field", "", "field to match")
+	value := flags.String("value", "", "value the field must have")
+	if _, err := parseFlags(flags, out, args); err != nil {
+		return err
+	}
+
+	var result []byte
+	var err error
+	if *field != "" {
+		result, err = evaluate("QueryTicketsByField", *field, *value)
+	} else {
+		result, err = evaluate("QueryAllTickets")
+	}
+	if err != nil {
+		return err
+	}
+	return out.list(result, ticketColumns)
+}
+
+func comparePress(args []string) error {
+	return compare("compare press", "CompareWeightsByPressIncrement", args)
+}
+
+func compareStore(args []string) error {
+	return compare("compare store", "CompareWeightsByStoreIncrement", args)
+}
+
+// compare evaluates a weight comparison, or submits it when -apply is given
+func compare(name string, transaction string, args []string) error {
+	flags, out := newFlagSet(name)
+	deleteViolations := flags.Bool("delete", false, "delete the records rejected by the comparison")
+	options := flags.String("options", "", "comparison options as inline JSON")
+	apply := flags.Bool("apply", false, "submit the comparison instead of a dry run")
+	if _, err := parseFlags(flags, out, args); err != nil {
+		return err
+	}
+
+	transactionArgs := []string{strconv.FormatBool(*deleteViolations)}
+	if *options != "" {
+		if !json.Valid([]byte(*options)) {
+			return fmt.Errorf("options are not valid JSON")
+		}
+		transaction += "WithOptions"
+		transactionArgs = append(transactionArgs, *options)
+	}
+
+	var result []byte
+	var err error
+	if *apply {
+		result, err = submit(transaction, transactionArgs, nil)
+	} else {
+		result, err = evaluate(transaction, transactionArgs...)
+	}
+	if err != nil {
+		return err
+	}
+	return out.comparison(result, !*apply)
+}
@@ -0,0 +1,126 @@
+// Command proofctl runs reconciliations and lookups against the proof records contract.
+//
+// Subcommands mirror the contract transactions:
+//
+//	proofctl record create [-file path | -data json] [-private] [-salt salt]
+//	proofctl record get <recordId>
+//	proofctl record list [-field name -value value]
+//	proofctl record history <recordId>
+//	proofctl ticket create [-file path | -data json] [-private] [-salt salt]
+//	proofctl ticket get <ticketKey>
+//	proofctl ticket list [-field name -value value]
+//	proofctl compare press|store [-delete] [-options json] [-apply]
+//
+// Every subcommand accepts -output table (the default) or -output json. Comparisons are
+// dry runs unless -apply is given: the transaction is evaluated and nothing is committed.
+//
+// By default proofctl works against a simulated ledger kept in a local file. Built with
+// the fabric tag it connects to a Fabric Gateway peer configured through the FABRIC_*
+// environment variables instead:
+//
+//	go build ./cmd/proofctl
+//	go build -tags fabric ./cmd/proofctl
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// command is a proofctl subcommand
+type command struct {
+	usage string
+	run   func(args []string) error
+}
+
+var commands = map[string]command{
+	"record create":  {"record create [-file path | -data json] [-private] [-salt salt]", recordCreate},
+	"record get":     {"record get <recordId>", recordGet},
+	"record list":    {"record list [-field name -value value]", recordList},
+	"record history": {"record history <recordId>", recordHistory},
+	"ticket create":  {"ticket create [-file path | -data json] [-private] [-salt salt]", ticketCreate},
+	"ticket get":     {"ticket get <ticketKey>", ticketGet},
+	"ticket list":    {"ticket list [-field name -value value]", ticketList},
+	"compare press":  {"compare press [-delete] [-options json] [-apply]", comparePress},
+	"compare store":  {"compare store [-delete] [-options json] [-apply]", compareStore},
+}
+
+// stdout receives the results of proofctl, even when the contract logging of an
+// in-process backend is redirected
+var stdout = os.Stdout
+
+// errUsage is returned when the command line does not name a subcommand
+var errUsage = errors.New("usage")
+
+func main() {
+	err := run(os.Args[1:])
+	if err == errUsage || err == flag.ErrHelp {
+		printUsage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) < 2 {
+		return errUsage
+	}
+	cmd, exists := commands[args[0]+" "+args[1]]
+	if !exists {
+		return errUsage
+	}
+	return cmd.run(args[2:])
+}
+
+func printUsage() {
+	usages := []string{}
+	for _, cmd := range commands {
+		usages = append(usages, cmd.usage)
+	}
+	sort.Strings(usages)
+
+	fmt.Fprintln(os.Stderr, "Usage:")
+	for _, usage := range usages {
+		fmt.Fprintf(os.Stderr, "  proofctl %s\n", usage)
+	}
+	fmt.Fprintln(os.Stderr, "\nEvery subcommand accepts -output table|json.")
+}
+
+func getEnv(name string, fallback string) string {
+	if value, exists := os.LookupEnv(name); exists {
+		return value
+	}
+	return fallback
+}
+
+// newFlagSet creates the flags of a subcommand, including the shared -output flag
+func newFlagSet(name string) (*flag.FlagSet, *printer) {
+	flags := flag.NewFlagSet("proofctl "+name, flag.ContinueOnError)
+	out := &printer{w: stdout}
+	flags.StringVar(&out.format, "output", formatTable, "output format: table or json")
+	return flags, out
+}
+
+// parseFlags parses the flags of a subcommand and returns its positional arguments
+func parseFlags(flags *flag.FlagSet, out *printer, args []string, positional ...string) ([]string, error) {
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if out.format != formatTable && out.format != formatJSON {
+		return nil, fmt.Errorf("invalid output format %s, expected %s or %s", out.format, formatTable, formatJSON)
+	}
+	if flags.NArg() != len(positional) {
+		if len(positional) == 0 {
+			return nil, fmt.Errorf("%s takes no arguments", flags.Name())
+		}
+		return nil, fmt.Errorf("%s expects %s", flags.Name(), strings.Join(positional, " "))
+	}
+	return flags.Args(), nil
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Output formats
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+)
+
+// column is a table column read from a field of the listed documents
+type column struct {
+	title string
+	field string
+}
+
+var recordColumns = []column{
+	{"RECORD ID", "_key"},
+	{"SPONSOR", "sponsor_id"},
+	{"PROOF", "proof_short_id"},
+	{"BULK", "bulk_short_id"},
+	{"PRESS", "press_increment"},
+	{"STORE", "store_increment"},
+	{"CHAINED WEIGHT", "chained_weight"},
+	{"OWNER", "ownerMSP"},
+}
+
+var ticketColumns = []column{
+	{"TICKET KEY", "_key"},
+	{"ID", "id"},
+	{"SPONSOR", "sponsor_id"},
+	{"INCREMENT", "incrementId"},
+	{"RECEIVED WEIGHT", "receivedWeight"},
+	{"OWNER", "ownerMSP"},
+}
+
+// queryResult is an entry of the list and history results of the contract
+type queryResult struct {
+	Key    string                 `json:"Key"`
+	Record map[string]interface{} `json:"Record"`
+}
+
+// outcome holds the fields shared by the create and comparison responses
+type outcome struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+// printer writes transaction results as a table or as indented JSON
+type printer struct {
+	format string
+	w      io.Writer
+}
+
+func (p *printer) json(result []byte) error {
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, result, "", "  "); err != nil {
+		return fmt.Errorf("invalid result: %v", err)
+	}
+	indented.WriteByte('\n')
+	_, err := p.w.Write(indented.Bytes())
+	return err
+}
+
+// checkOutcome returns the message of a response reporting success false
+func checkOutcome(result []byte) error {
+	var response outcome
+	if err := json.Unmarshal(result, &response); err != nil {
+		return fmt.Errorf("invalid result: %v", err)
+	}
+	if !response.Success {
+		return fmt.Errorf("%s", response.Message)
+	}
+	return nil
+}
+
+// created prints the response of a create transaction
+func (p *printer) created(result []byte, keyField string, documentField string) error {
+	if p.format == formatJSON {
+		if err := p.json(result); err != nil {
+			return err
+		}
+		return checkOutcome(result)
+	}
+	if err := checkOutcome(result); err != nil {
+		return err
+	}
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(result, &response); err != nil {
+		return fmt.Errorf("invalid result: %v", err)
+	}
+	fmt.Fprintf(p.w, "%s: %s\n\n", response["message"], formatValue(response[keyField]))
+	document, _ := response[documentField].(map[string]interface{})
+	return p.fields(document)
+}
+
+// document prints a single document
+func (p *printer) document(result []byte) error {
+	if p.format == formatJSON {
+		return p.json(result)
+	}
+
+	var document map[string]interface{}
+	if err := json.Unmarshal(result, &document); err != nil {
+		return fmt.Errorf("invalid result: %v", err)
+	}
+	return p.fields(document)
+}
+
+// fields prints the fields of a document, one per row
+func (p *printer) fields(document map[string]interface{}) error {
+	names := make([]string, 0, len(document))
+	for name := range document {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	table := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "FIELD\tVALUE")
+	for _, name := range names {
+		fmt.Fprintf(table, "%s\t%s\n", name, formatValue(document[name]))
+	}
+	return table.Flush()
+}
+
+// list prints the documents of a query result, one per row
+func (p *printer) list(result []byte, columns []column) error {
+	if p.format == formatJSON {
+		return p.json(result)
+	}
+
+	var results []queryResult
+	if err := json.Unmarshal(result, &results); err != nil {
+		return fmt.Errorf("invalid result: %v", err)
+	}
+
+	table := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	titles := []string{}
+	for _, col := range columns {
+		titles = append(titles, col.title)
+	}
+	fmt.Fprintln(table, strings.Join(titles, "\t"))
+	for _, entry := range results {
+		cells := []string{}
+		for _, col := range columns {
+			if col.field == "_key" {
+				cells = append(cells, formatValue(entry.Key))
+				continue
+			}
+			cells = append(cells, formatValue(entry.Record[col.field]))
+		}
+		fmt.Fprintln(table, strings.Join(cells, "\t"))
+	}
+	return table.Flush()
+}
+
+// history prints the modifications of a record, newest first
+func (p *printer) history(result []byte) error {
+	if p.format == formatJSON {
+		return p.json(result)
+	}
+
+	var results []queryResult
+	if err := json.Unmarshal(result, &results); err != nil {
+		return fmt.Errorf("invalid result: %v", err)
+	}
+
+	table := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "TX ID\tCHANGE\tCHAINED WEIGHT\tOWNER")
+	for _, entry := range results {
+		change := "write"
+		if entry.Record == nil {
+			change = "delete"
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\n", entry.Key, change,
+			formatValue(entry.Record["chained_weight"]), formatValue(entry.Record["ownerMSP"]))
+	}
+	return table.Flush()
+}
+
+// comparison prints the results, rejections and deletions of a weight comparison
+func (p *printer) comparison(result []byte, dryRun bool) error {
+	if p.format == formatJSON {
+		if err := p.json(result); err != nil {
+			return err
+		}
+		return checkOutcome(result)
+	}
+	if err := checkOutcome(result); err != nil {
+		return err
+	}
+
+	var response struct {
+		Aggregation string `json:"aggregation"`
+		Rejection   string `json:"rejection"`
+		Results     []struct {
+			SponsorID      string  `json:"sponsor_id"`
+			IncrementID    int     `json:"incrementId"`
+			ChainedWeight  float64 `json:"chainedWeight"`
+			ReceivedWeight float64 `json:"receivedWeight"`
+		} `json:"results"`
+		Rejections []struct {
+			RecordID      string  `json:"recordId"`
+			IncrementID   int     `json:"incrementId"`
+			ChainedWeight float64 `json:"chainedWeight"`
+			Reason        string  `json:"reason"`
+		} `json:"rejections"`
+		DeletedRecords []string `json:"deletedRecords"`
+		SkippedRecords []struct {
+			RecordID string `json:"recordId"`
+			Reason   string `json:"reason"`
+		} `json:"skippedRecords"`
+	}
+	if err := json.Unmarshal(result, &response); err != nil {
+		return fmt.Errorf("invalid result: %v", err)
+	}
+
+	if dryRun {
+		fmt.Fprintln(p.w, "Dry run: nothing was committed, use -apply to submit the comparison.")
+	}
+	fmt.Fprintf(p.w, "Aggregation %s, rejection %s\n\n", response.Aggregation, response.Rejection)
+
+	table := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "SPONSOR\tINCREMENT\tCHAINED WEIGHT\tRECEIVED WEIGHT")
+	for _, r := range response.Results {
+		fmt.Fprintf(table, "%s\t%d\t%s\t%s\n", r.SponsorID, r.IncrementID,
+			formatValue(r.ChainedWeight), formatValue(r.ReceivedWeight))
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+
+	if len(response.Rejections) > 0 {
+		fmt.Fprintln(p.w)
+		table = tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(table, "REJECTED RECORD\tINCREMENT\tCHAINED WEIGHT\tREASON")
+		for _, r := range response.Rejections {
+			fmt.Fprintf(table, "%s\t%d\t%s\t%s\n", formatValue(r.RecordID), r.IncrementID, formatValue(r.ChainedWeight), r.Reason)
+		}
+		if err := table.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if len(response.DeletedRecords) > 0 {
+		verb := "Deleted"
+		if dryRun {
+			verb = "Would delete"
+		}
+		fmt.Fprintf(p.w, "\n%s: %s\n", verb, formatValue(strings.Join(response.DeletedRecords, ", ")))
+	}
+	for _, skipped := range response.SkippedRecords {
+		fmt.Fprintf(p.w, "Skipped %s: %s\n", formatValue(skipped.RecordID), skipped.Reason)
+	}
+	return nil
+}
+
+// formatValue renders a decoded JSON value in a table cell
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "-"
+	case string:
+		return strings.ReplaceAll(v, "\x00", `\u0000`)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(encoded)
+	}
+}
@@ -0,0 +1,49 @@
+package projector
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Dialect describes the SQL flavour of the read model database
+type Dialect struct {
+	Name     string
+	Driver   string
+	numbered bool
+}
+
+// Supported dialects
+var (
+	SQLite   = Dialect{Name: "sqlite", Driver: "sqlite"}
+	Postgres = Dialect{Name: "postgres", Driver: "postgres", numbered: true}
+)
+
+// DialectByName returns the dialect with the given name
+func DialectByName(name string) (Dialect, error) {
+	switch name {
+	case SQLite.Name:
+		return SQLite, nil
+	case Postgres.Name:
+		return Postgres, nil
+	}
+	return Dialect{}, fmt.Errorf("unsupported dialect %s, expected %s or %s", name, SQLite.Name, Postgres.Name)
+}
+
+// rebind rewrites the ? placeholders of a statement for the dialect
+func (d Dialect) rebind(query string) string {
+	if !d.numbered {
+		return query
+	}
+	var rebound strings.Builder
+	n := 0
+	for _, c := range query {
+		if c == '?' {
+			n++
+			rebound.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		rebound.WriteRune(c)
+	}
+	return rebound.String()
+}
@@ -0,0 +1,57 @@
+package projector
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Event is a chaincode event of the proof records contract, as delivered by a Fabric
+// Gateway peer or recorded in a fixture
+type Event struct {
+	BlockNumber   uint64          `json:"blockNumber"`
+	TransactionID string          `json:"transactionId"`
+	EventName     string          `json:"eventName"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// envelope is the payload of every chaincode event of the contract
+type envelope struct {
+	SchemaVersion string  `json:"schemaVersion"`
+	EventName     string  `json:"eventName"`
+	TxID          string  `json:"txId"`
+	Timestamp     string  `json:"timestamp"`
+	Events        []entry `json:"events"`
+}
+
+// entry is a single event raised by a transaction
+type entry struct {
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// ReadFixtures reads recorded events, one JSON event per line. Blank lines and lines
+// starting with # are ignored.
+func ReadFixtures(r io.Reader) ([]Event, error) {
+	events := []Event{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Bytes()
+		if len(text) == 0 || text[0] == '#' {
+			continue
+		}
+		var event Event
+		if err := json.Unmarshal(text, &event); err != nil {
+			return nil, fmt.Errorf("invalid event on line %d: %v", line, err)
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read fixtures: %v", err)
+	}
+	return events, nil
+}
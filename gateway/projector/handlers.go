@@ -0,0 +1,347 @@
+package projector
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Document types of the contract
+const (
+	docTypeProofRecord = "proofRecord"
+	docTypeTicket      = "ticket"
+)
+
+// Proof record statuses of the read model
+const (
+	statusActive  = "active"
+	statusDeleted = "deleted"
+	statusVoided  = "voided"
+)
+
+// change is a single event entry being applied within a read model transaction
+type change struct {
+	ctx       context.Context
+	tx        *sql.Tx
+	dialect   Dialect
+	block     uint64
+	txID      string
+	index     int
+	timestamp string
+}
+
+func (c *change) exec(query string, args ...interface{}) error {
+	for i, arg := range args {
+		if value, ok := arg.(string); ok {
+			args[i] = textValue(value)
+		}
+	}
+	if _, err := c.tx.ExecContext(c.ctx, c.dialect.rebind(query), args...); err != nil {
+		return fmt.Errorf("failed to update read model: %v", err)
+	}
+	return nil
+}
+
+// textValue writes the NUL separators of composite keys as \u0000, the way they appear in the JSON
+// payloads, as PostgreSQL text cannot hold NUL
+func textValue(value string) string {
+	return strings.ReplaceAll(value, "\x00", `\u0000`)
+}
+
+// history records that the event entry touched a document
+func (c *change) history(key string, eventType string) error {
+	return c.exec(`INSERT INTO document_history
+		(document_key, transaction_id, event_index, block_number, event_type, event_timestamp)
+		VALUES (?, ?, ?, ?, ?, ?) ON CONFLICT (document_key, transaction_id, event_index) DO NOTHING`,
+		key, c.txID, c.index, c.block, eventType, c.timestamp)
+}
+
+// handlers apply the event types of the contract to the read model
+var handlers = map[string]func(c *change, payload json.RawMessage) error{
+	"ProofRecordCreated":      applyProofRecordCreated,
+	"TicketCreated":           applyTicketCreated,
+	"WeightViolationDetected": applyWeightViolationDetected,
+	"RecordsDeleted":          applyRecordsDeleted,
+	"RecordsVoided":           applyRecordsVoided,
+	"OwnershipTransferred":    applyOwnershipTransferred,
+	"ViolationActionProposed": applyProposal,
+	"ProposalApproved":        applyProposal,
+	"PersonalDataErased":      applyPersonalDataErased,
+	"CreditsIssued":           applyCreditsIssued,
+	"CreditsTransferred":      applyCreditsTransferred,
+	"CreditsRetired":          applyCreditsRetired,
+}
+
+func applyProofRecordCreated(c *change, payload json.RawMessage) error {
+	var created struct {
+		RecordID       string   `json:"recordId"`
+		SponsorID      string   `json:"sponsor_id"`
+		BulkShortID    string   `json:"bulk_short_id"`
+		ChainedWeight  float64  `json:"chained_weight"`
+		StoreIncrement *float64 `json:"store_increment"`
+		PressIncrement *float64 `json:"press_increment"`
+		OwnerMSP       string   `json:"ownerMSP"`
+		CreatedAt      string   `json:"createdAt"`
+	}
+	if err := json.Unmarshal(payload, &created); err != nil {
+		return err
+	}
+
+	err := c.exec(`INSERT INTO proof_records
+		(record_id, sponsor_id, bulk_short_id, chained_weight, press_increment, store_increment,
+		owner_msp, status, created_at, created_tx_id, updated_block)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (record_id) DO UPDATE SET sponsor_id = excluded.sponsor_id,
+		bulk_short_id = excluded.bulk_short_id, chained_weight = excluded.chained_weight,
+		press_increment = excluded.press_increment, store_increment = excluded.store_increment,
+		owner_msp = excluded.owner_msp, status = excluded.status, created_at = excluded.created_at,
+		created_tx_id = excluded.created_tx_id, updated_block = excluded.updated_block`,
+		created.RecordID, nullString(created.SponsorID), nullString(created.BulkShortID), created.ChainedWeight,
+		created.PressIncrement, created.StoreIncrement, nullString(created.OwnerMSP), statusActive,
+		created.CreatedAt, c.txID, c.block)
+	if err != nil {
+		return err
+	}
+	return c.history(created.RecordID, "ProofRecordCreated")
+}
+
+func applyTicketCreated(c *change, payload json.RawMessage) error {
+	var created struct {
+		TicketKey      string  `json:"ticketKey"`
+		TicketID       string  `json:"id"`
+		SponsorID      string  `json:"sponsor_id"`
+		IncrementID    float64 `json:"incrementId"`
+		ReceivedWeight float64 `json:"receivedWeight"`
+		OwnerMSP       string  `json:"ownerMSP"`
+		CreatedAt      string  `json:"createdAt"`
+	}
+	if err := json.Unmarshal(payload, &created); err != nil {
+		return err
+	}
+
+	err := c.exec(`INSERT INTO tickets
+		(ticket_key, ticket_id, sponsor_id, increment_id, received_weight, owner_msp,
+		created_at, created_tx_id, updated_block)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (ticket_key) DO UPDATE SET ticket_id = excluded.ticket_id,
+		sponsor_id = excluded.sponsor_id, increment_id = excluded.increment_id,
+		received_weight = excluded.received_weight, owner_msp = excluded.owner_msp,
+		created_at = excluded.created_at, created_tx_id = excluded.created_tx_id,
+		updated_block = excluded.updated_block`,
+		created.TicketKey, created.TicketID, nullString(created.SponsorID), created.IncrementID,
+		created.ReceivedWeight, nullString(created.OwnerMSP), created.CreatedAt, c.txID, c.block)
+	if err != nil {
+		return err
+	}
+	return c.history(created.TicketKey, "TicketCreated")
+}
+
+func applyWeightViolationDetected(c *change, payload json.RawMessage) error {
+	var violation struct {
+		SponsorID      string   `json:"sponsor_id"`
+		IncrementField string   `json:"incrementField"`
+		IncrementID    int      `json:"incrementId"`
+		ChainedWeight  float64  `json:"chainedWeight"`
+		ReceivedWeight float64  `json:"receivedWeight"`
+		Aggregation    string   `json:"aggregation"`
+		RecordIDs      []string `json:"recordIds"`
+	}
+	if err := json.Unmarshal(payload, &violation); err != nil {
+		return err
+	}
+
+	err := c.exec(`INSERT INTO weight_violations
+		(transaction_id, event_index, block_number, sponsor_id, increment_field, increment_id,
+		chained_weight, received_weight, aggregation, detected_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT (transaction_id, event_index) DO NOTHING`,
+		c.txID, c.index, c.block, nullString(violation.SponsorID), violation.IncrementField, violation.IncrementID,
+		violation.ChainedWeight, violation.ReceivedWeight, nullString(violation.Aggregation), c.timestamp)
+	if err != nil {
+		return err
+	}
+
+	for _, recordID := range violation.RecordIDs {
+		err := c.exec(`INSERT INTO weight_violation_records (transaction_id, event_index, record_id)
+			VALUES (?, ?, ?) ON CONFLICT (transaction_id, event_index, record_id) DO NOTHING`,
+			c.txID, c.index, recordID)
+		if err != nil {
+			return err
+		}
+		if err := c.history(recordID, "WeightViolationDetected"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyRecordsDeleted(c *change, payload json.RawMessage) error {
+	var deleted struct {
+		Reason    string   `json:"reason"`
+		RecordIDs []string `json:"recordIds"`
+	}
+	if err := json.Unmarshal(payload, &deleted); err != nil {
+		return err
+	}
+	return setRecordStatus(c, deleted.RecordIDs, statusDeleted, deleted.Reason, "RecordsDeleted")
+}
+
+func applyRecordsVoided(c *change, payload json.RawMessage) error {
+	var voided struct {
+		Reason     string   `json:"reason"`
+		ProposalID string   `json:"proposalId"`
+		RecordIDs  []string `json:"recordIds"`
+	}
+	if err := json.Unmarshal(payload, &voided); err != nil {
+		return err
+	}
+	return setRecordStatus(c, voided.RecordIDs, statusVoided, voided.Reason, "RecordsVoided")
+}
+
+func setRecordStatus(c *change, recordIDs []string, status string, reason string, eventType string) error {
+	for _, recordID := range recordIDs {
+		err := c.exec(`UPDATE proof_records SET status = ?, status_reason = ?, updated_block = ? WHERE record_id = ?`,
+			status, nullString(reason), c.block, recordID)
+		if err != nil {
+			return err
+		}
+		if err := c.history(recordID, eventType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyOwnershipTransferred(c *change, payload json.RawMessage) error {
+	var transferred struct {
+		Keys     []string `json:"keys"`
+		OwnerMSP string   `json:"ownerMSP"`
+	}
+	if err := json.Unmarshal(payload, &transferred); err != nil {
+		return err
+	}
+
+	for _, key := range transferred.Keys {
+		err := c.exec(`UPDATE proof_records SET owner_msp = ?, updated_block = ? WHERE record_id = ?`,
+			transferred.OwnerMSP, c.block, key)
+		if err != nil {
+			return err
+		}
+		err = c.exec(`UPDATE tickets SET owner_msp = ?, updated_block = ? WHERE ticket_key = ?`,
+			transferred.OwnerMSP, c.block, key)
+		if err != nil {
+			return err
+		}
+		if err := c.history(key, "OwnershipTransferred"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyProposal(c *change, payload json.RawMessage) error {
+	var proposal struct {
+		ProposalID string `json:"proposalId"`
+		Action     string `json:"action"`
+		Status     string `json:"status"`
+		Approvals  int    `json:"approvals"`
+		Quorum     int    `json:"quorum"`
+	}
+	if err := json.Unmarshal(payload, &proposal); err != nil {
+		return err
+	}
+
+	return c.exec(`INSERT INTO proposals
+		(proposal_id, action, status, approvals, quorum, updated_at, updated_block)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (proposal_id) DO UPDATE SET action = excluded.action, status = excluded.status,
+		approvals = excluded.approvals, quorum = excluded.quorum, updated_at = excluded.updated_at,
+		updated_block = excluded.updated_block`,
+		proposal.ProposalID, proposal.Action, proposal.Status, proposal.Approvals, proposal.Quorum,
+		c.timestamp, c.block)
+}
+
+func applyPersonalDataErased(c *change, payload json.RawMessage) error {
+	var erased struct {
+		Key     string `json:"key"`
+		DocType string `json:"docType"`
+	}
+	if err := json.Unmarshal(payload, &erased); err != nil {
+		return err
+	}
+
+	var err error
+	switch erased.DocType {
+	case docTypeProofRecord:
+		err = c.exec(`UPDATE proof_records SET personal_data_erased = ?, updated_block = ? WHERE record_id = ?`,
+			true, c.block, erased.Key)
+	case docTypeTicket:
+		err = c.exec(`UPDATE tickets SET personal_data_erased = ?, updated_block = ? WHERE ticket_key = ?`,
+			true, c.block, erased.Key)
+	}
+	if err != nil {
+		return err
+	}
+	return c.history(erased.Key, "PersonalDataErased")
+}
+
+func applyCreditsIssued(c *change, payload json.RawMessage) error {
+	var issued struct {
+		CreditID  string  `json:"creditId"`
+		SponsorID string  `json:"sponsor_id"`
+		Amount    float64 `json:"amount"`
+		OwnerType string  `json:"ownerType"`
+		Owner     string  `json:"owner"`
+	}
+	if err := json.Unmarshal(payload, &issued); err != nil {
+		return err
+	}
+	return insertCreditMovement(c, "issued", issued.CreditID, "", issued.SponsorID, issued.Amount,
+		issued.OwnerType, issued.Owner, "", "")
+}
+
+func applyCreditsTransferred(c *change, payload json.RawMessage) error {
+	var transferred struct {
+		CreditID     string  `json:"creditId"`
+		FromCreditID string  `json:"fromCreditId"`
+		Amount       float64 `json:"amount"`
+		ToOwnerType  string  `json:"toOwnerType"`
+		ToOwner      string  `json:"toOwner"`
+	}
+	if err := json.Unmarshal(payload, &transferred); err != nil {
+		return err
+	}
+	return insertCreditMovement(c, "transferred", transferred.CreditID, transferred.FromCreditID, "",
+		transferred.Amount, transferred.ToOwnerType, transferred.ToOwner, "", "")
+}
+
+func applyCreditsRetired(c *change, payload json.RawMessage) error {
+	var retired struct {
+		CreditID      string  `json:"creditId"`
+		CertificateID string  `json:"certificateId"`
+		Amount        float64 `json:"amount"`
+		Beneficiary   string  `json:"beneficiary"`
+	}
+	if err := json.Unmarshal(payload, &retired); err != nil {
+		return err
+	}
+	return insertCreditMovement(c, "retired", retired.CreditID, "", "", retired.Amount, "", "",
+		retired.CertificateID, retired.Beneficiary)
+}
+
+func insertCreditMovement(c *change, movement, creditID, fromCreditID, sponsorID string, amount float64,
+	ownerType, owner, certificateID, beneficiary string) error {
+	return c.exec(`INSERT INTO credit_movements
+		(transaction_id, event_index, block_number, movement, credit_id, from_credit_id, sponsor_id,
+		amount, owner_type, owner, certificate_id, beneficiary, moved_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT (transaction_id, event_index) DO NOTHING`,
+		c.txID, c.index, c.block, movement, creditID, nullString(fromCreditID), nullString(sponsorID),
+		amount, nullString(ownerType), nullString(owner), nullString(certificateID), nullString(beneficiary),
+		c.timestamp)
+}
+
+// nullString stores empty strings as NULL
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
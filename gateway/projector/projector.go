@@ -0,0 +1,147 @@
+// Package projector maintains a relational read model of proof records, tickets, their
+// history and weight comparison results from the chaincode events of the contract.
+//
+// Every chaincode event is applied in its own database transaction together with the
+// checkpoint, so a projector resumes after the last applied event when restarted.
+package projector
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Checkpoint identifies the last chaincode event applied to the read model
+type Checkpoint struct {
+	BlockNumber   uint64
+	TransactionID string
+}
+
+// Projector applies chaincode events to the read model
+type Projector struct {
+	db      *sql.DB
+	dialect Dialect
+	name    string
+
+	checkpoint     *Checkpoint
+	pastCheckpoint bool
+}
+
+// New creates the read model schema when missing and loads the checkpoint stored under the given name
+func New(ctx context.Context, db *sql.DB, dialect Dialect, name string) (*Projector, error) {
+	for _, statement := range schema {
+		if _, err := db.ExecContext(ctx, statement); err != nil {
+			return nil, fmt.Errorf("failed to create read model schema: %v", err)
+		}
+	}
+
+	p := &Projector{db: db, dialect: dialect, name: name}
+	var checkpoint Checkpoint
+	err := db.QueryRowContext(ctx,
+		dialect.rebind("SELECT block_number, transaction_id FROM projector_checkpoints WHERE name = ?"), name,
+	).Scan(&checkpoint.BlockNumber, &checkpoint.TransactionID)
+	if err == sql.ErrNoRows {
+		return p, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint %s: %v", name, err)
+	}
+	p.checkpoint = &checkpoint
+	return p, nil
+}
+
+// Checkpoint returns the last applied event, or nil when no event was applied yet
+func (p *Projector) Checkpoint() *Checkpoint {
+	return p.checkpoint
+}
+
+// StartBlock returns the block event delivery must start from to resume the projection
+func (p *Projector) StartBlock() uint64 {
+	if p.checkpoint == nil {
+		return 0
+	}
+	return p.checkpoint.BlockNumber
+}
+
+// Apply applies a chaincode event to the read model. Events at or before the
+// checkpoint are skipped; it reports whether the event was applied.
+func (p *Projector) Apply(ctx context.Context, event Event) (bool, error) {
+	if p.applied(event) {
+		return false, nil
+	}
+
+	var env envelope
+	if err := json.Unmarshal(event.Payload, &env); err != nil {
+		return false, fmt.Errorf("invalid payload of event %s in transaction %s: %v", event.EventName, event.TransactionID, err)
+	}
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin read model transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	for i, e := range env.Events {
+		c := &change{
+			ctx:       ctx,
+			tx:        tx,
+			dialect:   p.dialect,
+			block:     event.BlockNumber,
+			txID:      event.TransactionID,
+			index:     i,
+			timestamp: env.Timestamp,
+		}
+		if err := c.exec(`INSERT INTO chaincode_events
+			(transaction_id, event_index, block_number, event_type, event_timestamp, payload)
+			VALUES (?, ?, ?, ?, ?, ?) ON CONFLICT (transaction_id, event_index) DO NOTHING`,
+			c.txID, c.index, c.block, e.Type, c.timestamp, string(e.Payload)); err != nil {
+			return false, err
+		}
+
+		handler, known := handlers[e.Type]
+		if !known {
+			continue
+		}
+		if err := handler(c, e.Payload); err != nil {
+			return false, fmt.Errorf("failed to apply %s of transaction %s: %v", e.Type, event.TransactionID, err)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, p.dialect.rebind(`INSERT INTO projector_checkpoints
+		(name, block_number, transaction_id, updated_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET block_number = excluded.block_number,
+		transaction_id = excluded.transaction_id, updated_at = excluded.updated_at`),
+		p.name, event.BlockNumber, event.TransactionID, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return false, fmt.Errorf("failed to save checkpoint: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit read model transaction: %v", err)
+	}
+
+	p.checkpoint = &Checkpoint{BlockNumber: event.BlockNumber, TransactionID: event.TransactionID}
+	p.pastCheckpoint = true
+	return true, nil
+}
+
+// applied reports whether an event is at or before the checkpoint. Events of the
+// checkpoint block are delivered again on resume and skipped up to the checkpoint
+// transaction.
+func (p *Projector) applied(event Event) bool {
+	if p.checkpoint == nil || p.pastCheckpoint && event.BlockNumber >= p.checkpoint.BlockNumber {
+		return false
+	}
+	if event.BlockNumber < p.checkpoint.BlockNumber {
+		return true
+	}
+	if event.BlockNumber > p.checkpoint.BlockNumber {
+		p.pastCheckpoint = true
+		return false
+	}
+	if event.TransactionID == p.checkpoint.TransactionID {
+		p.pastCheckpoint = true
+	}
+	return true
+}
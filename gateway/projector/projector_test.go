@@ -0,0 +1,234 @@
+package projector
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open(SQLite.Driver, filepath.Join(t.TempDir(), "read-model.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func newTestProjector(t *testing.T, db *sql.DB, name string) *Projector {
+	t.Helper()
+	p, err := New(context.Background(), db, SQLite, name)
+	if err != nil {
+		t.Fatalf("failed to create projector: %v", err)
+	}
+	return p
+}
+
+func readFixtures(t *testing.T) []Event {
+	t.Helper()
+	file, err := os.Open(filepath.Join("testdata", "events.jsonl"))
+	if err != nil {
+		t.Fatalf("failed to open fixtures: %v", err)
+	}
+	defer file.Close()
+
+	events, err := ReadFixtures(file)
+	if err != nil {
+		t.Fatalf("failed to read fixtures: %v", err)
+	}
+	return events
+}
+
+// applyAll applies events and returns how many were applied
+func applyAll(t *testing.T, p *Projector, events []Event) int {
+	t.Helper()
+	applied := 0
+	for _, event := range events {
+		ok, err := p.Apply(context.Background(), event)
+		if err != nil {
+			t.Fatalf("failed to apply event of block %d: %v", event.BlockNumber, err)
+		}
+		if ok {
+			applied++
+		}
+	}
+	return applied
+}
+
+// newEvent builds a chaincode event carrying a single entry
+func newEvent(block uint64, txID string, eventType string, payload string) Event {
+	envelope := fmt.Sprintf(`{"schemaVersion":"1.0","eventName":%q,"txId":%q,"timestamp":"2026-10-18T12:00:00Z","events":[{"type":%q,"payload":%s}]}`,
+		eventType, txID, eventType, payload)
+	return Event{BlockNumber: block, TransactionID: txID, EventName: eventType, Payload: json.RawMessage(envelope)}
+}
+
+func count(t *testing.T, db *sql.DB, query string, args ...interface{}) int {
+	t.Helper()
+	var n int
+	if err := db.QueryRow(query, args...).Scan(&n); err != nil {
+		t.Fatalf("%s failed: %v", query, err)
+	}
+	return n
+}
+
+func TestReplayFixtures(t *testing.T) {
+	db := openTestDB(t)
+	p := newTestProjector(t, db, "test")
+	events := readFixtures(t)
+
+	if applied := applyAll(t, p, events); applied != len(events) {
+		t.Fatalf("applied %d events, want %d", applied, len(events))
+	}
+	if checkpoint := p.Checkpoint(); checkpoint == nil || checkpoint.BlockNumber != 12 {
+		t.Errorf("checkpoint = %+v, want block 12", checkpoint)
+	}
+
+	// Block 8 carries the approval and the voided records of the proposal
+	if n := count(t, db, `SELECT COUNT(*) FROM chaincode_events`); n != 13 {
+		t.Errorf("chaincode_events has %d rows, want 13", n)
+	}
+	if n := count(t, db, `SELECT COUNT(*) FROM proof_records WHERE status = 'voided' AND status_reason = 'weight violation'`); n != 2 {
+		t.Errorf("%d voided proof records, want 2", n)
+	}
+	if n := count(t, db, `SELECT COUNT(*) FROM proof_records WHERE status = 'active' AND press_increment = 2 AND store_increment IS NULL`); n != 1 {
+		t.Errorf("%d active proof records of press increment 2, want 1", n)
+	}
+	if n := count(t, db, `SELECT COUNT(*) FROM tickets WHERE ticket_key = 'TICKET_T-2' AND owner_msp = 'Org2MSP' AND updated_block = 12`); n != 1 {
+		t.Errorf("ticket T-2 was not transferred to Org2MSP")
+	}
+	if n := count(t, db, `SELECT COUNT(*) FROM weight_violations WHERE increment_id = 1 AND chained_weight = 70 AND received_weight = 60`); n != 1 {
+		t.Errorf("%d weight violations of increment 1, want 1", n)
+	}
+	if n := count(t, db, `SELECT COUNT(*) FROM weight_violation_records`); n != 2 {
+		t.Errorf("%d violating records, want 2", n)
+	}
+	if n := count(t, db, `SELECT COUNT(*) FROM proposals WHERE status = 'executed' AND approvals = 2 AND updated_block = 8`); n != 1 {
+		t.Errorf("proposal was not executed")
+	}
+	for movement, amount := range map[string]float64{"issued": 25, "transferred": 10, "retired": 5} {
+		if n := count(t, db, `SELECT COUNT(*) FROM credit_movements WHERE movement = ? AND amount = ?`, movement, amount); n != 1 {
+			t.Errorf("%d %s credit movements of %v, want 1", n, movement, amount)
+		}
+	}
+	if n := count(t, db, `SELECT COUNT(*) FROM credit_movements WHERE movement = 'retired' AND beneficiary = 'Acme Packaging'`); n != 1 {
+		t.Errorf("retirement beneficiary was not recorded")
+	}
+	if n := count(t, db, `SELECT COUNT(*) FROM document_history WHERE document_key = 'PROOF_SP-001_1792341697830_19hzncl'`); n != 3 {
+		t.Errorf("record history has %d entries, want creation, violation and voiding", n)
+	}
+}
+
+func TestReplayIsIdempotent(t *testing.T) {
+	db := openTestDB(t)
+	events := readFixtures(t)
+	applyAll(t, newTestProjector(t, db, "test"), events)
+
+	tables := []string{"chaincode_events", "proof_records", "tickets", "document_history", "weight_violations",
+		"weight_violation_records", "proposals", "credit_movements"}
+	before := map[string]int{}
+	for _, table := range tables {
+		before[table] = count(t, db, `SELECT COUNT(*) FROM `+table)
+	}
+
+	// A restarted projector skips every event up to its checkpoint
+	if applied := applyAll(t, newTestProjector(t, db, "test"), events); applied != 0 {
+		t.Errorf("restarted projector applied %d events, want 0", applied)
+	}
+	// A projector without checkpoint applies the events again without duplicating rows
+	if applied := applyAll(t, newTestProjector(t, db, "other"), events); applied != len(events) {
+		t.Errorf("new projector applied %d events, want %d", applied, len(events))
+	}
+	for _, table := range tables {
+		if n := count(t, db, `SELECT COUNT(*) FROM `+table); n != before[table] {
+			t.Errorf("%s has %d rows after the replay, want %d", table, n, before[table])
+		}
+	}
+}
+
+func TestResumeWithinCheckpointBlock(t *testing.T) {
+	ticket := func(key string) string {
+		return fmt.Sprintf(`{"ticketKey":%q,"id":%q,"incrementId":1,"receivedWeight":10,"ownerMSP":"Org1MSP"}`, key, key)
+	}
+	events := []Event{
+		newEvent(1, "tx-a", "TicketCreated", ticket("T-A")),
+		newEvent(1, "tx-b", "TicketCreated", ticket("T-B")),
+		newEvent(1, "tx-c", "TicketCreated", ticket("T-C")),
+		newEvent(2, "tx-d", "TicketCreated", ticket("T-D")),
+	}
+
+	db := openTestDB(t)
+	applyAll(t, newTestProjector(t, db, "test"), events[:2])
+
+	// Delivery resumes from the start of the checkpoint block
+	p := newTestProjector(t, db, "test")
+	if p.StartBlock() != 1 {
+		t.Fatalf("start block = %d, want 1", p.StartBlock())
+	}
+	want := []bool{true, true, false, false}
+	for i, event := range events {
+		if got := p.applied(event); got != want[i] {
+			t.Errorf("applied(%s) = %v, want %v", event.TransactionID, got, want[i])
+		}
+	}
+
+	p = newTestProjector(t, db, "test")
+	if applied := applyAll(t, p, events); applied != 2 {
+		t.Errorf("resumed projector applied %d events, want 2", applied)
+	}
+	if n := count(t, db, `SELECT COUNT(*) FROM tickets`); n != 4 {
+		t.Errorf("%d tickets, want 4", n)
+	}
+	if checkpoint := p.Checkpoint(); checkpoint.TransactionID != "tx-d" {
+		t.Errorf("checkpoint = %+v, want tx-d", checkpoint)
+	}
+}
+
+func TestDeletionAndErasure(t *testing.T) {
+	events := []Event{
+		newEvent(1, "tx-1", "ProofRecordCreated", `{"recordId":"R1","sponsor_id":"S1","chained_weight":10,"press_increment":1,"store_increment":1}`),
+		newEvent(2, "tx-2", "ProofRecordCreated", `{"recordId":"R2","sponsor_id":"S1","chained_weight":10,"press_increment":1,"store_increment":1}`),
+		newEvent(3, "tx-3", "TicketCreated", `{"ticketKey":"TICKET_T-1","id":"T-1","incrementId":1,"receivedWeight":5}`),
+		newEvent(4, "tx-4", "RecordsDeleted", `{"reason":"weight violation","recordIds":["R1"]}`),
+		newEvent(5, "tx-5", "PersonalDataErased", `{"key":"R2","docType":"proofRecord","pseudonym":"p"}`),
+		newEvent(6, "tx-6", "PersonalDataErased", `{"key":"TICKET_T-1","docType":"ticket","pseudonym":"p"}`),
+		newEvent(7, "tx-7", "UnknownEvent", `{}`),
+	}
+
+	db := openTestDB(t)
+	applyAll(t, newTestProjector(t, db, "test"), events)
+
+	if n := count(t, db, `SELECT COUNT(*) FROM proof_records WHERE record_id = 'R1' AND status = 'deleted' AND updated_block = 4`); n != 1 {
+		t.Errorf("R1 was not marked deleted")
+	}
+	if n := count(t, db, `SELECT COUNT(*) FROM proof_records WHERE record_id = 'R2' AND personal_data_erased AND status = 'active'`); n != 1 {
+		t.Errorf("R2 was not marked erased")
+	}
+	if n := count(t, db, `SELECT COUNT(*) FROM tickets WHERE ticket_key = 'TICKET_T-1' AND personal_data_erased`); n != 1 {
+		t.Errorf("ticket T-1 was not marked erased")
+	}
+	// Unknown event types are kept in the event log only
+	if n := count(t, db, `SELECT COUNT(*) FROM chaincode_events WHERE event_type = 'UnknownEvent'`); n != 1 {
+		t.Errorf("unknown event was not logged")
+	}
+}
+
+func TestCompositeKeysAreStoredAsText(t *testing.T) {
+	db := openTestDB(t)
+	p := newTestProjector(t, db, "test")
+	applyAll(t, p, []Event{newEvent(1, "tx-1", "ProofRecordCreated", `{"recordId":"\u0000PROOF\u0000S1\u00001\u0000h\u0000","sponsor_id":"S1","chained_weight":10}`)})
+
+	var recordID string
+	if err := db.QueryRow(`SELECT record_id FROM proof_records`).Scan(&recordID); err != nil {
+		t.Fatalf("failed to read record: %v", err)
+	}
+	if recordID != `\u0000PROOF\u0000S1\u00001\u0000h\u0000` {
+		t.Errorf("record_id = %q", recordID)
+	}
+}
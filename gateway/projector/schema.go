@@ -0,0 +1,108 @@
+package projector
+
+// schema creates the read model. The column types are understood by both SQLite and
+// PostgreSQL, and every statement can be run again on an existing database.
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS projector_checkpoints (
+	name TEXT PRIMARY KEY,
+	block_number BIGINT NOT NULL,
+	transaction_id TEXT NOT NULL,
+	updated_at TEXT NOT NULL
+)`,
+	`CREATE TABLE IF NOT EXISTS chaincode_events (
+	transaction_id TEXT NOT NULL,
+	event_index INTEGER NOT NULL,
+	block_number BIGINT NOT NULL,
+	event_type TEXT NOT NULL,
+	event_timestamp TEXT NOT NULL,
+	payload TEXT NOT NULL,
+	PRIMARY KEY (transaction_id, event_index)
+)`,
+	`CREATE TABLE IF NOT EXISTS proof_records (
+	record_id TEXT PRIMARY KEY,
+	sponsor_id TEXT,
+	bulk_short_id TEXT,
+	chained_weight DOUBLE PRECISION,
+	press_increment DOUBLE PRECISION,
+	store_increment DOUBLE PRECISION,
+	owner_msp TEXT,
+	status TEXT NOT NULL,
+	status_reason TEXT,
+	personal_data_erased BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at TEXT,
+	created_tx_id TEXT,
+	updated_block BIGINT NOT NULL
+)`,
+	`CREATE INDEX IF NOT EXISTS proof_records_sponsor ON proof_records (sponsor_id, press_increment, store_increment)`,
+	`CREATE TABLE IF NOT EXISTS tickets (
+	ticket_key TEXT PRIMARY KEY,
+	ticket_id TEXT,
+	sponsor_id TEXT,
+	increment_id DOUBLE PRECISION,
+	received_weight DOUBLE PRECISION,
+	owner_msp TEXT,
+	personal_data_erased BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at TEXT,
+	created_tx_id TEXT,
+	updated_block BIGINT NOT NULL
+)`,
+	`CREATE INDEX IF NOT EXISTS tickets_sponsor ON tickets (sponsor_id, increment_id)`,
+	`CREATE TABLE IF NOT EXISTS document_history (
+	document_key TEXT NOT NULL,
+	transaction_id TEXT NOT NULL,
+	event_index INTEGER NOT NULL,
+	block_number BIGINT NOT NULL,
+	event_type TEXT NOT NULL,
+	event_timestamp TEXT NOT NULL,
+	PRIMARY KEY (document_key, transaction_id, event_index)
+)`,
+	`CREATE TABLE IF NOT EXISTS weight_violations (
+	transaction_id TEXT NOT NULL,
+	event_index INTEGER NOT NULL,
+	block_number BIGINT NOT NULL,
+	sponsor_id TEXT,
+	increment_field TEXT NOT NULL,
+	increment_id INTEGER NOT NULL,
+	chained_weight DOUBLE PRECISION NOT NULL,
+	received_weight DOUBLE PRECISION NOT NULL,
+	aggregation TEXT,
+	detected_at TEXT NOT NULL,
+	PRIMARY KEY (transaction_id, event_index)
+)`,
+	`CREATE TABLE IF NOT EXISTS weight_violation_records (
+	transaction_id TEXT NOT NULL,
+	event_index INTEGER NOT NULL,
+	record_id TEXT NOT NULL,
+	PRIMARY KEY (transaction_id, event_index, record_id)
+)`,
+	`CREATE TABLE IF NOT EXISTS proposals (
+	proposal_id TEXT PRIMARY KEY,
+	action TEXT NOT NULL,
+	status TEXT NOT NULL,
+	approvals INTEGER NOT NULL,
+	quorum INTEGER NOT NULL,
+	updated_at TEXT NOT NULL,
+	updated_block BIGINT NOT NULL
+)`,
+	`CREATE TABLE IF NOT EXISTS credit_movements (
+	transaction_id TEXT NOT NULL,
+	event_index INTEGER NOT NULL,
+	block_number BIGINT NOT NULL,
+	movement TEXT NOT NULL,
+	credit_id TEXT NOT NULL,
+	from_credit_id TEXT,
+	sponsor_id TEXT,
+	amount DOUBLE PRECISION NOT NULL,
+	owner_type TEXT,
+	owner TEXT,
+	certificate_id TEXT,
+	beneficiary TEXT,
+	moved_at TEXT NOT NULL,
+	PRIMARY KEY (transaction_id, event_index)
+)`,
+}
+
+// Schema returns the statements creating the read model
+func Schema() []string {
+	return append([]string{}, schema...)
+}
@@ -0,0 +1,156 @@
+package server
+
+import "strings"
+
+// OpenAPI returns the OpenAPI 3 description of the routes of the gateway
+func OpenAPI() map[string]interface{} {
+	paths := map[string]interface{}{}
+	for _, route := range Routes() {
+		operations, exists := paths[route.Path].(map[string]interface{})
+		if !exists {
+			operations = map[string]interface{}{}
+			paths[route.Path] = operations
+		}
+		operations[strings.ToLower(route.Method)] = operation(route)
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "Proof Records Gateway",
+			"version":     "1.0.0",
+			"description": "REST endpoints of the ProofRecordsContract transactions. Contract results are returned unchanged.",
+		},
+		"paths":    paths,
+		"security": []interface{}{map[string]interface{}{"bearerAuth": []string{}}},
+		"components": map[string]interface{}{
+			"securitySchemes": map[string]interface{}{
+				"bearerAuth": map[string]interface{}{
+					"type":        "http",
+					"scheme":      "bearer",
+					"description": "Token of a client of the gateway, transactions run with the identity of the client",
+				},
+			},
+			"schemas": map[string]interface{}{
+				"Error": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"error":       map[string]interface{}{"type": "string"},
+						"transaction": map[string]interface{}{"type": "string"},
+					},
+					"required": []string{"error"},
+				},
+				"Result": map[string]interface{}{
+					"type":        "object",
+					"description": "JSON result of the contract transaction",
+				},
+			},
+		},
+	}
+}
+
+func operation(route Route) map[string]interface{} {
+	parameters := []interface{}{}
+	var requestBody map[string]interface{}
+	for _, param := range route.Params {
+		if param.In == InBody {
+			description := param.Description
+			if param.Default != "" {
+				description += " (defaults to " + param.Default + ")"
+			}
+			requestBody = map[string]interface{}{
+				"description": description,
+				"required":    param.Required,
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{
+						"schema": map[string]interface{}{"type": "object"},
+					},
+				},
+			}
+			continue
+		}
+
+		name := param.Key
+		if name == "" {
+			name = param.Name
+		}
+		schema := map[string]interface{}{"type": "string"}
+		if param.Default != "" {
+			schema["default"] = param.Default
+		}
+		parameters = append(parameters, map[string]interface{}{
+			"name":        name,
+			"in":          param.In,
+			"required":    param.Required,
+			"description": param.Description,
+			"schema":      schema,
+		})
+	}
+
+	successStatus := "200"
+	if route.Created {
+		successStatus = "201"
+	}
+	responses := map[string]interface{}{
+		successStatus: resultResponse("Transaction result"),
+		"204":         map[string]interface{}{"description": "Transaction without result"},
+		"400":         errorResponse("Invalid request or transaction rejected by the contract"),
+		"401":         errorResponse("Missing or unknown bearer token"),
+		"403":         errorResponse("Caller lacks the role or ownership required by the transaction"),
+		"404":         errorResponse("Document does not exist"),
+		"409":         errorResponse("Duplicate document or action already taken"),
+		"502":         errorResponse("Ledger backend unavailable"),
+	}
+	if route.Reports {
+		responses["422"] = resultResponse("Transaction result reporting success false")
+	}
+
+	op := map[string]interface{}{
+		"operationId":             route.Transaction,
+		"summary":                 route.Summary,
+		"tags":                    []string{route.Tag},
+		"responses":               responses,
+		"x-fabric-transaction":    route.Transaction,
+		"x-fabric-submit":         route.Submit,
+		"x-fabric-transient-keys": transientKeys(route),
+	}
+	if len(parameters) > 0 {
+		op["parameters"] = parameters
+	}
+	if requestBody != nil {
+		op["requestBody"] = requestBody
+	}
+	return op
+}
+
+func transientKeys(route Route) []string {
+	keys := []string{}
+	for _, param := range route.Params {
+		if param.Transient {
+			keys = append(keys, param.Name)
+		}
+	}
+	return keys
+}
+
+func resultResponse(description string) map[string]interface{} {
+	return map[string]interface{}{
+		"description": description,
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{
+				"schema": map[string]interface{}{"$ref": "#/components/schemas/Result"},
+			},
+		},
+	}
+}
+
+func errorResponse(description string) map[string]interface{} {
+	return map[string]interface{}{
+		"description": description,
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{
+				"schema": map[string]interface{}{"$ref": "#/components/schemas/Error"},
+			},
+		},
+	}
+}
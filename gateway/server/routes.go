@@ -0,0 +1,295 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Parameter sources
+const (
+	InPath   = "path"
+	InQuery  = "query"
+	InHeader = "header"
+	InBody   = "body"
+)
+
+// Param is a contract argument or transient map entry read from an HTTP request. Key
+// names the parameter in the request when it differs from Name.
+type Param struct {
+	Name        string
+	Key         string
+	In          string
+	Transient   bool
+	Required    bool
+	Default     string
+	Description string
+}
+
+// Route exposes a contract transaction as a REST endpoint
+type Route struct {
+	Method      string
+	Path        string
+	Transaction string
+	Tag         string
+	Summary     string
+	Submit      bool
+	Created     bool
+	// Reports is set when the contract answers failures with a success false result
+	Reports bool
+	Params  []Param
+}
+
+// saltHeader carries the secret salt of a proof record commitment, which also salts its private details
+const saltHeader = "X-Commitment-Salt"
+
+// privateSaltHeader carries the secret salt of the private details of tickets and collectors
+const privateSaltHeader = "X-Private-Salt"
+
+// pseudonymKeyHeader carries the secret key the pseudonyms of erased personal data are derived from
+const pseudonymKeyHeader = "X-Pseudonym-Key"
+
+// collectorNameHeader carries the name of the collector whose personal data is erased
+const collectorNameHeader = "X-Collector-Name"
+
+func pathParam(name, description string) Param {
+	return Param{Name: name, In: InPath, Required: true, Description: description}
+}
+
+func queryParam(name, description string) Param {
+	return Param{Name: name, In: InQuery, Required: true, Description: description}
+}
+
+func optionalQueryParam(name, defaultValue, description string) Param {
+	return Param{Name: name, In: InQuery, Default: defaultValue, Description: description}
+}
+
+func bodyParam(name, description string) Param {
+	return Param{Name: name, In: InBody, Required: true, Description: description}
+}
+
+func optionalBodyParam(name, defaultValue, description string) Param {
+	return Param{Name: name, In: InBody, Default: defaultValue, Description: description}
+}
+
+func transientBodyParam(name, description string) Param {
+	return Param{Name: name, In: InBody, Transient: true, Required: true, Description: description}
+}
+
+func saltParam() Param {
+	return Param{Name: "salt", Key: saltHeader, In: InHeader, Transient: true, Required: true, Description: "Secret salt of the record commitment, passed in the transient map"}
+}
+
+func optionalSaltParam() Param {
+	return Param{Name: "salt", Key: saltHeader, In: InHeader, Transient: true, Description: "Secret salt of the record commitment, passed in the transient map. Records created without it have no commitment"}
+}
+
+func privateSaltParam() Param {
+	return Param{Name: "salt", Key: privateSaltHeader, In: InHeader, Transient: true, Required: true, Description: "Secret salt of the private details, passed in the transient map"}
+}
+
+func optionalPrivateSaltParam() Param {
+	return Param{Name: "salt", Key: privateSaltHeader, In: InHeader, Transient: true, Description: "Secret salt of the private details, passed in the transient map"}
+}
+
+func pseudonymKeyParam() Param {
+	return Param{Name: "pseudonymKey", Key: pseudonymKeyHeader, In: InHeader, Transient: true, Required: true, Description: "Secret key of the pseudonyms, passed in the transient map"}
+}
+
+func deleteViolationsParam() Param {
+	return optionalQueryParam("deleteViolations", "false", "Delete the records rejected by the comparison")
+}
+
+// entityRoutes returns the registry endpoints of an entity type, writes also take the given extra params
+func entityRoutes(path, kind, plural, idName, idDescription, description string, extra ...Param) []Route {
+	id := pathParam(idName, idDescription)
+	data := bodyParam(strings.ToLower(kind[:1])+kind[1:]+"Data", description)
+	return []Route{
+		{Method: http.MethodPost, Path: "/" + path, Transaction: "Create" + kind, Tag: "registry",
+			Summary: "Register a " + strings.ToLower(kind), Submit: true, Created: true,
+			Params: append([]Param{data}, extra...)},
+		{Method: http.MethodGet, Path: "/" + path, Transaction: "QueryAll" + plural, Tag: "registry",
+			Summary: "Query all " + strings.ToLower(plural)},
+		{Method: http.MethodGet, Path: "/" + path + "/{" + idName + "}", Transaction: "Query" + kind, Tag: "registry",
+			Summary: "Query a " + strings.ToLower(kind),
+			Params:  []Param{id}},
+		{Method: http.MethodPut, Path: "/" + path + "/{" + idName + "}", Transaction: "Update" + kind, Tag: "registry",
+			Summary: "Update a " + strings.ToLower(kind), Submit: true,
+			Params: append([]Param{id, data}, extra...)},
+		{Method: http.MethodDelete, Path: "/" + path + "/{" + idName + "}", Transaction: "Delete" + kind, Tag: "registry",
+			Summary: "Delete a " + strings.ToLower(kind) + " no document refers to", Submit: true,
+			Params: []Param{id}},
+	}
+}
+
+func configRoutes(path, kind string) []Route {
+	return []Route{
+		{Method: http.MethodGet, Path: "/config/" + path, Transaction: "Query" + kind + "Config", Tag: "config",
+			Summary: "Query the " + path + " configuration"},
+		{Method: http.MethodPut, Path: "/config/" + path, Transaction: "Set" + kind + "Config", Tag: "config",
+			Summary: "Set the " + path + " configuration", Submit: true,
+			Params: []Param{bodyParam("configData", "Configuration document")}},
+	}
+}
+
+// Routes returns the REST endpoints of every ProofRecordsContract transaction
+func Routes() []Route {
+	routes := []Route{
+		{Method: http.MethodPost, Path: "/ledger/init", Transaction: "InitLedger", Tag: "ledger",
+			Summary: "Initialize the ledger", Submit: true},
+
+		{Method: http.MethodPost, Path: "/records", Transaction: "CreateProofRecord", Tag: "records",
+			Summary: "Create a proof record", Submit: true, Created: true, Reports: true,
+			Params: []Param{bodyParam("recordData", "Proof record"), optionalSaltParam()}},
+		{Method: http.MethodPost, Path: "/records/private", Transaction: "CreateProofRecordPrivate", Tag: "records",
+			Summary: "Create a proof record passed in the transient map", Submit: true, Created: true, Reports: true,
+			Params: []Param{transientBodyParam("record", "Proof record, passed in the transient map"), saltParam()}},
+		{Method: http.MethodGet, Path: "/records", Transaction: "QueryAllProofRecords", Tag: "records",
+			Summary: "Query all proof records"},
+		{Method: http.MethodGet, Path: "/records/search", Transaction: "QueryRecordsByField", Tag: "records",
+			Summary: "Query proof records by field",
+			Params:  []Param{queryParam("fieldName", "Field to match"), queryParam("fieldValue", "Value to match")}},
+		{Method: http.MethodGet, Path: "/records/{recordId}", Transaction: "QueryProofRecord", Tag: "records",
+			Summary: "Query a proof record",
+			Params:  []Param{pathParam("recordId", "Proof record ID")}},
+		{Method: http.MethodGet, Path: "/records/{recordId}/history", Transaction: "GetRecordHistory", Tag: "records",
+			Summary: "Query the history of a proof record",
+			Params:  []Param{pathParam("recordId", "Proof record ID")}},
+		{Method: http.MethodPost, Path: "/records/{recordId}/commitment/verify", Transaction: "VerifyProofRecordCommitment", Tag: "records",
+			Summary: "Verify a claimed proof record against its commitment",
+			Params: []Param{
+				pathParam("recordId", "Proof record ID"),
+				bodyParam("claimedDocument", "Proof record as originally submitted"),
+				queryParam("salt", "Secret salt of the commitment"),
+			}},
+		{Method: http.MethodGet, Path: "/records/{recordId}/endorsement-policy", Transaction: "QueryRecordEndorsementPolicy", Tag: "endorsement",
+			Summary: "Query the endorsement policy of a proof record",
+			Params:  []Param{pathParam("recordId", "Proof record ID")}},
+		{Method: http.MethodGet, Path: "/records/{recordId}/credit", Transaction: "QueryCreditByRecord", Tag: "credits",
+			Summary: "Query the credit backed by a proof record",
+			Params:  []Param{pathParam("recordId", "Proof record ID")}},
+		{Method: http.MethodGet, Path: "/documents/by-creator", Transaction: "QueryDocumentsByCreator", Tag: "records",
+			Summary: "Query documents by creator",
+			Params:  []Param{queryParam("dimension", "Creator dimension"), queryParam("value", "Value to match")}},
+
+		{Method: http.MethodPost, Path: "/tickets", Transaction: "CreateTicket", Tag: "tickets",
+			Summary: "Create a ticket", Submit: true, Created: true, Reports: true,
+			Params: []Param{bodyParam("ticketData", "Ticket"), optionalPrivateSaltParam()}},
+		{Method: http.MethodPost, Path: "/tickets/private", Transaction: "CreateTicketPrivate", Tag: "tickets",
+			Summary: "Create a ticket passed in the transient map", Submit: true, Created: true, Reports: true,
+			Params: []Param{transientBodyParam("ticket", "Ticket, passed in the transient map"), privateSaltParam()}},
+		{Method: http.MethodGet, Path: "/tickets", Transaction: "QueryAllTickets", Tag: "tickets",
+			Summary: "Query all tickets"},
+		{Method: http.MethodGet, Path: "/tickets/search", Transaction: "QueryTicketsByField", Tag: "tickets",
+			Summary: "Query tickets by field",
+			Params:  []Param{queryParam("fieldName", "Field to match"), queryParam("fieldValue", "Value to match")}},
+		{Method: http.MethodGet, Path: "/tickets/{ticketKey}", Transaction: "QueryTicket", Tag: "tickets",
+			Summary: "Query a ticket",
+			Params:  []Param{pathParam("ticketKey", "Ticket key")}},
+
+		{Method: http.MethodPost, Path: "/comparisons/press", Transaction: "CompareWeightsByPressIncrement", Tag: "comparisons",
+			Summary: "Compare weights by press increment", Submit: true, Reports: true,
+			Params: []Param{deleteViolationsParam()}},
+		{Method: http.MethodPost, Path: "/comparisons/press/options", Transaction: "CompareWeightsByPressIncrementWithOptions", Tag: "comparisons",
+			Summary: "Compare weights by press increment with options", Submit: true, Reports: true,
+			Params: []Param{deleteViolationsParam(), optionalBodyParam("options", "{}", "Comparison options")}},
+		{Method: http.MethodPost, Path: "/comparisons/store", Transaction: "CompareWeightsByStoreIncrement", Tag: "comparisons",
+			Summary: "Compare weights by store increment", Submit: true, Reports: true,
+			Params: []Param{deleteViolationsParam()}},
+		{Method: http.MethodPost, Path: "/comparisons/store/options", Transaction: "CompareWeightsByStoreIncrementWithOptions", Tag: "comparisons",
+			Summary: "Compare weights by store increment with options", Submit: true, Reports: true,
+			Params: []Param{deleteViolationsParam(), optionalBodyParam("options", "{}", "Comparison options")}},
+		{Method: http.MethodPost, Path: "/reconciliations/press", Transaction: "ReconcileByPressIncrement", Tag: "comparisons",
+			Summary: "Reconcile weights by press increment", Reports: true,
+			Params: []Param{optionalBodyParam("options", "{}", "Reconciliation options")}},
+		{Method: http.MethodPost, Path: "/reconciliations/store", Transaction: "ReconcileByStoreIncrement", Tag: "comparisons",
+			Summary: "Reconcile weights by store increment", Reports: true,
+			Params: []Param{optionalBodyParam("options", "{}", "Reconciliation options")}},
+
+		{Method: http.MethodPost, Path: "/roles", Transaction: "GrantRole", Tag: "roles",
+			Summary: "Grant roles to an identity", Submit: true,
+			Params: []Param{bodyParam("grantData", "Role grant")}},
+		{Method: http.MethodDelete, Path: "/roles", Transaction: "RevokeRole", Tag: "roles",
+			Summary: "Revoke a role from an identity", Submit: true,
+			Params: []Param{queryParam("identity", "Identity holding the role"), queryParam("role", "Role to revoke")}},
+		{Method: http.MethodDelete, Path: "/roles/sponsors", Transaction: "RevokeSponsor", Tag: "roles",
+			Summary: "Revoke a sponsor from an identity", Submit: true,
+			Params: []Param{queryParam("identity", "Identity holding the sponsor"), queryParam("sponsorId", "Sponsor to revoke")}},
+		{Method: http.MethodGet, Path: "/roles", Transaction: "ListRoleAssignments", Tag: "roles",
+			Summary: "List role assignments"},
+
+		{Method: http.MethodPut, Path: "/documents/{key}/owner", Transaction: "TransferOwnership", Tag: "ownership",
+			Summary: "Transfer the ownership of a document", Submit: true,
+			Params: []Param{pathParam("key", "Document key"), queryParam("ownerMSP", "New owner organization")}},
+		{Method: http.MethodPut, Path: "/bulks/{bulkShortId}/owner", Transaction: "TransferBulkOwnership", Tag: "ownership",
+			Summary: "Transfer the ownership of the records of a bulk", Submit: true,
+			Params: []Param{pathParam("bulkShortId", "Bulk short ID"), queryParam("ownerMSP", "New owner organization")}},
+		{Method: http.MethodPut, Path: "/documents/{key}/delegates/{delegateMSP}", Transaction: "DelegateOwnership", Tag: "ownership",
+			Summary: "Delegate modification rights on a document", Submit: true,
+			Params: []Param{pathParam("key", "Document key"), pathParam("delegateMSP", "Delegate organization")}},
+		{Method: http.MethodDelete, Path: "/documents/{key}/delegates/{delegateMSP}", Transaction: "RevokeDelegation", Tag: "ownership",
+			Summary: "Revoke a delegation on a document", Submit: true,
+			Params: []Param{pathParam("key", "Document key"), pathParam("delegateMSP", "Delegate organization")}},
+		{Method: http.MethodPost, Path: "/documents/{key}/erasure", Transaction: "ErasePersonalData", Tag: "ownership",
+			Summary: "Erase the personal data of a document", Submit: true,
+			Params: []Param{pathParam("key", "Document key"), queryParam("reason", "Reason of the erasure"), pseudonymKeyParam()}},
+		{Method: http.MethodPost, Path: "/collectors/erasure", Transaction: "EraseCollectorPersonalData", Tag: "ownership",
+			Summary: "Erase the personal data of every document of a collector", Submit: true,
+			Params: []Param{queryParam("reason", "Reason of the erasure"), pseudonymKeyParam(),
+				{Name: "collector_name", Key: collectorNameHeader, In: InHeader, Transient: true, Required: true, Description: "Name of the collector, passed in the transient map"}}},
+
+		{Method: http.MethodPost, Path: "/endorsement-policies", Transaction: "SetSponsorEndorsementPolicy", Tag: "endorsement",
+			Summary: "Set the endorsement policy of a sponsor", Submit: true,
+			Params: []Param{bodyParam("policyData", "Sponsor endorsement policy")}},
+		{Method: http.MethodGet, Path: "/endorsement-policies/{sponsorId}", Transaction: "QuerySponsorEndorsementPolicy", Tag: "endorsement",
+			Summary: "Query the endorsement policy of a sponsor",
+			Params:  []Param{pathParam("sponsorId", "Sponsor ID")}},
+		{Method: http.MethodPost, Path: "/endorsement-policies/{sponsorId}/apply", Transaction: "ApplySponsorEndorsementPolicy", Tag: "endorsement",
+			Summary: "Apply the endorsement policy of a sponsor to its records", Submit: true,
+			Params: []Param{pathParam("sponsorId", "Sponsor ID")}},
+
+		{Method: http.MethodPost, Path: "/proposals", Transaction: "ProposeViolationAction", Tag: "governance",
+			Summary: "Propose an action on violating records", Submit: true, Created: true,
+			Params: []Param{bodyParam("proposalData", "Violation action proposal")}},
+		{Method: http.MethodGet, Path: "/proposals", Transaction: "QueryProposalsByStatus", Tag: "governance",
+			Summary: "Query proposals by status",
+			Params:  []Param{queryParam("status", "Proposal status")}},
+		{Method: http.MethodGet, Path: "/proposals/{proposalId}", Transaction: "QueryProposal", Tag: "governance",
+			Summary: "Query a proposal",
+			Params:  []Param{pathParam("proposalId", "Proposal ID")}},
+		{Method: http.MethodPost, Path: "/proposals/{proposalId}/approvals", Transaction: "ApproveProposal", Tag: "governance",
+			Summary: "Approve a proposal", Submit: true,
+			Params: []Param{pathParam("proposalId", "Proposal ID")}},
+
+		{Method: http.MethodPost, Path: "/credits", Transaction: "IssueCredits", Tag: "credits",
+			Summary: "Issue credits against reconciled proof records", Submit: true, Created: true, Reports: true,
+			Params: []Param{bodyParam("issuanceData", "Credit issuance")}},
+		{Method: http.MethodGet, Path: "/credits/{creditId}", Transaction: "QueryCredit", Tag: "credits",
+			Summary: "Query a credit",
+			Params:  []Param{pathParam("creditId", "Credit ID")}},
+		{Method: http.MethodPost, Path: "/credits/transfers", Transaction: "Transfer", Tag: "credits",
+			Summary: "Transfer credits", Submit: true, Reports: true,
+			Params: []Param{bodyParam("transferData", "Credit transfer")}},
+		{Method: http.MethodPost, Path: "/credits/retirements", Transaction: "Retire", Tag: "credits",
+			Summary: "Retire credits", Submit: true, Created: true, Reports: true,
+			Params: []Param{bodyParam("retirementData", "Credit retirement")}},
+		{Method: http.MethodGet, Path: "/balances/{ownerType}/{owner}", Transaction: "BalanceOf", Tag: "credits",
+			Summary: "Query the credit balance of an owner",
+			Params:  []Param{pathParam("ownerType", "Owner type"), pathParam("owner", "Owner")}},
+		{Method: http.MethodGet, Path: "/retirement-certificates/{certificateId}", Transaction: "QueryRetirementCertificate", Tag: "credits",
+			Summary: "Query a retirement certificate",
+			Params:  []Param{pathParam("certificateId", "Retirement certificate ID")}},
+	}
+
+	routes = append(routes, entityRoutes("sponsors", "Sponsor", "Sponsors", "sponsorId", "Sponsor ID", "Sponsor")...)
+	routes = append(routes, entityRoutes("collectors", "Collector", "Collectors", "collectorId", "Collector ID", "Collector", optionalPrivateSaltParam())...)
+	routes = append(routes, entityRoutes("bulks", "Bulk", "Bulks", "bulkShortId", "Bulk short ID", "Bulk")...)
+
+	routes = append(routes, configRoutes("comparison", "Comparison")...)
+	routes = append(routes, configRoutes("access-control", "AccessControl")...)
+	routes = append(routes, configRoutes("identity", "Identity")...)
+	routes = append(routes, configRoutes("governance", "Governance")...)
+	routes = append(routes, configRoutes("privacy", "Privacy")...)
+	routes = append(routes, configRoutes("redaction", "Redaction")...)
+	routes = append(routes, configRoutes("registry", "Registry")...)
+	return routes
+}
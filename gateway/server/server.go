@@ -0,0 +1,228 @@
+// Package server exposes the transactions of the proof records contract as a REST API.
+package server
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/yourusername/proof-records-chaincode/gateway/backend"
+)
+
+// maxBodySize bounds the request bodies passed to the contract
+const maxBodySize = 10 << 20
+
+// Server serves the transactions of the contract over HTTP. Every request to a route must
+// carry the bearer token of a client, its transactions run with the identity of that client.
+type Server struct {
+	clients []Client
+	mux     *http.ServeMux
+}
+
+// Client is a caller of the gateway and the backend invoking its transactions with its identity
+type Client struct {
+	Name string
+	// TokenSHA256 is the hex encoded SHA-256 hash of the bearer token of the client
+	TokenSHA256 string
+	Backend     backend.Backend
+}
+
+// ErrorResponse is the body of a request the gateway or the contract rejected
+type ErrorResponse struct {
+	Error       string `json:"error"`
+	Transaction string `json:"transaction,omitempty"`
+}
+
+// failureResponse holds the fields shared by the contract's success/message responses,
+// such as CreateProofRecordResponse and ComparisonResponse
+type failureResponse struct {
+	Success *bool  `json:"success"`
+	Message string `json:"message"`
+}
+
+// New creates a server invoking the contract through the backends of the given clients
+func New(clients []Client) *Server {
+	s := &Server{clients: clients, mux: http.NewServeMux()}
+
+	for _, route := range Routes() {
+		s.mux.HandleFunc(route.Method+" "+route.Path, s.handler(route))
+	}
+	s.mux.HandleFunc("GET /openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, OpenAPI())
+	})
+	s.mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handler(route Route) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		client := s.authenticate(r)
+		if client == nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="proof-records"`)
+			writeJSON(w, http.StatusUnauthorized, ErrorResponse{Error: "a valid bearer token is required", Transaction: route.Transaction})
+			return
+		}
+
+		args, transient, err := readParams(route, r)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: err.Error(), Transaction: route.Transaction})
+			return
+		}
+
+		var result []byte
+		if route.Submit {
+			result, err = client.Backend.Submit(route.Transaction, args, transient)
+		} else {
+			result, err = client.Backend.Evaluate(route.Transaction, args, transient)
+		}
+		if err != nil {
+			var chaincodeErr *backend.ChaincodeError
+			if errors.As(err, &chaincodeErr) {
+				writeJSON(w, statusForMessage(chaincodeErr.Message, http.StatusBadRequest),
+					ErrorResponse{Error: chaincodeErr.Message, Transaction: route.Transaction})
+				return
+			}
+			log.Printf("%s %s (%s): %v", route.Method, route.Path, client.Name, err)
+			writeJSON(w, http.StatusBadGateway, ErrorResponse{Error: err.Error(), Transaction: route.Transaction})
+			return
+		}
+
+		if len(result) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeRaw(w, resultStatus(route, result), result)
+	}
+}
+
+// authenticate returns the client whose token the request carries, or nil
+func (s *Server) authenticate(r *http.Request) *Client {
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found || token == "" {
+		return nil
+	}
+
+	hash := sha256.Sum256([]byte(token))
+	tokenHash := hex.EncodeToString(hash[:])
+	for i := range s.clients {
+		if subtle.ConstantTimeCompare([]byte(tokenHash), []byte(strings.ToLower(s.clients[i].TokenSHA256))) == 1 {
+			return &s.clients[i]
+		}
+	}
+	return nil
+}
+
+// readParams reads the contract arguments and transient map entries of a route from a request
+func readParams(route Route, r *http.Request) ([]string, map[string][]byte, error) {
+	body := ""
+	for _, param := range route.Params {
+		if param.In == InBody {
+			data, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to read request body: %v", err)
+			}
+			body = strings.TrimSpace(string(data))
+			break
+		}
+	}
+
+	args := []string{}
+	transient := map[string][]byte{}
+	for _, param := range route.Params {
+		key := param.Key
+		if key == "" {
+			key = param.Name
+		}
+
+		value, found := "", false
+		switch param.In {
+		case InPath:
+			value = r.PathValue(key)
+			found = value != ""
+		case InQuery:
+			found = r.URL.Query().Has(key)
+			value = r.URL.Query().Get(key)
+		case InHeader:
+			value = r.Header.Get(key)
+			found = value != ""
+		case InBody:
+			value = body
+			found = body != ""
+			if found && !json.Valid([]byte(body)) {
+				return nil, nil, fmt.Errorf("request body must be a JSON document")
+			}
+		}
+
+		if !found {
+			if param.Required {
+				return nil, nil, fmt.Errorf("%s %s is required", param.In, key)
+			}
+			if param.Transient {
+				continue
+			}
+			value = param.Default
+		}
+
+		if param.Transient {
+			transient[param.Name] = []byte(value)
+		} else {
+			args = append(args, value)
+		}
+	}
+	return args, transient, nil
+}
+
+// resultStatus returns the HTTP status of a transaction result. Responses reporting
+// success false are mapped to a client error from their message.
+func resultStatus(route Route, result []byte) int {
+	var response failureResponse
+	if json.Unmarshal(result, &response) == nil && response.Success != nil && !*response.Success {
+		return statusForMessage(response.Message, http.StatusUnprocessableEntity)
+	}
+	if route.Created {
+		return http.StatusCreated
+	}
+	return http.StatusOK
+}
+
+// statusForMessage maps a contract error or failure message to an HTTP status
+func statusForMessage(message string, fallback int) int {
+	switch {
+	case strings.Contains(message, "authorization denied"), strings.Contains(message, "ownership denied"):
+		return http.StatusForbidden
+	case strings.Contains(message, "does not exist"):
+		return http.StatusNotFound
+	case strings.Contains(message, "Duplicate"), strings.Contains(message, "already"),
+		strings.Contains(message, "still referenced"):
+		return http.StatusConflict
+	}
+	return fallback
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeRaw(w, status, data)
+}
+
+func writeRaw(w http.ResponseWriter, status int, data []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(data)
+}
@@ -0,0 +1,147 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/proof-records-chaincode/gateway/backend"
+)
+
+// fakeBackend answers every transaction with a fixed result or error and records the last call
+type fakeBackend struct {
+	result    []byte
+	err       error
+	submitted bool
+	args      []string
+	transient map[string][]byte
+}
+
+func (f *fakeBackend) Submit(transaction string, args []string, transient map[string][]byte) ([]byte, error) {
+	f.submitted, f.args, f.transient = true, args, transient
+	return f.result, f.err
+}
+
+func (f *fakeBackend) Evaluate(transaction string, args []string, transient map[string][]byte) ([]byte, error) {
+	f.submitted, f.args, f.transient = false, args, transient
+	return f.result, f.err
+}
+
+func (f *fakeBackend) Close() error {
+	return nil
+}
+
+func tokenHash(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// serve sends a request with the given bearer token and a commitment salt to a server of one client
+func serve(b backend.Backend, token string, method string, target string, body string) *httptest.ResponseRecorder {
+	s := New([]Client{{Name: "alice", TokenSHA256: tokenHash("alice-token"), Backend: b}})
+	request := httptest.NewRequest(method, target, strings.NewReader(body))
+	request.Header.Set(saltHeader, "secret")
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	recorder := httptest.NewRecorder()
+	s.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestRoutesRequireAClientToken(t *testing.T) {
+	b := &fakeBackend{result: []byte(`[]`)}
+
+	for _, token := range []string{"", "unknown-token"} {
+		response := serve(b, token, http.MethodGet, "/records", "")
+		if response.Code != http.StatusUnauthorized {
+			t.Errorf("token %q: status = %d, want 401", token, response.Code)
+		}
+	}
+	if response := serve(b, "alice-token", http.MethodGet, "/records", ""); response.Code != http.StatusOK {
+		t.Errorf("client token: status = %d, want 200", response.Code)
+	}
+	for _, path := range []string{"/healthz", "/openapi.json"} {
+		if response := serve(b, "", http.MethodGet, path, ""); response.Code != http.StatusOK {
+			t.Errorf("%s: status = %d, want 200", path, response.Code)
+		}
+	}
+}
+
+func TestRequestsRunOnTheBackendOfTheirClient(t *testing.T) {
+	alice := &fakeBackend{result: []byte(`{"client":"alice"}`)}
+	bob := &fakeBackend{result: []byte(`{"client":"bob"}`)}
+	s := New([]Client{
+		{Name: "alice", TokenSHA256: tokenHash("alice-token"), Backend: alice},
+		{Name: "bob", TokenSHA256: strings.ToUpper(tokenHash("bob-token")), Backend: bob},
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/records/R1", nil)
+	request.Header.Set("Authorization", "Bearer bob-token")
+	recorder := httptest.NewRecorder()
+	s.ServeHTTP(recorder, request)
+
+	if recorder.Body.String() != `{"client":"bob"}` {
+		t.Errorf("body = %s, want the result of bob's backend", recorder.Body.String())
+	}
+	if alice.args != nil || len(bob.args) != 1 || bob.args[0] != "R1" {
+		t.Errorf("alice args = %v, bob args = %v", alice.args, bob.args)
+	}
+}
+
+func TestStatusMapping(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		result string
+		err    error
+		want   int
+	}{
+		{"created", http.MethodPost, "/records", `{}`, `{"success":true}`, nil, http.StatusCreated},
+		{"success false", http.MethodPost, "/records", `{}`, `{"success":false,"message":"Missing required fields"}`, nil, http.StatusUnprocessableEntity},
+		{"duplicate", http.MethodPost, "/records", `{}`, `{"success":false,"message":"Duplicate record found"}`, nil, http.StatusConflict},
+		{"no result", http.MethodPost, "/ledger/init", "", "", nil, http.StatusNoContent},
+		{"missing param", http.MethodGet, "/records/search?fieldName=a", "", "", nil, http.StatusBadRequest},
+		{"invalid body", http.MethodPost, "/records", `not json`, "", nil, http.StatusBadRequest},
+		{"denied", http.MethodGet, "/records/R1", "", "", &backend.ChaincodeError{Message: "authorization denied: missing role"}, http.StatusForbidden},
+		{"not found", http.MethodGet, "/records/R1", "", "", &backend.ChaincodeError{Message: "Document R1 does not exist"}, http.StatusNotFound},
+		{"rejected", http.MethodGet, "/records/R1", "", "", &backend.ChaincodeError{Message: "invalid options"}, http.StatusBadRequest},
+		{"unavailable", http.MethodGet, "/records/R1", "", "", errors.New("connection refused"), http.StatusBadGateway},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b := &fakeBackend{result: []byte(test.result), err: test.err}
+			response := serve(b, "alice-token", test.method, test.target, test.body)
+			if response.Code != test.want {
+				t.Errorf("status = %d, want %d: %s", response.Code, test.want, response.Body.String())
+			}
+		})
+	}
+}
+
+func TestTransientHeaders(t *testing.T) {
+	b := &fakeBackend{result: []byte(`{"success":true}`)}
+	s := New([]Client{{Name: "alice", TokenSHA256: tokenHash("alice-token"), Backend: b}})
+
+	request := httptest.NewRequest(http.MethodPost, "/records", strings.NewReader(`{"sponsor_id":"S1"}`))
+	request.Header.Set("Authorization", "Bearer alice-token")
+	request.Header.Set(saltHeader, "secret")
+	s.ServeHTTP(httptest.NewRecorder(), request)
+
+	if !b.submitted {
+		t.Errorf("CreateProofRecord was evaluated, want submitted")
+	}
+	if len(b.args) != 1 || b.args[0] != `{"sponsor_id":"S1"}` {
+		t.Errorf("args = %v", b.args)
+	}
+	if string(b.transient["salt"]) != "secret" {
+		t.Errorf("transient salt = %q, want the header value", b.transient["salt"])
+	}
+}
@@ -4,17 +4,31 @@ import (
 	"log"
 
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/yourusername/proof-records-chaincode/chaincode"
 )
 
 func main() {
-	proofRecordsContract := new(ProofRecordsContract)
+	proofRecordsContract := new(chaincode.ProofRecordsContract)
 
-	chaincode, err := contractapi.NewChaincode(proofRecordsContract)
+	proofRecordsChaincode, err := contractapi.NewChaincode(proofRecordsContract)
 	if err != nil {
 		log.Panicf("Error creating proof records chaincode: %v", err)
 	}
 
-	if err := chaincode.Start(); err != nil {
-		log.Panicf("Error starting proof records chaincode: %v", err)
+	serverConfig, err := serverConfigFromEnv()
+	if err != nil {
+		log.Panicf("Error reading chaincode server configuration: %v", err)
+	}
+
+	// Without CHAINCODE_SERVER_ADDRESS the chaincode is launched by the peer
+	if serverConfig == nil {
+		if err := proofRecordsChaincode.Start(); err != nil {
+			log.Panicf("Error starting proof records chaincode: %v", err)
+		}
+		return
+	}
+
+	if err := runServer(proofRecordsChaincode, serverConfig); err != nil {
+		log.Panicf("Error running proof records chaincode server: %v", err)
 	}
 }
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+)
+
+// shutdownTimeout bounds how long open peer streams are drained on shutdown
+const shutdownTimeout = 10 * time.Second
+
+// ServerConfig configures the contract as an external chaincode service
+type ServerConfig struct {
+	Address       string
+	CCID          string
+	HealthAddress string
+	TLSProps      shim.TLSProperties
+}
+
+// serverConfigFromEnv reads the chaincode server configuration. It returns nil when
+// CHAINCODE_SERVER_ADDRESS is not set and the chaincode is launched by the peer.
+func serverConfigFromEnv() (*ServerConfig, error) {
+	address := os.Getenv("CHAINCODE_SERVER_ADDRESS")
+	if address == "" {
+		return nil, nil
+	}
+
+	config := &ServerConfig{
+		Address:       address,
+		CCID:          os.Getenv("CHAINCODE_ID"),
+		HealthAddress: getEnv("CHAINCODE_HEALTH_ADDRESS", ":9090"),
+		TLSProps: shim.TLSProperties{
+			Disabled: strings.EqualFold(os.Getenv("CHAINCODE_TLS_DISABLED"), "true"),
+		},
+	}
+	if config.CCID == "" {
+		return nil, errors.New("CHAINCODE_ID is required when CHAINCODE_SERVER_ADDRESS is set")
+	}
+	if config.TLSProps.Disabled {
+		return config, nil
+	}
+
+	var err error
+	config.TLSProps.Key, err = readEnvFile("CHAINCODE_TLS_KEY", true)
+	if err != nil {
+		return nil, err
+	}
+	config.TLSProps.Cert, err = readEnvFile("CHAINCODE_TLS_CERT", true)
+	if err != nil {
+		return nil, err
+	}
+	config.TLSProps.ClientCACerts, err = readEnvFile("CHAINCODE_CLIENT_CA_CERT", false)
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// runServer serves the chaincode to peers until SIGINT or SIGTERM, then drains the
+// open peer streams and stops
+func runServer(cc shim.Chaincode, config *ServerConfig) error {
+	chaincodeServer := &shim.ChaincodeServer{
+		CCID:     config.CCID,
+		Address:  config.Address,
+		CC:       cc,
+		TLSProps: config.TLSProps,
+	}
+
+	grpcServer, err := newGRPCServer(config.TLSProps)
+	if err != nil {
+		return err
+	}
+	pb.RegisterChaincodeServer(grpcServer, chaincodeServer)
+
+	listener, err := net.Listen("tcp", config.Address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %v", config.Address, err)
+	}
+
+	var serving int32 = 1
+	var healthServer *http.Server
+	if config.HealthAddress != "" {
+		healthServer = newHealthServer(config.HealthAddress, &serving)
+		go func() {
+			if err := healthServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Printf("Health endpoint stopped: %v", err)
+			}
+		}()
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- grpcServer.Serve(listener)
+	}()
+	log.Printf("Proof records chaincode %s listening on %s", config.CCID, config.Address)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	select {
+	case err := <-serveErr:
+		atomic.StoreInt32(&serving, 0)
+		if healthServer != nil {
+			healthServer.Close()
+		}
+		return err
+	case sig := <-signals:
+		log.Printf("Received %s, shutting down", sig)
+	}
+
+	atomic.StoreInt32(&serving, 0)
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(shutdownTimeout):
+		grpcServer.Stop()
+	}
+
+	if healthServer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		healthServer.Shutdown(ctx)
+	}
+	return nil
+}
+
+// newGRPCServer creates the gRPC server with the keepalive, message size and TLS
+// settings the shim uses for chaincode servers
+func newGRPCServer(tlsProps shim.TLSProperties) (*grpc.Server, error) {
+	serverOpts := []grpc.ServerOption{
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:    1 * time.Minute,
+			Timeout: 20 * time.Second,
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             1 * time.Minute,
+			PermitWithoutStream: true,
+		}),
+		grpc.MaxSendMsgSize(100 * 1024 * 1024),
+		grpc.MaxRecvMsgSize(100 * 1024 * 1024),
+		grpc.ConnectionTimeout(5 * time.Second),
+	}
+
+	if !tlsProps.Disabled {
+		tlsConfig, err := loadTLSConfig(tlsProps)
+		if err != nil {
+			return nil, err
+		}
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	return grpc.NewServer(serverOpts...), nil
+}
+
+// loadTLSConfig builds the server TLS configuration, requiring client certificates
+// signed by the client CA when one is configured
+func loadTLSConfig(tlsProps shim.TLSProperties) (*tls.Config, error) {
+	certificate, err := tls.X509KeyPair(tlsProps.Cert, tlsProps.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse TLS key pair: %v", err)
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:             tls.VersionTLS12,
+		Certificates:           []tls.Certificate{certificate},
+		SessionTicketsDisabled: true,
+	}
+	if tlsProps.ClientCACerts != nil {
+		clientCAs := x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(tlsProps.ClientCACerts) {
+			return nil, errors.New("failed to parse client CA certificates")
+		}
+		tlsConfig.ClientCAs = clientCAs
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
+
+// newHealthServer serves /healthz, which reports 200 while the chaincode server
+// accepts peer connections and 503 once it shuts down
+func newHealthServer(address string, serving *int32) *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		status, code := "ok", http.StatusOK
+		if atomic.LoadInt32(serving) == 0 {
+			status, code = "shutting down", http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(map[string]string{"status": status})
+	})
+	return &http.Server{Addr: address, Handler: mux}
+}
+
+// readEnvFile reads the file named by an environment variable
+func readEnvFile(name string, required bool) ([]byte, error) {
+	path := os.Getenv(name)
+	if path == "" {
+		if required {
+			return nil, fmt.Errorf("%s is required unless CHAINCODE_TLS_DISABLED is true", name)
+		}
+		return nil, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", name, err)
+	}
+	return data, nil
+}
+
+func getEnv(name string, fallback string) string {
+	if value, exists := os.LookupEnv(name); exists {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// writeKeyPair writes a self-signed certificate and its key to dir and returns their paths
+func writeKeyPair(t *testing.T, dir string) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "proof-records"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to encode key: %v", err)
+	}
+
+	certPath, keyPath := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := ioutil.WriteFile(certPath, certPEM, 0600); err != nil {
+		t.Fatalf("failed to write certificate: %v", err)
+	}
+	if err := ioutil.WriteFile(keyPath, keyPEM, 0600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+	return certPath, keyPath
+}
+
+func TestServerConfigFromEnv(t *testing.T) {
+	t.Setenv("CHAINCODE_SERVER_ADDRESS", "")
+	if config, err := serverConfigFromEnv(); config != nil || err != nil {
+		t.Errorf("config without server address = %+v, %v, want peer launched chaincode", config, err)
+	}
+
+	t.Setenv("CHAINCODE_SERVER_ADDRESS", "0.0.0.0:9999")
+	t.Setenv("CHAINCODE_ID", "")
+	if _, err := serverConfigFromEnv(); err == nil {
+		t.Errorf("server address without CHAINCODE_ID was accepted")
+	}
+
+	t.Setenv("CHAINCODE_ID", "proof-records:abc")
+	t.Setenv("CHAINCODE_TLS_DISABLED", "TRUE")
+	config, err := serverConfigFromEnv()
+	if err != nil {
+		t.Fatalf("serverConfigFromEnv failed: %v", err)
+	}
+	if config.CCID != "proof-records:abc" || config.HealthAddress != ":9090" || !config.TLSProps.Disabled {
+		t.Errorf("config = %+v", config)
+	}
+
+	t.Setenv("CHAINCODE_TLS_DISABLED", "")
+	t.Setenv("CHAINCODE_TLS_KEY", "")
+	if _, err := serverConfigFromEnv(); err == nil {
+		t.Errorf("TLS without key was accepted")
+	}
+}
+
+func TestServerConfigLoadsTLSFiles(t *testing.T) {
+	certPath, keyPath := writeKeyPair(t, t.TempDir())
+	t.Setenv("CHAINCODE_SERVER_ADDRESS", "0.0.0.0:9999")
+	t.Setenv("CHAINCODE_ID", "proof-records:abc")
+	t.Setenv("CHAINCODE_TLS_DISABLED", "false")
+	t.Setenv("CHAINCODE_TLS_KEY", keyPath)
+	t.Setenv("CHAINCODE_TLS_CERT", certPath)
+	t.Setenv("CHAINCODE_CLIENT_CA_CERT", certPath)
+	t.Setenv("CHAINCODE_HEALTH_ADDRESS", "")
+
+	config, err := serverConfigFromEnv()
+	if err != nil {
+		t.Fatalf("serverConfigFromEnv failed: %v", err)
+	}
+	if config.HealthAddress != "" {
+		t.Errorf("health address = %q, want the endpoint disabled", config.HealthAddress)
+	}
+
+	tlsConfig, err := loadTLSConfig(config.TLSProps)
+	if err != nil {
+		t.Fatalf("loadTLSConfig failed: %v", err)
+	}
+	if tlsConfig.ClientAuth != tls.RequireAndVerifyClientCert || tlsConfig.MinVersion != tls.VersionTLS12 {
+		t.Errorf("TLS config does not require TLS 1.2 client certificates")
+	}
+
+	config.TLSProps.ClientCACerts = []byte("not a certificate")
+	if _, err := loadTLSConfig(config.TLSProps); err == nil {
+		t.Errorf("invalid client CA was accepted")
+	}
+}
+
+func TestHealthServer(t *testing.T) {
+	var serving int32 = 1
+	handler := newHealthServer(":0", &serving).Handler
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if recorder.Code != http.StatusOK {
+		t.Errorf("serving status = %d, want 200", recorder.Code)
+	}
+
+	atomic.StoreInt32(&serving, 0)
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Errorf("shutting down status = %d, want 503", recorder.Code)
+	}
+}